@@ -0,0 +1,51 @@
+package Clock
+
+import (
+	"time"
+)
+
+/****************************************************************
+ * @author: Ihc
+ * @date: 2026/10/20 14:10
+ * @description: 时钟接口,测试时可以替换为手动推进的时钟
+	TimingWheels,Cron等需要定时器的模块共用该接口
+ ***************************************************************/
+
+// Clock 时钟接口
+type Clock interface {
+	// Now 获取时钟的当前时间
+	Now() time.Time
+	// NewTimer 创建在d之后触发的定时器
+	NewTimer(d time.Duration) Timer
+}
+
+// Timer Clock 创建的定时器
+type Timer interface {
+	// C 定时器触发时发送当前时间
+	C() <-chan time.Time
+	// Stop 停止定时器,定时器已触发或已停止时返回false
+	Stop() bool
+}
+
+// RealClock 用标准库时间模块实现Clock接口
+type RealClock struct{}
+
+func (r RealClock) Now() time.Time {
+	return time.Now()
+}
+
+func (r RealClock) NewTimer(d time.Duration) Timer {
+	return realTimer{time.NewTimer(d)}
+}
+
+type realTimer struct {
+	timer *time.Timer
+}
+
+func (r realTimer) C() <-chan time.Time {
+	return r.timer.C
+}
+
+func (r realTimer) Stop() bool {
+	return r.timer.Stop()
+}
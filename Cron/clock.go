@@ -0,0 +1,20 @@
+package Cron
+
+import (
+	clock "preseus/Clock"
+)
+
+/****************************************************************
+ * @author: Ihc
+ * @date: 2026/10/20 11:50
+ * @description: 时钟接口,测试时可以替换为手动推进的时钟
+ ***************************************************************/
+
+// Clock 时钟接口,见 Clock.Clock
+type Clock = clock.Clock
+
+// Timer Clock 创建的定时器,见 Clock.Timer
+type Timer = clock.Timer
+
+// realClock 系统时钟
+type realClock = clock.RealClock
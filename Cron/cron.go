@@ -1,7 +1,460 @@
 package Cron
 
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"time"
+)
+
 /****************************************************************
  * @author: Ihc
  * @date: 2022/4/19 22:56
- * @description:
+ * @description: 定时任务
+	1.表达式: Parse 解析5或6个字段的cron表达式与描述符,得到 Schedule
+	2.调度: Schedule.Next 计算下一次触发时间,处理时区与夏令时
+	3.调度器: Cron 在运行时加入与删除任务,每个任务在自己的goroutine中执行
+	4.重叠: 任务上一次尚未结束时按 OverlapPolicy 跳过,排队或并发执行
+	5.停止: Stop 不再触发任务,等待正在执行的任务结束;任务panic时恢复并交给 PanicHandler
+	6.多实例: 设置 Locker 后每次触发先获取租约,保证同名任务的每次触发在集群内只执行一次
  ***************************************************************/
+
+// EntryID 任务的标识
+type EntryID int
+
+// OverlapPolicy 任务触发时上一次执行尚未结束的处理方式
+type OverlapPolicy int
+
+const (
+	// OverlapConcurrent 并发执行
+	OverlapConcurrent OverlapPolicy = iota
+	// OverlapSkip 跳过本次触发
+	OverlapSkip
+	// OverlapQueue 排队,上一次结束后立即执行
+	OverlapQueue
+)
+
+// PanicHandler 处理任务中的panic,recovered为recover()的返回值,stack为panic时的调用栈
+type PanicHandler func(recovered interface{}, stack []byte)
+
+// defaultPanicHandler 将panic与调用栈输出到标准错误
+func defaultPanicHandler(recovered interface{}, stack []byte) {
+	fmt.Fprintf(os.Stderr, "cron: job panicked: %v\n%s", recovered, stack)
+}
+
+// ErrorHandler 处理获取与完成租约时的错误,不包括 ErrLockHeld
+type ErrorHandler func(name string, scheduled time.Time, err error)
+
+// defaultErrorHandler 将错误输出到标准错误
+func defaultErrorHandler(name string, scheduled time.Time, err error) {
+	fmt.Fprintf(os.Stderr, "cron: job %q scheduled at %s: %v\n", name, scheduled.Format(time.RFC3339), err)
+}
+
+// Option 用于设置Cron的初始化选项
+type Option func(options *Options)
+
+// Options Cron初始化选项
+type Options struct {
+	clock        Clock          // clock 时钟
+	location     *time.Location // location 计算触发时间使用的时区
+	panicHandler PanicHandler   // panicHandler 处理任务中的panic
+	locker       Locker         // locker 为nil时不加锁
+	lockTTL      time.Duration  // lockTTL 租约的有效期
+	errorHandler ErrorHandler   // errorHandler 处理租约的错误
+}
+
+// WithClock 设置时钟,默认为系统时钟
+func WithClock(clock Clock) Option {
+	return func(options *Options) {
+		options.clock = clock
+	}
+}
+
+// WithLocation 设置计算触发时间使用的时区,默认为time.Local,表达式中的CRON_TZ优先
+func WithLocation(location *time.Location) Option {
+	return func(options *Options) {
+		options.location = location
+	}
+}
+
+// WithPanicHandler 设置任务panic时的处理函数,默认输出到标准错误
+func WithPanicHandler(handler PanicHandler) Option {
+	return func(options *Options) {
+		options.panicHandler = handler
+	}
+}
+
+// WithLocker 设置分布式锁,每次触发先获取有效期为ttl的租约,取得租约的实例才执行任务
+// ttl应大于任务的最长执行时间,超过ttl仍在执行的任务可能被其他实例接管,且无法完成租约
+// @every 任务的触发时间对齐到Unix纪元起间隔的整数倍,而不是从启动时开始计算
+func WithLocker(locker Locker, ttl time.Duration) Option {
+	return func(options *Options) {
+		options.locker = locker
+		options.lockTTL = ttl
+	}
+}
+
+// WithErrorHandler 设置获取与完成租约出错时的处理函数,默认输出到标准错误
+func WithErrorHandler(handler ErrorHandler) Option {
+	return func(options *Options) {
+		options.errorHandler = handler
+	}
+}
+
+// JobOption 用于设置单个任务的选项
+type JobOption func(options *jobOptions)
+
+// jobOptions 任务选项
+type jobOptions struct {
+	name    string        // name 任务名称
+	overlap OverlapPolicy // overlap 重叠时的处理方式
+}
+
+// WithName 设置任务名称,默认为表达式,使用 Locker 时名称是集群内加锁的键
+func WithName(name string) JobOption {
+	return func(options *jobOptions) {
+		options.name = name
+	}
+}
+
+// WithOverlapPolicy 设置任务上一次执行尚未结束时的处理方式,默认为 OverlapConcurrent
+func WithOverlapPolicy(policy OverlapPolicy) JobOption {
+	return func(options *jobOptions) {
+		options.overlap = policy
+	}
+}
+
+// Entry 任务的快照
+type Entry struct {
+	ID        EntryID
+	Name      string
+	Schedule  Schedule
+	Next      time.Time // Next 下一次触发时间,未启动或不会再触发时为零值
+	Prev      time.Time // Prev 上一次触发时间
+	Running   int       // Running 正在执行的次数
+	Queued    int       // Queued OverlapQueue 排队等待执行的次数
+	Skipped   uint64    // Skipped OverlapSkip 跳过的次数
+	Contended uint64    // Contended 因其他实例已执行或正在执行而未执行的次数
+}
+
+// entry 调度器中的任务
+type entry struct {
+	Entry
+	fn      func(lease Lease)
+	options jobOptions
+	queue   []time.Time // queue OverlapQueue 排队的计划触发时间
+	removed bool
+}
+
+// Cron 定时任务调度器,并发安全
+type Cron struct {
+	options Options
+	mu      sync.Mutex
+	entries []*entry // entries 按ID排序
+	nextID  EntryID
+	running bool          // running 是否已启动
+	wake    chan struct{} // wake 任务变化时重新计算等待时间
+	stop    chan struct{} // stop 关闭时调度goroutine退出
+	stopped chan struct{} // stopped 调度goroutine退出后关闭
+	jobs    sync.WaitGroup
+}
+
+// New 创建调度器,调用 Start 后开始触发任务
+func New(opts ...Option) *Cron {
+	options := Options{
+		clock:        realClock{},
+		location:     time.Local,
+		panicHandler: defaultPanicHandler,
+		errorHandler: defaultErrorHandler,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.locker != nil && options.lockTTL <= 0 {
+		panic("cron lock ttl is not > 0")
+	}
+	return &Cron{options: options, wake: make(chan struct{}, 1)}
+}
+
+// AddFunc 按cron表达式spec执行fn,返回任务的标识
+func (c *Cron) AddFunc(spec string, fn func(), opts ...JobOption) (EntryID, error) {
+	return c.AddLeasedFunc(spec, func(Lease) { fn() }, opts...)
+}
+
+// AddLeasedFunc 按cron表达式spec执行fn,fn得到本次触发的租约,可以将防护令牌传给下游存储
+// 设置 Locker 时任务名称在调度器内必须唯一
+func (c *Cron) AddLeasedFunc(spec string, fn func(lease Lease), opts ...JobOption) (EntryID, error) {
+	schedule, err := Parse(spec)
+	if err != nil {
+		return 0, err
+	}
+	options := jobOptions{name: spec}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.options.locker != nil {
+		// 从启动时计算的间隔在各实例间不同,无法按计划触发时间互斥
+		if d, ok := schedule.(ConstantDelaySchedule); ok {
+			schedule = alignedDelaySchedule{d}
+		}
+		for _, e := range c.entries {
+			if e.Name == options.name {
+				return 0, fmt.Errorf("cron: duplicate job name %q", options.name)
+			}
+		}
+	}
+	c.nextID++
+	e := &entry{Entry: Entry{ID: c.nextID, Name: options.name, Schedule: schedule}, fn: fn, options: options}
+	if c.running {
+		e.Next = schedule.Next(c.now())
+		c.signal()
+	}
+	c.entries = append(c.entries, e)
+	return e.ID, nil
+}
+
+// Remove 删除任务,正在执行的任务不受影响,排队的执行被取消
+func (c *Cron) Remove(id EntryID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	i := sort.Search(len(c.entries), func(i int) bool { return c.entries[i].ID >= id })
+	if i == len(c.entries) || c.entries[i].ID != id {
+		return
+	}
+	e := c.entries[i]
+	e.removed = true
+	e.clearQueue()
+	c.entries = append(c.entries[:i], c.entries[i+1:]...)
+	c.signal()
+}
+
+// Entry 返回任务的快照,任务不存在时ok为false
+func (c *Cron) Entry(id EntryID) (Entry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, e := range c.entries {
+		if e.ID == id {
+			return e.Entry, true
+		}
+	}
+	return Entry{}, false
+}
+
+// Entries 返回全部任务的快照,按下一次触发时间排序,不会再触发的任务在最后
+func (c *Cron) Entries() []Entry {
+	c.mu.Lock()
+	entries := make([]Entry, len(c.entries))
+	for i, e := range c.entries {
+		entries[i] = e.Entry
+	}
+	c.mu.Unlock()
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Next.IsZero() || entries[j].Next.IsZero() {
+			return !entries[i].Next.IsZero()
+		}
+		return entries[i].Next.Before(entries[j].Next)
+	})
+	return entries
+}
+
+// Start 开始触发任务,已启动时忽略
+func (c *Cron) Start() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.running {
+		return
+	}
+	c.running = true
+	c.stop = make(chan struct{})
+	c.stopped = make(chan struct{})
+	now := c.now()
+	for _, e := range c.entries {
+		e.Next = e.Schedule.Next(now)
+	}
+	go c.loop(c.stop, c.stopped)
+}
+
+// Stop 停止触发任务并取消排队的执行,等待正在执行的任务结束,ctx结束时返回ctx.Err()
+// 停止后可以再次 Start
+func (c *Cron) Stop(ctx context.Context) error {
+	c.mu.Lock()
+	if c.running {
+		c.running = false
+		close(c.stop)
+		stopped := c.stopped
+		c.mu.Unlock()
+		<-stopped
+		c.mu.Lock()
+	}
+	for _, e := range c.entries {
+		e.Next = time.Time{}
+		e.clearQueue()
+	}
+	c.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		c.jobs.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// now 调度器时区的当前时间
+func (c *Cron) now() time.Time {
+	return c.options.clock.Now().In(c.options.location)
+}
+
+// signal 唤醒调度goroutine
+func (c *Cron) signal() {
+	select {
+	case c.wake <- struct{}{}:
+	default:
+	}
+}
+
+// loop 等待最早的任务到期后触发
+func (c *Cron) loop(stop, stopped chan struct{}) {
+	defer close(stopped)
+	for {
+		wait := c.dispatchDue()
+		var (
+			timer Timer
+			fire  <-chan time.Time
+		)
+		if wait >= 0 {
+			timer = c.options.clock.NewTimer(wait)
+			fire = timer.C()
+		}
+		select {
+		case <-fire:
+		case <-c.wake:
+		case <-stop:
+			if timer != nil {
+				timer.Stop()
+			}
+			return
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+}
+
+// dispatchDue 触发全部到期的任务,返回距最早的下一次触发的时间,没有任务时返回-1
+// 错过多次触发的任务只触发一次
+func (c *Cron) dispatchDue() time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	var earliest time.Time
+	for _, e := range c.entries {
+		if e.Next.IsZero() {
+			continue
+		}
+		if !e.Next.After(now) {
+			e.Prev = e.Next
+			c.dispatch(e, e.Next)
+			e.Next = e.Schedule.Next(now)
+		}
+		if !e.Next.IsZero() && (earliest.IsZero() || e.Next.Before(earliest)) {
+			earliest = e.Next
+		}
+	}
+	if earliest.IsZero() {
+		return -1
+	}
+	return earliest.Sub(now)
+}
+
+// dispatch 按重叠策略执行scheduled时刻的触发,调用方持有锁
+func (c *Cron) dispatch(e *entry, scheduled time.Time) {
+	if e.Running > 0 {
+		switch e.options.overlap {
+		case OverlapSkip:
+			e.Skipped++
+			return
+		case OverlapQueue:
+			e.queue = append(e.queue, scheduled)
+			e.Queued = len(e.queue)
+			return
+		}
+	}
+	e.Running++
+	c.jobs.Add(1)
+	go c.run(e, scheduled)
+}
+
+// clearQueue 取消排队的执行,调用方持有锁
+func (e *entry) clearQueue() {
+	e.queue = nil
+	e.Queued = 0
+}
+
+// run 执行任务,OverlapQueue 的任务结束后继续执行排队的触发
+func (c *Cron) run(e *entry, scheduled time.Time) {
+	defer c.jobs.Done()
+	for {
+		c.execute(e, scheduled)
+		c.mu.Lock()
+		if len(e.queue) == 0 || !c.running || e.removed {
+			e.Running--
+			c.mu.Unlock()
+			return
+		}
+		scheduled = e.queue[0]
+		e.queue = e.queue[1:]
+		e.Queued = len(e.queue)
+		c.mu.Unlock()
+	}
+}
+
+// execute 执行一次触发,设置 Locker 时先获取租约,执行结束后完成租约
+func (c *Cron) execute(e *entry, scheduled time.Time) {
+	locker := c.options.locker
+	if locker == nil {
+		c.safeRun(e, Lease{Name: e.Name, Scheduled: scheduled})
+		return
+	}
+	// 租约到期后才取得的锁没有意义,获取租约的时间不超过ttl
+	ctx, cancel := context.WithTimeout(context.Background(), c.options.lockTTL)
+	lease, err := locker.Acquire(ctx, e.Name, scheduled, c.options.lockTTL)
+	cancel()
+	if errors.Is(err, ErrLockHeld) {
+		c.mu.Lock()
+		e.Contended++
+		c.mu.Unlock()
+		return
+	}
+	if err != nil {
+		c.options.errorHandler(e.Name, scheduled, err)
+		return
+	}
+	c.safeRun(e, lease)
+	ctx, cancel = context.WithTimeout(context.Background(), c.options.lockTTL)
+	defer cancel()
+	if err := locker.Complete(ctx, lease); err != nil {
+		c.options.errorHandler(e.Name, scheduled, err)
+	}
+}
+
+// safeRun 执行任务,panic时交给 PanicHandler
+func (c *Cron) safeRun(e *entry, lease Lease) {
+	defer func() {
+		if r := recover(); r != nil {
+			c.options.panicHandler(r, debug.Stack())
+		}
+	}()
+	e.fn(lease)
+}
@@ -0,0 +1,351 @@
+package Cron
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"preseus/internal/fakeclock"
+)
+
+/****************************************************************
+ * @author: Ihc
+ * @date: 2026/10/20 12:10
+ * @description:
+ ***************************************************************/
+
+var epoch = time.Date(2026, 10, 20, 12, 0, 0, 0, time.UTC)
+
+// fakeClock 手动推进的时钟
+type fakeClock struct {
+	*fakeclock.FakeClock
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{fakeclock.New(epoch)}
+}
+
+// tick 等待调度goroutine开始等待,推进到最早的定时器的触发时间
+func (c *fakeClock) tick(t *testing.T) time.Time {
+	t.Helper()
+	var when time.Time
+	waitFor(t, "scheduler waiting", func() bool {
+		timers := c.Timers()
+		if len(timers) == 0 {
+			return false
+		}
+		when = timers[0]
+		return true
+	})
+	c.AdvanceTo(when)
+	return when
+}
+
+// pending 未触发的定时器数量
+func (c *fakeClock) pending() int {
+	return len(c.Timers())
+}
+
+// waitFor 轮询等待条件成立
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timeout waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func newTestCron(opts ...Option) (*Cron, *fakeClock) {
+	clock := newFakeClock()
+	c := New(append([]Option{WithClock(clock), WithLocation(time.UTC)}, opts...)...)
+	return c, clock
+}
+
+func stopCron(t *testing.T, c *Cron) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := c.Stop(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAddRemoveEntries(t *testing.T) {
+	c, clock := newTestCron()
+	fired := make(chan string, 10)
+	hourly, err := c.AddFunc("@hourly", func() { fired <- "hourly" })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.AddFunc("61 * * * *", func() {}); err == nil {
+		t.Fatal("invalid spec must be rejected")
+	}
+	c.Start()
+	defer stopCron(t, c)
+	minutely, _ := c.AddFunc("*/30 * * * *", func() { fired <- "half" }, WithName("half-hourly"))
+
+	entries := c.Entries()
+	if len(entries) != 2 || entries[0].ID != minutely || entries[0].Name != "half-hourly" || entries[1].Name != "@hourly" {
+		t.Fatalf("%+v", entries)
+	}
+	if !entries[0].Next.Equal(epoch.Add(30*time.Minute)) || !entries[1].Next.Equal(epoch.Add(time.Hour)) {
+		t.Fatal(entries[0].Next, entries[1].Next)
+	}
+
+	if when := clock.tick(t); !when.Equal(epoch.Add(30 * time.Minute)) {
+		t.Fatal("woke at", when)
+	}
+	if got := <-fired; got != "half" {
+		t.Fatal(got)
+	}
+	clock.tick(t)
+	a, b := <-fired, <-fired
+	if a == b {
+		t.Fatal("both jobs fire at the hour", a, b)
+	}
+	if e, _ := c.Entry(hourly); !e.Prev.Equal(epoch.Add(time.Hour)) || !e.Next.Equal(epoch.Add(2*time.Hour)) {
+		t.Fatalf("%+v", e)
+	}
+
+	c.Remove(hourly)
+	c.Remove(hourly)
+	if _, ok := c.Entry(hourly); ok || len(c.Entries()) != 1 {
+		t.Fatal("entry not removed")
+	}
+	clock.tick(t)
+	clock.tick(t)
+	if a, b := <-fired, <-fired; a != "half" || b != "half" {
+		t.Fatal("removed job fired", a, b)
+	}
+}
+
+func TestOverlapPolicies(t *testing.T) {
+	cases := []struct {
+		policy                         OverlapPolicy
+		running, queued, skipped, runs int
+	}{
+		{OverlapConcurrent, 3, 0, 0, 3},
+		{OverlapSkip, 1, 0, 2, 1},
+		{OverlapQueue, 1, 2, 0, 3},
+	}
+	for _, tc := range cases {
+		c, clock := newTestCron()
+		release := make(chan struct{})
+		var runs, concurrent, maxConcurrent int32
+		id, _ := c.AddFunc("* * * * * *", func() {
+			n := atomic.AddInt32(&concurrent, 1)
+			for {
+				m := atomic.LoadInt32(&maxConcurrent)
+				if n <= m || atomic.CompareAndSwapInt32(&maxConcurrent, m, n) {
+					break
+				}
+			}
+			atomic.AddInt32(&runs, 1)
+			<-release
+			atomic.AddInt32(&concurrent, -1)
+		}, WithOverlapPolicy(tc.policy))
+		c.Start()
+		for i := 0; i < 3; i++ {
+			clock.tick(t)
+			waitFor(t, "dispatched", func() bool {
+				e, _ := c.Entry(id)
+				return e.Running+e.Queued+int(e.Skipped) == i+1 || e.Running == i+1
+			})
+		}
+		e, _ := c.Entry(id)
+		if e.Running != tc.running || e.Queued != tc.queued || int(e.Skipped) != tc.skipped {
+			t.Fatalf("policy %d: %+v", tc.policy, e)
+		}
+		close(release)
+		waitFor(t, "runs finished", func() bool {
+			e, _ := c.Entry(id)
+			return e.Running == 0
+		})
+		if int(runs) != tc.runs {
+			t.Fatalf("policy %d: %d runs", tc.policy, runs)
+		}
+		if tc.policy != OverlapConcurrent && maxConcurrent != 1 {
+			t.Fatalf("policy %d: %d concurrent runs", tc.policy, maxConcurrent)
+		}
+		stopCron(t, c)
+	}
+}
+
+func TestPanicRecovery(t *testing.T) {
+	panics := make(chan interface{}, 2)
+	c, clock := newTestCron(WithPanicHandler(func(recovered interface{}, stack []byte) { panics <- recovered }))
+	var runs int32
+	c.AddFunc("* * * * * *", func() {
+		atomic.AddInt32(&runs, 1)
+		panic("boom")
+	})
+	c.Start()
+	defer stopCron(t, c)
+	clock.tick(t)
+	if r := <-panics; r != "boom" {
+		t.Fatal(r)
+	}
+	clock.tick(t)
+	<-panics
+	if atomic.LoadInt32(&runs) != 2 {
+		t.Fatal("job must keep firing after a panic", runs)
+	}
+}
+
+func TestStopWaitsForRunningJobs(t *testing.T) {
+	c, clock := newTestCron()
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	var runs int32
+	id, _ := c.AddFunc("* * * * * *", func() {
+		atomic.AddInt32(&runs, 1)
+		started <- struct{}{}
+		<-release
+	}, WithOverlapPolicy(OverlapQueue))
+	c.Start()
+	clock.tick(t)
+	<-started
+	clock.tick(t)
+	waitFor(t, "queued", func() bool {
+		e, _ := c.Entry(id)
+		return e.Queued == 1
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := c.Stop(ctx); err != context.DeadlineExceeded {
+		t.Fatal("Stop must wait for the running job", err)
+	}
+	if e, _ := c.Entry(id); e.Queued != 0 || !e.Next.IsZero() {
+		t.Fatalf("queued runs must be cancelled: %+v", e)
+	}
+	close(release)
+	stopCron(t, c)
+	if atomic.LoadInt32(&runs) != 1 {
+		t.Fatal("queued run started after Stop", runs)
+	}
+
+	// 停止后可以再次启动
+	c.Start()
+	defer stopCron(t, c)
+	if e, _ := c.Entry(id); e.Next.IsZero() {
+		t.Fatal("restart must reschedule entries")
+	}
+}
+
+func TestLockerSingleRunAcrossInstances(t *testing.T) {
+	clock := newFakeClock()
+	locker := NewMemoryLocker(clock)
+	tokens := make(chan uint64, 10)
+	var replicas []*Cron
+	for i := 0; i < 3; i++ {
+		c := New(WithClock(clock), WithLocation(time.UTC), WithLocker(locker, time.Minute))
+		if _, err := c.AddLeasedFunc("* * * * * *", func(lease Lease) { tokens <- lease.Token }, WithName("report")); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := c.AddFunc("@hourly", func() {}, WithName("report")); err == nil {
+			t.Fatal("duplicate job names must be rejected with a locker")
+		}
+		c.Start()
+		defer stopCron(t, c)
+		replicas = append(replicas, c)
+	}
+	for want := uint64(1); want <= 3; want++ {
+		waitFor(t, "replicas waiting", func() bool { return clock.pending() == len(replicas) })
+		clock.tick(t)
+		if token := <-tokens; token != want {
+			t.Fatal("token", token, "want", want)
+		}
+		waitFor(t, "contended replicas", func() bool {
+			var contended uint64
+			for _, c := range replicas {
+				contended += c.Entries()[0].Contended
+			}
+			return contended == 2*want
+		})
+	}
+	select {
+	case token := <-tokens:
+		t.Fatal("run fired twice", token)
+	default:
+	}
+}
+
+func TestLockerEveryAcrossInstances(t *testing.T) {
+	clock := newFakeClock()
+	locker := NewMemoryLocker(clock)
+	scheduled := make(chan time.Time, 10)
+	// 各实例在不同时间启动,@every 的触发时间仍然一致
+	var replicas []*Cron
+	for i := 0; i < 3; i++ {
+		c := New(WithClock(clock), WithLocation(time.UTC), WithLocker(locker, time.Minute))
+		if _, err := c.AddLeasedFunc("@every 1m", func(lease Lease) { scheduled <- lease.Scheduled }); err != nil {
+			t.Fatal(err)
+		}
+		c.Start()
+		defer stopCron(t, c)
+		replicas = append(replicas, c)
+		clock.Advance(7 * time.Second)
+	}
+	for i := 1; i <= 3; i++ {
+		want := epoch.Add(time.Duration(i) * time.Minute)
+		for _, c := range replicas {
+			if next := c.Entries()[0].Next; !next.Equal(want) {
+				t.Fatal("next", next, "want", want)
+			}
+		}
+		waitFor(t, "replicas waiting", func() bool { return clock.pending() == len(replicas) })
+		clock.tick(t)
+		if got := <-scheduled; !got.Equal(want) {
+			t.Fatal("scheduled", got, "want", want)
+		}
+		waitFor(t, "contended replicas", func() bool {
+			var contended uint64
+			for _, c := range replicas {
+				contended += c.Entries()[0].Contended
+			}
+			return contended == uint64(2*i)
+		})
+	}
+	select {
+	case got := <-scheduled:
+		t.Fatal("run fired twice", got)
+	default:
+	}
+}
+
+func TestLockerStaleHolder(t *testing.T) {
+	clock := newFakeClock()
+	locker := NewMemoryLocker(clock)
+	errs := make(chan error, 1)
+	c := New(WithClock(clock), WithLocation(time.UTC), WithLocker(locker, time.Minute),
+		WithErrorHandler(func(name string, scheduled time.Time, err error) { errs <- err }))
+	started := make(chan Lease, 1)
+	release := make(chan struct{})
+	c.AddLeasedFunc("@hourly", func(lease Lease) {
+		started <- lease
+		<-release
+	})
+	c.Start()
+	defer stopCron(t, c)
+	clock.tick(t)
+	lease := <-started
+
+	// 任务执行超过ttl,其他实例接管了这次触发
+	clock.Advance(time.Minute)
+	taken, err := locker.Acquire(context.Background(), "@hourly", lease.Scheduled, time.Minute)
+	if err != nil || taken.Token <= lease.Token {
+		t.Fatal(taken, err)
+	}
+	close(release)
+	if err := <-errs; err != ErrStaleLease {
+		t.Fatal("stale holder completed", err)
+	}
+	if err := locker.Complete(context.Background(), taken); err != nil {
+		t.Fatal(err)
+	}
+}
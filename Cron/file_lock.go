@@ -0,0 +1,157 @@
+//go:build !windows
+// +build !windows
+
+package Cron
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+)
+
+/****************************************************************
+ * @author: Ihc
+ * @date: 2026/10/20 12:40
+ * @description: 基于文件锁的 Locker,同一主机上的多个进程共享一个目录
+	每个任务名称对应目录中的锁文件与状态文件,读写状态时持有锁文件的排他锁(flock)
+	状态写入临时文件后重命名替换,崩溃时保留旧的状态;状态文件为空或无法解析时拒绝加锁,
+	而不是从零开始发放令牌
+	windows上没有flock,不提供 FileLocker
+ ***************************************************************/
+
+// lockRetryInterval 文件锁被其他进程持有时重试的间隔
+const lockRetryInterval = 5 * time.Millisecond
+
+// ErrCorruptState 状态文件已损坏,需要人工检查或删除后才能继续加锁
+var ErrCorruptState = errors.New("cron: corrupt lock state")
+
+// FileLocker 基于文件锁的 Locker,用于同一主机上的多个进程与测试
+// 依赖flock,只在非windows系统上提供
+type FileLocker struct {
+	dir   string
+	clock Clock
+}
+
+// NewFileLocker 在目录dir中保存加锁状态,目录不存在时创建,clock为nil时使用系统时钟
+func NewFileLocker(dir string, clock Clock) (*FileLocker, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	if clock == nil {
+		clock = realClock{}
+	}
+	return &FileLocker{dir: dir, clock: clock}, nil
+}
+
+// Acquire 见 Locker.Acquire
+func (f *FileLocker) Acquire(ctx context.Context, name string, scheduled time.Time, ttl time.Duration) (Lease, error) {
+	var lease Lease
+	err := f.update(ctx, name, func(t *leaseTable) (err error) {
+		lease, err = t.acquire(name, scheduled, f.clock.Now(), ttl)
+		return err
+	})
+	return lease, err
+}
+
+// Complete 见 Locker.Complete
+func (f *FileLocker) Complete(ctx context.Context, lease Lease) error {
+	return f.update(ctx, lease.Name, func(t *leaseTable) error {
+		return t.complete(lease, f.clock.Now())
+	})
+}
+
+// update 持有name对应锁文件的排他锁,读出加锁状态交给fn修改,fn成功时写回
+// 等待文件锁时ctx结束返回ctx.Err(),状态文件损坏时返回 ErrCorruptState
+func (f *FileLocker) update(ctx context.Context, name string, fn func(t *leaseTable) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	base := filepath.Join(f.dir, url.PathEscape(name))
+	file, err := os.OpenFile(base+".lock", os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if err := lockFile(ctx, file); err != nil {
+		return err
+	}
+	defer unlockFile(file)
+
+	path := base + ".state"
+	var t leaseTable
+	data, err := os.ReadFile(path)
+	switch {
+	case os.IsNotExist(err):
+	case err != nil:
+		return err
+	default:
+		// 状态文件只由重命名创建,为空同样是损坏
+		if err := json.Unmarshal(data, &t); err != nil {
+			return fmt.Errorf("%w: %s: %v", ErrCorruptState, path, err)
+		}
+	}
+	if err := fn(&t); err != nil {
+		return err
+	}
+	if data, err = json.Marshal(&t); err != nil {
+		return err
+	}
+	return writeState(path, data)
+}
+
+// writeState 将状态写入临时文件并同步后重命名为path,任何时刻path都是完整的旧状态或新状态
+func writeState(path string, data []byte) error {
+	tmp := path + ".tmp"
+	file, err := os.OpenFile(tmp, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(data)
+	if err == nil {
+		err = file.Sync()
+	}
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	// 同步目录,使重命名持久化
+	if dir, err := os.Open(filepath.Dir(path)); err == nil {
+		dir.Sync()
+		dir.Close()
+	}
+	return nil
+}
+
+// lockFile 取得文件的排他锁,锁被其他进程持有时每隔 lockRetryInterval 重试,直到ctx结束
+func lockFile(ctx context.Context, file *os.File) error {
+	for {
+		err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+		if err != syscall.EWOULDBLOCK && err != syscall.EINTR {
+			return err
+		}
+		timer := time.NewTimer(lockRetryInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// unlockFile 释放文件锁
+func unlockFile(file *os.File) error {
+	return syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+}
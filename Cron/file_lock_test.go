@@ -0,0 +1,131 @@
+//go:build !windows
+// +build !windows
+
+package Cron
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+/****************************************************************
+ * @author: Ihc
+ * @date: 2026/10/20 13:40
+ * @description:
+ ***************************************************************/
+
+func TestFileLocker(t *testing.T) {
+	clock := newFakeClock()
+	dir := t.TempDir()
+	locker, err := NewFileLocker(dir, clock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testLocker(t, locker, clock)
+
+	// 状态保存在文件中,其他进程打开同一目录可见
+	other, err := NewFileLocker(dir, clock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	scheduled := epoch.Add(time.Hour)
+	if _, err := locker.Acquire(ctx, "*/5 * * * *", scheduled, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := other.Acquire(ctx, "*/5 * * * *", scheduled, time.Minute); err != ErrLockHeld {
+		t.Fatal("lock not shared through the directory", err)
+	}
+}
+
+func TestFileLockerCorruptState(t *testing.T) {
+	clock := newFakeClock()
+	dir := t.TempDir()
+	locker, err := NewFileLocker(dir, clock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	lease, err := locker.Acquire(ctx, "report", epoch, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 写入中途崩溃留下的临时文件不影响状态
+	state := filepath.Join(dir, "report.state")
+	if err := os.WriteFile(state+".tmp", []byte(`{"tok`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := locker.Acquire(ctx, "report", epoch, time.Minute); err != ErrLockHeld {
+		t.Fatal("state lost", err)
+	}
+	// 状态文件被截断后拒绝加锁,不能从零开始发放令牌
+	if err := os.Truncate(state, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := locker.Acquire(ctx, "report", epoch.Add(time.Minute), time.Minute); !errors.Is(err, ErrCorruptState) {
+		t.Fatal("truncated state accepted", err)
+	}
+	if err := locker.Complete(ctx, lease); !errors.Is(err, ErrCorruptState) {
+		t.Fatal("truncated state accepted", err)
+	}
+}
+
+func TestFileLockerConcurrent(t *testing.T) {
+	dir := t.TempDir()
+	results := make(chan error, 8)
+	for i := 0; i < cap(results); i++ {
+		go func() {
+			locker, err := NewFileLocker(dir, nil)
+			if err == nil {
+				_, err = locker.Acquire(context.Background(), "report", epoch, time.Hour)
+			}
+			results <- err
+		}()
+	}
+	acquired := 0
+	for i := 0; i < cap(results); i++ {
+		switch err := <-results; err {
+		case nil:
+			acquired++
+		case ErrLockHeld:
+		default:
+			t.Fatal(err)
+		}
+	}
+	if acquired != 1 {
+		t.Fatal("acquired by", acquired, "holders")
+	}
+}
+
+func TestFileLockerHonorsContext(t *testing.T) {
+	dir := t.TempDir()
+	locker, err := NewFileLocker(dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 其他进程持有文件锁
+	file, err := os.OpenFile(filepath.Join(dir, "report.lock"), os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := locker.Acquire(ctx, "report", epoch, time.Minute); err != context.DeadlineExceeded {
+		t.Fatal("Acquire must give up when ctx ends", err)
+	}
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_UN); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := locker.Acquire(context.Background(), "report", epoch, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,142 @@
+package Cron
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+/****************************************************************
+ * @author: Ihc
+ * @date: 2026/10/20 12:30
+ * @description: 多实例部署时任务的单次执行锁
+	1.租约: 按任务名称与计划触发时间加锁,租约到期前其他实例不能执行同一次触发
+	2.防护令牌: 同名任务每次获取租约时令牌单调递增,过期的持有者不能完成,下游可以用令牌拒绝过期的写入
+	3.实现: MemoryLocker 用于测试与单进程,FileLocker 用于同一主机上的多个进程(不支持windows)
+ ***************************************************************/
+
+var (
+	// ErrLockHeld 该次触发已由其他持有者执行或正在执行
+	ErrLockHeld = errors.New("cron: lock held by another holder")
+	// ErrStaleLease 租约已过期或已被更新的持有者取代
+	ErrStaleLease = errors.New("cron: stale lease")
+)
+
+// Lease 任务一次触发的租约
+type Lease struct {
+	Name      string    // Name 任务名称
+	Scheduled time.Time // Scheduled 计划触发时间
+	Token     uint64    // Token 防护令牌,同名任务每次获取租约时递增,未使用 Locker 时为0
+	Expires   time.Time // Expires 租约的到期时间
+}
+
+// Locker 分布式锁,保证同名任务的每次触发在集群内只执行一次
+// 所有实例的任务名称与表达式必须一致,计划触发时间相同才能互斥
+type Locker interface {
+	// Acquire 获取name在scheduled时刻触发的租约,有效期ttl
+	// 该次触发已完成,租约被其他持有者持有且未过期,或同名任务已有更晚的触发时返回 ErrLockHeld
+	// 租约过期且未完成时,新的持有者可以接管,得到更大的令牌
+	Acquire(ctx context.Context, name string, scheduled time.Time, ttl time.Duration) (Lease, error)
+	// Complete 标记该次触发已完成,租约已过期或被接管时返回 ErrStaleLease
+	Complete(ctx context.Context, lease Lease) error
+}
+
+// run 一次触发的加锁记录
+type run struct {
+	Token   uint64 `json:"token"`
+	Expires int64  `json:"expires"` // Expires 租约的到期时间,Unix纳秒
+	Done    bool   `json:"done"`
+}
+
+// leaseTable 同名任务的加锁状态,MemoryLocker 与 FileLocker 共用
+type leaseTable struct {
+	Token  uint64         `json:"token"`  // Token 最近发放的令牌
+	Latest int64          `json:"latest"` // Latest 获取过租约的最晚触发时间,早于它的触发不再执行
+	Runs   map[int64]*run `json:"runs"`   // Runs 按计划触发时间索引的记录
+}
+
+// acquire 见 Locker.Acquire
+// 获取新的触发时,清理更早的已完成或已过期的记录
+func (t *leaseTable) acquire(name string, scheduled, now time.Time, ttl time.Duration) (Lease, error) {
+	key := scheduled.UnixNano()
+	if key < t.Latest {
+		return Lease{}, ErrLockHeld
+	}
+	if t.Runs == nil {
+		t.Runs = make(map[int64]*run)
+	}
+	r := t.Runs[key]
+	if r != nil && (r.Done || now.UnixNano() < r.Expires) {
+		return Lease{}, ErrLockHeld
+	}
+	if r == nil {
+		r = &run{}
+		t.Runs[key] = r
+	}
+	t.Token++
+	t.Latest = key
+	expires := now.Add(ttl)
+	r.Token = t.Token
+	r.Expires = expires.UnixNano()
+	for k, old := range t.Runs {
+		if k < key && (old.Done || now.UnixNano() >= old.Expires) {
+			delete(t.Runs, k)
+		}
+	}
+	return Lease{Name: name, Scheduled: scheduled, Token: r.Token, Expires: expires}, nil
+}
+
+// complete 见 Locker.Complete
+func (t *leaseTable) complete(lease Lease, now time.Time) error {
+	r := t.Runs[lease.Scheduled.UnixNano()]
+	if r == nil || r.Done || r.Token != lease.Token || now.UnixNano() >= r.Expires {
+		return ErrStaleLease
+	}
+	r.Done = true
+	return nil
+}
+
+// MemoryLocker 进程内的 Locker,用于测试与同一进程中的多个 Cron
+type MemoryLocker struct {
+	clock  Clock
+	mu     sync.Mutex
+	tables map[string]*leaseTable
+}
+
+// NewMemoryLocker 创建进程内的 Locker,clock为nil时使用系统时钟
+func NewMemoryLocker(clock Clock) *MemoryLocker {
+	if clock == nil {
+		clock = realClock{}
+	}
+	return &MemoryLocker{clock: clock, tables: make(map[string]*leaseTable)}
+}
+
+// Acquire 见 Locker.Acquire
+func (m *MemoryLocker) Acquire(ctx context.Context, name string, scheduled time.Time, ttl time.Duration) (Lease, error) {
+	if err := ctx.Err(); err != nil {
+		return Lease{}, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t := m.tables[name]
+	if t == nil {
+		t = &leaseTable{}
+		m.tables[name] = t
+	}
+	return t.acquire(name, scheduled, m.clock.Now(), ttl)
+}
+
+// Complete 见 Locker.Complete
+func (m *MemoryLocker) Complete(ctx context.Context, lease Lease) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t := m.tables[lease.Name]
+	if t == nil {
+		return ErrStaleLease
+	}
+	return t.complete(lease, m.clock.Now())
+}
@@ -0,0 +1,79 @@
+package Cron
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+/****************************************************************
+ * @author: Ihc
+ * @date: 2026/10/20 12:50
+ * @description:
+ ***************************************************************/
+
+func testLocker(t *testing.T, locker Locker, clock *fakeClock) {
+	ctx := context.Background()
+	scheduled := epoch.Add(time.Minute)
+	first, err := locker.Acquire(ctx, "report", scheduled, 10*time.Second)
+	if err != nil || first.Token != 1 || !first.Expires.Equal(epoch.Add(10*time.Second)) {
+		t.Fatal(first, err)
+	}
+	if _, err := locker.Acquire(ctx, "report", scheduled, 10*time.Second); err != ErrLockHeld {
+		t.Fatal("lease held by another holder", err)
+	}
+	if other, err := locker.Acquire(ctx, "cleanup", scheduled, 10*time.Second); err != nil || other.Token != 1 {
+		t.Fatal("names are locked independently", other, err)
+	}
+
+	// 租约过期后被接管,过期的持有者不能完成
+	clock.Advance(10 * time.Second)
+	second, err := locker.Acquire(ctx, "report", scheduled, 10*time.Second)
+	if err != nil || second.Token != 2 {
+		t.Fatal("expired lease must be taken over", second, err)
+	}
+	if err := locker.Complete(ctx, first); err != ErrStaleLease {
+		t.Fatal("stale holder completed", err)
+	}
+	if err := locker.Complete(ctx, second); err != nil {
+		t.Fatal(err)
+	}
+	if err := locker.Complete(ctx, second); err != ErrStaleLease {
+		t.Fatal("lease completed twice", err)
+	}
+	clock.Advance(time.Minute)
+	if _, err := locker.Acquire(ctx, "report", scheduled, 10*time.Second); err != ErrLockHeld {
+		t.Fatal("completed run must not run again", err)
+	}
+
+	// 更晚的触发开始后,更早的触发不再执行
+	third, err := locker.Acquire(ctx, "report", scheduled.Add(time.Minute), 10*time.Second)
+	if err != nil || third.Token != 3 {
+		t.Fatal(third, err)
+	}
+	if _, err := locker.Acquire(ctx, "report", scheduled, 10*time.Second); err != ErrLockHeld {
+		t.Fatal("earlier run acquired after a later one", err)
+	}
+	// 更早的触发没有记录时同样不再执行
+	if _, err := locker.Acquire(ctx, "audit", scheduled.Add(time.Minute), 10*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := locker.Acquire(ctx, "audit", scheduled, 10*time.Second); err != ErrLockHeld {
+		t.Fatal("unrecorded earlier run acquired after a later one", err)
+	}
+	clock.Advance(10 * time.Second)
+	if err := locker.Complete(ctx, third); err != ErrStaleLease {
+		t.Fatal("expired lease completed", err)
+	}
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := locker.Acquire(canceled, "report", scheduled.Add(time.Hour), time.Second); err != context.Canceled {
+		t.Fatal(err)
+	}
+}
+
+func TestMemoryLocker(t *testing.T) {
+	clock := newFakeClock()
+	testLocker(t, NewMemoryLocker(clock), clock)
+}
@@ -0,0 +1,278 @@
+package Cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/****************************************************************
+ * @author: Ihc
+ * @date: 2026/10/20 10:50
+ * @description: cron表达式解析
+5个字段: 分 时 日 月 周;6个字段: 秒 分 时 日 月 周
+每个字段是逗号分隔的列表,列表项为 *, a, a-b 及其加上 /n 的步长形式,a/n 表示从a到最大值,月与周可以使用名称(JAN,MON,不区分大小写)
+日与周可以使用 ? 表示不限制
+日: L 月末,L-n 月末前n天,LW 月末的工作日,nW 离n日最近的工作日(不跨月)
+周: 0与7都表示周日,nL 本月最后一个周n,n#k 本月第k个周n
+日与周都有限制时满足其一即可,有一个不限制时两者都要满足
+描述符: @yearly(@annually) @monthly @weekly @daily(@midnight) @hourly @every <duration>
+表达式前可以用 CRON_TZ=<时区> 或 TZ=<时区> 指定时区
+ ***************************************************************/
+
+// bounds 字段的取值范围
+type bounds struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var (
+	seconds = bounds{name: "second", min: 0, max: 59}
+	minutes = bounds{name: "minute", min: 0, max: 59}
+	hours   = bounds{name: "hour", min: 0, max: 23}
+	doms    = bounds{name: "day of month", min: 1, max: 31}
+	months  = bounds{name: "month", min: 1, max: 12, names: map[string]int{
+		"JAN": 1, "FEB": 2, "MAR": 3, "APR": 4, "MAY": 5, "JUN": 6,
+		"JUL": 7, "AUG": 8, "SEP": 9, "OCT": 10, "NOV": 11, "DEC": 12,
+	}}
+	dows = bounds{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"SUN": 0, "MON": 1, "TUE": 2, "WED": 3, "THU": 4, "FRI": 5, "SAT": 6,
+	}}
+)
+
+// descriptors 预定义的表达式
+var descriptors = map[string]string{
+	"@yearly":   "0 0 0 1 1 *",
+	"@annually": "0 0 0 1 1 *",
+	"@monthly":  "0 0 0 1 * *",
+	"@weekly":   "0 0 0 * * 0",
+	"@daily":    "0 0 0 * * *",
+	"@midnight": "0 0 0 * * *",
+	"@hourly":   "0 0 * * * *",
+}
+
+// Parse 解析cron表达式,字段数为5时不含秒,为6时第一个字段为秒
+// 未用CRON_TZ或TZ指定时区时,Next 使用参数的时区
+func Parse(spec string) (Schedule, error) {
+	return ParseInLocation(spec, nil)
+}
+
+// ParseInLocation 解析cron表达式,未用CRON_TZ或TZ指定时区时使用loc,loc为nil时使用 Next 参数的时区
+func ParseInLocation(spec string, loc *time.Location) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if strings.HasPrefix(spec, "CRON_TZ=") || strings.HasPrefix(spec, "TZ=") {
+		i := strings.IndexAny(spec, " \t")
+		if i < 0 {
+			return nil, fmt.Errorf("cron: missing fields after time zone in %q", spec)
+		}
+		name := spec[strings.IndexByte(spec, '=')+1 : i]
+		var err error
+		if loc, err = time.LoadLocation(name); err != nil {
+			return nil, fmt.Errorf("cron: invalid time zone %q: %v", name, err)
+		}
+		spec = strings.TrimSpace(spec[i:])
+	}
+	// 描述符不区分大小写
+	if strings.HasPrefix(strings.ToLower(spec), "@every") {
+		d, err := time.ParseDuration(strings.TrimSpace(spec[len("@every"):]))
+		if err != nil {
+			return nil, fmt.Errorf("cron: invalid @every duration in %q: %v", spec, err)
+		}
+		if d <= 0 {
+			return nil, fmt.Errorf("cron: @every duration must be > 0 in %q", spec)
+		}
+		return ConstantDelaySchedule{Delay: d}, nil
+	}
+	if strings.HasPrefix(spec, "@") {
+		expanded, ok := descriptors[strings.ToLower(spec)]
+		if !ok {
+			return nil, fmt.Errorf("cron: unknown descriptor %q", spec)
+		}
+		spec = expanded
+	}
+	fields := strings.Fields(spec)
+	switch len(fields) {
+	case 5:
+		fields = append([]string{"0"}, fields...)
+	case 6:
+	default:
+		return nil, fmt.Errorf("cron: expected 5 or 6 fields, found %d in %q", len(fields), spec)
+	}
+	s := &specSchedule{location: loc}
+	var err error
+	if s.second, err = parseField(fields[0], seconds); err != nil {
+		return nil, err
+	}
+	if s.minute, err = parseField(fields[1], minutes); err != nil {
+		return nil, err
+	}
+	if s.hour, err = parseField(fields[2], hours); err != nil {
+		return nil, err
+	}
+	if err = s.parseDom(fields[3]); err != nil {
+		return nil, err
+	}
+	if s.month, err = parseField(fields[4], months); err != nil {
+		return nil, err
+	}
+	if err = s.parseDow(fields[5]); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// parseField 解析逗号分隔的列表
+func parseField(field string, b bounds) (uint64, error) {
+	var bits uint64
+	for _, item := range strings.Split(field, ",") {
+		v, err := parseItem(item, b)
+		if err != nil {
+			return 0, err
+		}
+		bits |= v
+	}
+	return bits, nil
+}
+
+// parseItem 解析 *, a, a-b 及其步长,?只在日与周字段中有效,由 parseDom 与 parseDow 处理
+func parseItem(item string, b bounds) (uint64, error) {
+	rangePart, step := item, 1
+	if i := strings.IndexByte(item, '/'); i >= 0 {
+		var err error
+		if step, err = strconv.Atoi(item[i+1:]); err != nil || step <= 0 {
+			return 0, fmt.Errorf("cron: invalid step in %s field %q", b.name, item)
+		}
+		rangePart = item[:i]
+	}
+	var lo, hi int
+	switch {
+	case rangePart == "*":
+		lo, hi = b.min, b.max
+	case strings.IndexByte(rangePart, '-') > 0:
+		i := strings.IndexByte(rangePart, '-')
+		var err error
+		if lo, err = parseValue(rangePart[:i], b); err != nil {
+			return 0, err
+		}
+		if hi, err = parseValue(rangePart[i+1:], b); err != nil {
+			return 0, err
+		}
+		if lo > hi {
+			return 0, fmt.Errorf("cron: range start is after end in %s field %q", b.name, item)
+		}
+	default:
+		var err error
+		if lo, err = parseValue(rangePart, b); err != nil {
+			return 0, err
+		}
+		hi = lo
+		// a/n 表示从a开始到最大值
+		if rangePart != item {
+			hi = b.max
+		}
+	}
+	var bits uint64
+	for v := lo; v <= hi; v += step {
+		bits |= 1 << uint(v)
+	}
+	return bits, nil
+}
+
+// parseValue 解析数字或名称
+func parseValue(s string, b bounds) (int, error) {
+	if v, ok := b.names[strings.ToUpper(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("cron: invalid value %q in %s field", s, b.name)
+	}
+	if v < b.min || v > b.max {
+		return 0, fmt.Errorf("cron: value %d out of range [%d, %d] in %s field", v, b.min, b.max, b.name)
+	}
+	return v, nil
+}
+
+// isStar 字段是否不限制取值
+func isStar(field string) bool {
+	return field == "*" || field == "?"
+}
+
+// parseDom 解析日字段,支持?,L,L-n,LW,nW
+func (s *specSchedule) parseDom(field string) error {
+	s.domStar = isStar(field)
+	for _, item := range strings.Split(field, ",") {
+		upper := strings.ToUpper(item)
+		switch {
+		case upper == "L":
+			s.lastDom |= 1
+		case upper == "LW":
+			s.lastWeekday = true
+		case strings.HasPrefix(upper, "L-"):
+			n, err := strconv.Atoi(item[2:])
+			if err != nil || n < 0 || n > 30 {
+				return fmt.Errorf("cron: invalid offset in day of month field %q", item)
+			}
+			s.lastDom |= 1 << uint(n)
+		case strings.HasSuffix(upper, "W"):
+			n, err := parseValue(item[:len(item)-1], doms)
+			if err != nil {
+				return err
+			}
+			s.nearestWeekday |= 1 << uint(n)
+		default:
+			if item == "?" {
+				item = "*"
+			}
+			bits, err := parseItem(item, doms)
+			if err != nil {
+				return err
+			}
+			s.dom |= bits
+		}
+	}
+	return nil
+}
+
+// parseDow 解析周字段,支持?,nL与n#k
+func (s *specSchedule) parseDow(field string) error {
+	s.dowStar = isStar(field)
+	for _, item := range strings.Split(field, ",") {
+		upper := strings.ToUpper(item)
+		switch {
+		case strings.IndexByte(item, '#') > 0:
+			i := strings.IndexByte(item, '#')
+			day, err := parseValue(item[:i], dows)
+			if err != nil {
+				return err
+			}
+			k, err := strconv.Atoi(item[i+1:])
+			if err != nil || k < 1 || k > 5 {
+				return fmt.Errorf("cron: invalid week number in day of week field %q", item)
+			}
+			s.nthDow[day%7] |= 1 << uint(k)
+		case len(upper) > 1 && strings.HasSuffix(upper, "L"):
+			day, err := parseValue(item[:len(item)-1], dows)
+			if err != nil {
+				return err
+			}
+			s.lastDow |= 1 << uint(day%7)
+		default:
+			if item == "?" {
+				item = "*"
+			}
+			bits, err := parseItem(item, dows)
+			if err != nil {
+				return err
+			}
+			s.dow |= bits
+		}
+	}
+	// 7与0都表示周日
+	if s.dow&(1<<7) != 0 {
+		s.dow = s.dow&^(1<<7) | 1
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+package Cron
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+/****************************************************************
+ * @author: Ihc
+ * @date: 2026/10/20 11:30
+ * @description:
+ ***************************************************************/
+
+// from 2026-10-20是周二
+const from = "2026-10-20T10:15:30Z"
+
+func mustParseTime(t *testing.T, value string) time.Time {
+	t.Helper()
+	if value == "" {
+		return time.Time{}
+	}
+	v, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestNext(t *testing.T) {
+	cases := []struct {
+		spec string
+		from string
+		want string
+	}{
+		// 基本字段
+		{"* * * * *", from, "2026-10-20T10:16:00Z"},
+		{"* * * * * *", from, "2026-10-20T10:15:31Z"},
+		{"30 * * * * *", from, "2026-10-20T10:16:30Z"},
+		{"*/15 * * * *", from, "2026-10-20T10:30:00Z"},
+		{"0 5/20 * * * *", from, "2026-10-20T10:25:00Z"},
+		{"0 9-17/2 * * *", from, "2026-10-20T11:00:00Z"},
+		{"0,45 10 * * *", from, "2026-10-20T10:45:00Z"},
+		{"0 10 * * *", from, "2026-10-21T10:00:00Z"},
+		{"0 0 * * MON-FRI", from, "2026-10-21T00:00:00Z"},
+		{"0 0 * * SAT,SUN", from, "2026-10-24T00:00:00Z"},
+		{"0 0 * * 7", from, "2026-10-25T00:00:00Z"},
+		{"0 0 * * 0", from, "2026-10-25T00:00:00Z"},
+		{"0 12 1 JAN *", from, "2027-01-01T12:00:00Z"},
+		{"0 0 * jan-mar mon", from, "2027-01-04T00:00:00Z"},
+		{"0 0 1 */4 *", from, "2027-01-01T00:00:00Z"},
+		{"0 0 29 2 *", from, "2028-02-29T00:00:00Z"},
+		{"0 0 30 2 *", from, ""},
+		// 日与周都有限制时满足其一即可
+		{"0 0 13 * FRI", from, "2026-10-23T00:00:00Z"},
+		{"0 0 13 * ?", from, "2026-11-13T00:00:00Z"},
+		{"0 0 ? * FRI", from, "2026-10-23T00:00:00Z"},
+		// L W #
+		{"0 0 L * *", from, "2026-10-31T00:00:00Z"},
+		{"0 0 L 2 *", from, "2027-02-28T00:00:00Z"},
+		{"0 0 L-1 * *", from, "2026-10-30T00:00:00Z"},
+		{"0 0 L-30 * *", from, "2026-12-01T00:00:00Z"},
+		{"0 0 LW * *", from, "2026-10-30T00:00:00Z"},
+		{"0 0 15W * *", from, "2026-11-16T00:00:00Z"},
+		{"0 0 1W * *", from, "2026-11-02T00:00:00Z"},
+		{"0 0 1W * *", "2026-07-15T00:00:00Z", "2026-08-03T00:00:00Z"},
+		{"0 0 30W * *", "2026-11-01T00:00:00Z", "2026-11-30T00:00:00Z"},
+		{"0 0 31W * *", "2026-10-01T00:00:00Z", "2026-10-30T00:00:00Z"},
+		{"0 0 * * 5L", from, "2026-10-30T00:00:00Z"},
+		{"0 0 * * FRIL", "2026-10-31T00:00:00Z", "2026-11-27T00:00:00Z"},
+		{"0 0 * * FRI#3", from, "2026-11-20T00:00:00Z"},
+		{"0 0 * * 1#1", from, "2026-11-02T00:00:00Z"},
+		{"0 0 0 ? * SUN#5", from, "2026-11-29T00:00:00Z"},
+		// 描述符
+		{"@hourly", from, "2026-10-20T11:00:00Z"},
+		{"@daily", from, "2026-10-21T00:00:00Z"},
+		{"@midnight", from, "2026-10-21T00:00:00Z"},
+		{"@weekly", from, "2026-10-25T00:00:00Z"},
+		{"@monthly", from, "2026-11-01T00:00:00Z"},
+		{"@yearly", from, "2027-01-01T00:00:00Z"},
+		{"@annually", from, "2027-01-01T00:00:00Z"},
+		{"@every 90m", from, "2026-10-20T11:45:30Z"},
+		{"@EVERY 90m", from, "2026-10-20T11:45:30Z"},
+		// 时区
+		{"CRON_TZ=America/New_York 0 9 * * *", from, "2026-10-20T09:00:00-04:00"},
+		{"TZ=Asia/Shanghai 0 0 * * *", from, "2026-10-21T00:00:00+08:00"},
+		// 夏令时开始(2026-03-08 02:00跳到03:00):跳过的时间在03:00触发,且只触发一次
+		{"TZ=America/New_York 30 2 * * *", "2026-03-08T00:00:00-05:00", "2026-03-08T03:00:00-04:00"},
+		{"TZ=America/New_York 30 2 * * *", "2026-03-08T03:00:00-04:00", "2026-03-09T02:30:00-04:00"},
+		{"TZ=America/New_York 0 * * * *", "2026-03-08T01:30:00-05:00", "2026-03-08T03:00:00-04:00"},
+		{"TZ=America/New_York 0 * * * *", "2026-03-08T03:00:00-04:00", "2026-03-08T04:00:00-04:00"},
+		{"TZ=America/New_York */20 2 * * *", "2026-03-08T01:59:00-05:00", "2026-03-08T03:00:00-04:00"},
+		// 夏令时结束(2026-11-01 02:00回到01:00):重复的时间只在第一次出现时触发
+		{"TZ=America/New_York 30 1 * * *", "2026-11-01T00:00:00-04:00", "2026-11-01T01:30:00-04:00"},
+		{"TZ=America/New_York 30 1 * * *", "2026-11-01T01:30:00-04:00", "2026-11-02T01:30:00-05:00"},
+		{"TZ=America/New_York 0 * * * *", "2026-11-01T01:00:00-04:00", "2026-11-01T02:00:00-05:00"},
+		{"TZ=America/New_York */30 * * * *", "2026-11-01T01:10:00-05:00", "2026-11-01T02:00:00-05:00"},
+		{"TZ=America/New_York 0 0 * * *", "2026-10-31T12:00:00-04:00", "2026-11-01T00:00:00-04:00"},
+		{"TZ=America/New_York 0 0 * * *", "2026-11-01T00:00:00-04:00", "2026-11-02T00:00:00-05:00"},
+	}
+	for _, c := range cases {
+		schedule, err := Parse(c.spec)
+		if err != nil {
+			t.Fatalf("%q: %v", c.spec, err)
+		}
+		got := schedule.Next(mustParseTime(t, c.from))
+		want := mustParseTime(t, c.want)
+		if !got.Equal(want) || got.IsZero() != want.IsZero() {
+			t.Errorf("%q from %s: got %v, want %v", c.spec, c.from, got, want)
+		}
+	}
+}
+
+func TestNextKeepsLocation(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	schedule, _ := Parse("0 9 * * *")
+	got := schedule.Next(time.Date(2026, 10, 20, 10, 0, 0, 0, tokyo))
+	if want := time.Date(2026, 10, 21, 9, 0, 0, 0, tokyo); !got.Equal(want) || got.Location() != tokyo {
+		t.Fatal(got)
+	}
+	// ParseInLocation 的时区优先于参数的时区
+	schedule, _ = ParseInLocation("0 9 * * *", tokyo)
+	got = schedule.Next(mustParseTime(t, from))
+	if want := time.Date(2026, 10, 21, 9, 0, 0, 0, tokyo); !got.Equal(want) {
+		t.Fatal(got)
+	}
+	// 表达式中的时区优先于 ParseInLocation
+	schedule, _ = ParseInLocation("TZ=UTC 0 9 * * *", tokyo)
+	if got = schedule.Next(mustParseTime(t, from)); !got.Equal(mustParseTime(t, "2026-10-21T09:00:00Z")) {
+		t.Fatal(got)
+	}
+}
+
+func TestNextSequence(t *testing.T) {
+	schedule, _ := Parse("0 0 9,17 * * MON-FRI")
+	want := []string{
+		"2026-10-20T17:00:00Z",
+		"2026-10-21T09:00:00Z",
+		"2026-10-21T17:00:00Z",
+		"2026-10-22T09:00:00Z",
+		"2026-10-22T17:00:00Z",
+		"2026-10-23T09:00:00Z",
+		"2026-10-23T17:00:00Z",
+		"2026-10-26T09:00:00Z",
+	}
+	next := mustParseTime(t, from)
+	for _, w := range want {
+		if next = schedule.Next(next); !next.Equal(mustParseTime(t, w)) {
+			t.Fatalf("got %v, want %s", next, w)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	specs := []string{
+		"",
+		"* * * *",
+		"* * * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * 32 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"*/x * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+		"1-x * * * *",
+		"* * * FOO *",
+		"* * * * FUNDAY",
+		"* * L-31 * *",
+		"* * L-x * *",
+		"* * W * *",
+		"* * 32W * *",
+		"* * * * MON#6",
+		"* * * * 1#0",
+		"* * * * 8#1",
+		"* * * * 8L",
+		"? * * * *",
+		"* ? * * *",
+		"0 * ? * * *",
+		"* * * ? *",
+		"* * ?/2 * *",
+		"@foo",
+		"@every",
+		"@every -1s",
+		"@every 1x",
+		"TZ=Nowhere/City * * * * *",
+		"CRON_TZ=UTC",
+	}
+	for _, spec := range specs {
+		if _, err := Parse(spec); err == nil {
+			t.Errorf("%q: expected error", spec)
+		}
+	}
+}
@@ -0,0 +1,256 @@
+package Cron
+
+import (
+	"math/bits"
+	"time"
+)
+
+/****************************************************************
+ * @author: Ihc
+ * @date: 2026/10/20 11:10
+ * @description: 调度时间的计算
+按调度的时区逐日查找,在匹配的日期中按时,分,秒的顺序找到第一个晚于给定时间的墙上时间
+夏令时开始时跳过的墙上时间在跳变后的第一个时刻触发,同一时刻只触发一次
+夏令时结束时重复的墙上时间只在第一次出现时触发
+ ***************************************************************/
+
+// maxSearchDays 查找的最大天数,超过后认为不会再触发
+// 2月29日且指定周几的表达式最长28年才出现一次
+const maxSearchDays = 366 * 30
+
+// Schedule 调度,描述任务的触发时间
+type Schedule interface {
+	// Next 返回严格晚于t的下一次触发时间,不会再触发时返回零值
+	Next(t time.Time) time.Time
+}
+
+// ConstantDelaySchedule 固定间隔的调度,由 @every 创建
+type ConstantDelaySchedule struct {
+	Delay time.Duration
+}
+
+// Next 返回t之后Delay的时间
+func (s ConstantDelaySchedule) Next(t time.Time) time.Time {
+	return t.Add(s.Delay)
+}
+
+// alignedDelaySchedule 触发时间对齐到Unix纪元起Delay整数倍的固定间隔调度
+// 设置 Locker 时 @every 使用它,启动时间不同的实例得到相同的计划触发时间,才能互斥
+type alignedDelaySchedule struct {
+	ConstantDelaySchedule
+}
+
+// Next 返回严格晚于t的第一个Delay整数倍的时间
+func (s alignedDelaySchedule) Next(t time.Time) time.Time {
+	d := int64(s.Delay)
+	n := t.UnixNano()
+	r := n % d
+	if r < 0 {
+		r += d
+	}
+	return time.Unix(0, n-r+d).In(t.Location())
+}
+
+// specSchedule cron表达式的调度,每个字段是取值的位图
+type specSchedule struct {
+	second, minute, hour, dom, month, dow uint64
+
+	domStar, dowStar bool     // domStar dowStar 日与周是否不限制
+	lastDom          uint64   // lastDom L-n,第n位表示月末前n天
+	lastWeekday      bool     // lastWeekday LW
+	nearestWeekday   uint64   // nearestWeekday nW,第n位表示离n日最近的工作日
+	lastDow          uint64   // lastDow nL,第n位表示本月最后一个周n
+	nthDow           [7]uint8 // nthDow n#k,nthDow[n]的第k位表示本月第k个周n
+
+	location *time.Location // location 为nil时使用参数的时区
+}
+
+// Next 返回严格晚于t的下一次触发时间
+func (s *specSchedule) Next(t time.Time) time.Time {
+	loc := s.location
+	if loc == nil {
+		loc = t.Location()
+	}
+	t = t.In(loc)
+	year, month, day := t.Date()
+	hour, minute, second := t.Clock()
+	for i := 0; i < maxSearchDays; i++ {
+		// 用UTC计算日期,避免时区影响日期的加减
+		date := time.Date(year, month, day+i, 0, 0, 0, 0, time.UTC)
+		if !s.matchDay(date) {
+			continue
+		}
+		y, m, d := date.Date()
+		first := i == 0
+		for h := nextBit(s.hour, 0); h >= 0; h = nextBit(s.hour, h+1) {
+			if first && h < hour {
+				continue
+			}
+			for mi := nextBit(s.minute, 0); mi >= 0; mi = nextBit(s.minute, mi+1) {
+				if first && h == hour && mi < minute {
+					continue
+				}
+				for sec := nextBit(s.second, 0); sec >= 0; sec = nextBit(s.second, sec+1) {
+					if first && h == hour && mi == minute && sec <= second {
+						continue
+					}
+					if next := wallInstant(y, m, d, h, mi, sec, loc); next.After(t) {
+						return next
+					}
+				}
+			}
+		}
+	}
+	return time.Time{}
+}
+
+// nextBit 返回不小于from的第一个置位的位置,没有时返回-1
+func nextBit(set uint64, from int) int {
+	if from >= 64 {
+		return -1
+	}
+	rest := set >> uint(from)
+	if rest == 0 {
+		return -1
+	}
+	return from + bits.TrailingZeros64(rest)
+}
+
+func has(set uint64, n int) bool {
+	return set&(1<<uint(n)) != 0
+}
+
+// daysIn 月份的天数
+func daysIn(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// matchDay 日期是否满足月,日与周
+func (s *specSchedule) matchDay(date time.Time) bool {
+	if !has(s.month, int(date.Month())) {
+		return false
+	}
+	domMatch, dowMatch := s.matchDom(date), s.matchDow(date)
+	if s.domStar || s.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+func (s *specSchedule) matchDom(date time.Time) bool {
+	year, month, day := date.Date()
+	if has(s.dom, day) {
+		return true
+	}
+	last := daysIn(year, month)
+	if s.lastDom != 0 && last-day < 31 && has(s.lastDom, last-day) {
+		return true
+	}
+	weekday := date.Weekday()
+	if weekday == time.Saturday || weekday == time.Sunday {
+		return false
+	}
+	if s.lastWeekday && nearestWeekday(year, month, last) == day {
+		return true
+	}
+	// 工作日只可能是n-2到n+2中最近的那一天
+	for n := day - 2; n <= day+2; n++ {
+		if n >= 1 && n <= last && has(s.nearestWeekday, n) && nearestWeekday(year, month, n) == day {
+			return true
+		}
+	}
+	return false
+}
+
+// nearestWeekday 离n日最近且在同一个月内的工作日
+func nearestWeekday(year int, month time.Month, n int) int {
+	switch time.Date(year, month, n, 0, 0, 0, 0, time.UTC).Weekday() {
+	case time.Saturday:
+		if n == 1 {
+			return n + 2
+		}
+		return n - 1
+	case time.Sunday:
+		if n == daysIn(year, month) {
+			return n - 2
+		}
+		return n + 1
+	}
+	return n
+}
+
+func (s *specSchedule) matchDow(date time.Time) bool {
+	weekday := int(date.Weekday())
+	if has(s.dow, weekday) {
+		return true
+	}
+	day := date.Day()
+	if has(s.lastDow, weekday) && day+7 > daysIn(date.Year(), date.Month()) {
+		return true
+	}
+	return s.nthDow[weekday]&(1<<uint((day-1)/7+1)) != 0
+}
+
+// wallInstant 返回loc中墙上时间对应的时刻
+// 墙上时间重复时返回较早的时刻;墙上时间不存在时返回跳变后的第一个时刻
+func wallInstant(year int, month time.Month, day, hour, minute, second int, loc *time.Location) time.Time {
+	wall := time.Date(year, month, day, hour, minute, second, 0, time.UTC).Unix()
+	// 跳变前后的偏移,用于找出该墙上时间的全部时刻
+	offsets := [3]int{offsetAt(wall-86400, loc), offsetAt(wall, loc), offsetAt(wall+86400, loc)}
+	var (
+		best  time.Time
+		found bool
+	)
+	for _, offset := range offsets {
+		candidate := time.Unix(wall-int64(offset), 0).In(loc)
+		if wallSeconds(candidate) == wall && (!found || candidate.Before(best)) {
+			best, found = candidate, true
+		}
+	}
+	if found {
+		return best
+	}
+	// 墙上时间落在跳变的间隙中,二分查找墙上时间第一次不早于它的时刻
+	lo, hi := wall-int64(maxInt(offsets[:])), wall-int64(minInt(offsets[:]))
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		if wallSeconds(time.Unix(mid, 0).In(loc)) >= wall {
+			hi = mid
+		} else {
+			lo = mid + 1
+		}
+	}
+	return time.Unix(lo, 0).In(loc)
+}
+
+// offsetAt 时刻unix在loc中的偏移(秒)
+func offsetAt(unix int64, loc *time.Location) int {
+	_, offset := time.Unix(unix, 0).In(loc).Zone()
+	return offset
+}
+
+// wallSeconds 把t的墙上时间当作UTC时的Unix秒数
+func wallSeconds(t time.Time) int64 {
+	_, offset := t.Zone()
+	return t.Unix() + int64(offset)
+}
+
+func maxInt(values []int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v > m {
+			m = v
+		}
+	}
+	return m
+}
+
+func minInt(values []int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
@@ -0,0 +1,249 @@
+package Logger
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+/****************************************************************
+ * @author: Ihc
+ * @date: 2026/10/19 23:10
+ * @description: 异步输出
+Write只把日志复制到有界环形缓冲区,由后台goroutine批量写入下层 Writer,
+写日志的goroutine不会被慢速磁盘阻塞;缓冲区满时的行为由 FullPolicy 决定:
+	1.FullBlock: 阻塞直到有空位,不丢日志
+	2.FullDropNewest: 丢弃新日志
+	3.FullDropOldest: 丢弃最旧的日志
+丢弃日志后,后台goroutine会在下一批日志前写入一条记录丢弃数量的WARN日志
+Sync与Close保证之前写入的日志全部到达下层 Writer
+ ***************************************************************/
+
+// FullPolicy 缓冲区满时的行为
+type FullPolicy int
+
+const (
+	// FullBlock 阻塞直到有空位
+	FullBlock FullPolicy = iota
+	// FullDropNewest 丢弃新日志
+	FullDropNewest
+	// FullDropOldest 丢弃最旧的日志
+	FullDropOldest
+)
+
+// AsyncOption 用于设置异步输出的初始化选项
+type AsyncOption func(options *AsyncOptions)
+
+// AsyncOptions 异步输出初始化选项
+type AsyncOptions struct {
+	bufferSize int              // bufferSize 缓冲区可以容纳的日志条数
+	policy     FullPolicy       // policy 缓冲区满时的行为
+	encoder    Encoder          // encoder 编码丢弃数量的记录,应与Logger的编码器一致
+	nowFunc    func() time.Time // nowFunc 丢弃数量记录的时间来源
+}
+
+// WithBufferSize 设置缓冲区可以容纳的日志条数,默认为1024
+func WithBufferSize(bufferSize int) AsyncOption {
+	return func(options *AsyncOptions) {
+		options.bufferSize = bufferSize
+	}
+}
+
+// WithFullPolicy 设置缓冲区满时的行为,默认为 FullBlock
+func WithFullPolicy(policy FullPolicy) AsyncOption {
+	return func(options *AsyncOptions) {
+		options.policy = policy
+	}
+}
+
+// WithAsyncEncoder 设置丢弃数量记录的编码器,默认为 NewConsoleEncoder
+func WithAsyncEncoder(encoder Encoder) AsyncOption {
+	return func(options *AsyncOptions) {
+		options.encoder = encoder
+	}
+}
+
+// WithAsyncNowFunc 设置丢弃数量记录的时间来源
+func WithAsyncNowFunc(nowFunc func() time.Time) AsyncOption {
+	return func(options *AsyncOptions) {
+		options.nowFunc = nowFunc
+	}
+}
+
+// AsyncWriter 异步输出,实现 Writer
+// 缓冲区的每个槽位持有一块复用的内存,预热后写入不分配内存
+type AsyncWriter struct {
+	mu      sync.Mutex
+	writer  Writer
+	options AsyncOptions
+	ring    [][]byte // ring 环形缓冲区
+	head    int      // head 最旧日志的位置
+	count   int      // count 缓冲区中的日志条数
+	dropped int64    // dropped 尚未记录的丢弃条数
+	queued  uint64   // queued 进入缓冲区的日志序号
+	written uint64   // written 已写入下层的日志序号,丢弃的日志视为已写入
+	closed  bool
+
+	notEmpty *sync.Cond // notEmpty 通知后台goroutine有新日志
+	notFull  *sync.Cond // notFull 通知阻塞的Write有空位
+	flushed  *sync.Cond // flushed 通知Sync写入进度
+	done     chan struct{}
+}
+
+// NewAsyncWriter 创建异步输出,下层 Writer 只会被后台goroutine调用
+func NewAsyncWriter(writer Writer, opts ...AsyncOption) *AsyncWriter {
+	options := AsyncOptions{
+		bufferSize: 1024,
+		policy:     FullBlock,
+		encoder:    NewConsoleEncoder(),
+		nowFunc:    time.Now,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.bufferSize < 1 {
+		options.bufferSize = 1
+	}
+	w := &AsyncWriter{
+		writer:  writer,
+		options: options,
+		ring:    make([][]byte, options.bufferSize),
+		done:    make(chan struct{}),
+	}
+	w.notEmpty = sync.NewCond(&w.mu)
+	w.notFull = sync.NewCond(&w.mu)
+	w.flushed = sync.NewCond(&w.mu)
+	go w.flushLoop()
+	return w
+}
+
+// Write 复制p到缓冲区,缓冲区满时按 FullPolicy 处理
+// 被丢弃的日志同样返回成功,丢弃数量由记录日志体现
+func (w *AsyncWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for !w.closed && w.count == len(w.ring) {
+		switch w.options.policy {
+		case FullDropNewest:
+			w.dropped++
+			w.queued++
+			w.written++
+			return len(p), nil
+		case FullDropOldest:
+			w.head = (w.head + 1) % len(w.ring)
+			w.count--
+			w.dropped++
+			w.written++
+		default:
+			w.notFull.Wait()
+		}
+	}
+	if w.closed {
+		return 0, ErrClosed
+	}
+	i := (w.head + w.count) % len(w.ring)
+	w.ring[i] = append(w.ring[i][:0], p...)
+	w.count++
+	w.queued++
+	w.notEmpty.Signal()
+	return len(p), nil
+}
+
+// Sync 等待之前写入的日志全部写入下层后调用下层的Sync
+func (w *AsyncWriter) Sync() error {
+	w.mu.Lock()
+	target := w.queued
+	for w.written < target && !w.stopped() {
+		w.flushed.Wait()
+	}
+	w.mu.Unlock()
+	return w.writer.Sync()
+}
+
+// Close 停止接收日志,等待缓冲区中的日志全部写入下层并Sync
+// 不会关闭下层 Writer
+func (w *AsyncWriter) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		<-w.done
+		return nil
+	}
+	w.closed = true
+	w.notEmpty.Signal()
+	w.notFull.Broadcast()
+	w.mu.Unlock()
+	<-w.done
+	return w.writer.Sync()
+}
+
+// stopped 后台goroutine是否已退出
+func (w *AsyncWriter) stopped() bool {
+	select {
+	case <-w.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Dropped 尚未记录的丢弃条数
+func (w *AsyncWriter) Dropped() int64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.dropped
+}
+
+// flushLoop 后台goroutine,每次取出缓冲区中的全部日志合并为一次写入
+// 关闭后写完剩余日志再退出
+func (w *AsyncWriter) flushLoop() {
+	defer close(w.done)
+	var batch []byte
+	for {
+		w.mu.Lock()
+		for w.count == 0 && w.dropped == 0 && !w.closed {
+			w.notEmpty.Wait()
+		}
+		if w.count == 0 && w.dropped == 0 {
+			w.mu.Unlock()
+			return
+		}
+		batch = batch[:0]
+		if w.dropped > 0 {
+			batch = w.appendDropped(batch, w.dropped)
+			w.dropped = 0
+		}
+		n := w.count
+		for ; w.count > 0; w.count-- {
+			batch = append(batch, w.ring[w.head]...)
+			if cap(w.ring[w.head]) > maxPooledBuffer {
+				w.ring[w.head] = nil
+			}
+			w.head = (w.head + 1) % len(w.ring)
+		}
+		w.notFull.Broadcast()
+		w.mu.Unlock()
+
+		if _, err := w.writer.Write(batch); err != nil {
+			fmt.Fprintf(os.Stderr, "logger: async write failed: %v\n", err)
+		}
+
+		w.mu.Lock()
+		w.written += uint64(n)
+		w.flushed.Broadcast()
+		w.mu.Unlock()
+	}
+}
+
+// appendDropped 追加一条记录丢弃数量的日志
+func (w *AsyncWriter) appendDropped(buf []byte, dropped int64) []byte {
+	entry := getEntry()
+	entry.Time = w.options.nowFunc()
+	entry.Level = WarnLevel
+	entry.Message = "logger dropped entries"
+	entry.Fields = append(entry.Fields, Int64("dropped", dropped))
+	buf = w.options.encoder.Encode(buf, entry)
+	putEntry(entry)
+	return buf
+}
@@ -0,0 +1,196 @@
+package Logger
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+/****************************************************************
+ * @author: Ihc
+ * @date: 2026/10/19 23:30
+ * @description:
+ ***************************************************************/
+
+// gateWriter 在gate关闭前阻塞写入,模拟慢速磁盘
+type gateWriter struct {
+	mu      sync.Mutex
+	buf     bytes.Buffer
+	syncs   int
+	entered chan struct{} // entered 第一次进入Write时通知
+	gate    chan struct{}
+}
+
+func newGateWriter() *gateWriter {
+	return &gateWriter{entered: make(chan struct{}, 1), gate: make(chan struct{})}
+}
+
+func (w *gateWriter) Write(p []byte) (int, error) {
+	select {
+	case w.entered <- struct{}{}:
+	default:
+	}
+	<-w.gate
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Write(p)
+}
+
+func (w *gateWriter) Sync() error {
+	w.mu.Lock()
+	w.syncs++
+	w.mu.Unlock()
+	return nil
+}
+
+func (w *gateWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.String()
+}
+
+// fillAsync 先写入"0"并等待后台goroutine阻塞在下层写入中,再写满缓冲区
+func fillAsync(t *testing.T, policy FullPolicy) (*AsyncWriter, *gateWriter) {
+	gate := newGateWriter()
+	w := NewAsyncWriter(gate, WithBufferSize(3), WithFullPolicy(policy),
+		WithAsyncNowFunc(func() time.Time { return fixedTime }))
+	if _, err := w.Write([]byte("0\n")); err != nil {
+		t.Fatal(err)
+	}
+	<-gate.entered
+	for i := 1; i <= 3; i++ {
+		if _, err := w.Write([]byte(strconv.Itoa(i) + "\n")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return w, gate
+}
+
+func TestAsyncDropNewest(t *testing.T) {
+	w, gate := fillAsync(t, FullDropNewest)
+	_, _ = w.Write([]byte("4\n"))
+	_, _ = w.Write([]byte("5\n"))
+	if w.Dropped() != 2 {
+		t.Fatalf("dropped %d", w.Dropped())
+	}
+	close(gate.gate)
+	if err := w.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	want := "0\n2026-10-19T20:50:00.000Z WARN logger dropped entries dropped=2\n1\n2\n3\n"
+	if gate.String() != want {
+		t.Fatalf("got %q, want %q", gate.String(), want)
+	}
+	_ = w.Close()
+}
+
+func TestAsyncDropOldest(t *testing.T) {
+	w, gate := fillAsync(t, FullDropOldest)
+	_, _ = w.Write([]byte("4\n"))
+	_, _ = w.Write([]byte("5\n"))
+	close(gate.gate)
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	want := "0\n2026-10-19T20:50:00.000Z WARN logger dropped entries dropped=2\n3\n4\n5\n"
+	if gate.String() != want {
+		t.Fatalf("got %q, want %q", gate.String(), want)
+	}
+}
+
+func TestAsyncBlock(t *testing.T) {
+	w, gate := fillAsync(t, FullBlock)
+	returned := make(chan struct{})
+	go func() {
+		_, _ = w.Write([]byte("4\n"))
+		close(returned)
+	}()
+	select {
+	case <-returned:
+		t.Fatal("Write returned while buffer is full")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(gate.gate)
+	<-returned
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if gate.String() != "0\n1\n2\n3\n4\n" {
+		t.Fatalf("got %q", gate.String())
+	}
+}
+
+func TestAsyncClose(t *testing.T) {
+	gate := newGateWriter()
+	close(gate.gate)
+	w := NewAsyncWriter(gate)
+	logger := NewLogger(WithWriter(w))
+	for i := 0; i < 100; i++ {
+		logger.Info("line", Int("n", i))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(gate.String(), "\n"); n != 100 || gate.syncs != 1 {
+		t.Fatalf("lines %d, syncs %d", n, gate.syncs)
+	}
+	if _, err := w.Write([]byte("late\n")); err != ErrClosed {
+		t.Fatalf("write after close: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAsyncConcurrent(t *testing.T) {
+	gate := newGateWriter()
+	close(gate.gate)
+	w := NewAsyncWriter(gate, WithBufferSize(8))
+	logger := NewLogger(WithWriter(w))
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				logger.Info("concurrent", Int("n", j))
+				if j%64 == 0 {
+					_ = logger.Sync()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(gate.String(), "\n"), "\n")
+	if len(lines) != 1600 {
+		t.Fatalf("got %d lines", len(lines))
+	}
+	for _, line := range lines {
+		if !strings.Contains(line, "INFO concurrent n=") {
+			t.Fatalf("corrupted line %q", line)
+		}
+	}
+}
+
+func TestAsyncZeroAllocation(t *testing.T) {
+	if raceEnabled {
+		t.Skip("sync.Pool drops objects under the race detector")
+	}
+	w := NewAsyncWriter(AddSync(io.Discard), WithBufferSize(4096))
+	defer w.Close()
+	logger := NewLogger(WithWriter(w), WithEncoder(NewJSONEncoder()))
+	for i := 0; i < 4096; i++ {
+		logCommon(logger)
+	}
+	_ = w.Sync()
+	if n := testing.AllocsPerRun(1000, func() { logCommon(logger) }); n != 0 {
+		t.Fatalf("async write allocates %v times", n)
+	}
+}
@@ -0,0 +1,92 @@
+package Logger
+
+import (
+	"context"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+/****************************************************************
+ * @author: Ihc
+ * @date: 2026/10/20 02:10
+ * @description: 调用位置,调用栈与context中的字段
+	1.WithCaller: 记录调用Logger方法的文件,行号与函数,包装Logger的函数用WithCallerSkip跳过
+	2.WithStacktrace: 指定等级及以上的日志附带调用栈
+	3.WithContextExtractor: XxxCtx方法从context.Context中提取请求范围的字段,如trace ID,user ID
+ ***************************************************************/
+
+// Caller 调用位置
+type Caller struct {
+	Defined  bool   // Defined 是否记录了调用位置
+	File     string // File 文件的完整路径
+	Line     int    // Line 行号
+	Function string // Function 包含包路径的函数名
+}
+
+// newCaller 解析pc对应的调用位置
+func newCaller(pc uintptr) Caller {
+	if pc == 0 {
+		return Caller{}
+	}
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	return Caller{Defined: true, File: frame.File, Line: frame.Line, Function: frame.Function}
+}
+
+// TrimmedPath 只保留最后一级目录的 文件:行号,如 Logger/logger.go:42
+func (c Caller) TrimmedPath() string {
+	file := c.File
+	if i := strings.LastIndexByte(file, '/'); i >= 0 {
+		if j := strings.LastIndexByte(file[:i], '/'); j >= 0 {
+			file = file[j+1:]
+		}
+	}
+	return file + ":" + strconv.Itoa(c.Line)
+}
+
+// callerPC 返回调用位置,skip为0时是callerPC的调用者
+func callerPC(skip int) uintptr {
+	var pcs [1]uintptr
+	if runtime.Callers(skip+2, pcs[:]) == 0 {
+		return 0
+	}
+	return pcs[0]
+}
+
+// maxStackDepth 调用栈的最大深度
+const maxStackDepth = 64
+
+// stacktrace 返回调用栈,skip为0时从stacktrace的调用者开始
+// 每帧两行: 函数名,缩进的 文件:行号
+func stacktrace(skip int) string {
+	var pcs [maxStackDepth]uintptr
+	n := runtime.Callers(skip+2, pcs[:])
+	frames := runtime.CallersFrames(pcs[:n])
+	var b strings.Builder
+	for {
+		frame, more := frames.Next()
+		b.WriteString(frame.Function)
+		b.WriteString("\n\t")
+		b.WriteString(frame.File)
+		b.WriteByte(':')
+		b.WriteString(strconv.Itoa(frame.Line))
+		if !more {
+			break
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// ContextExtractor 从ctx中提取字段追加到fields并返回
+type ContextExtractor func(ctx context.Context, fields []Field) []Field
+
+// ContextValue 创建提取ctx.Value(key)的 ContextExtractor,值不为nil时以field为键追加
+func ContextValue(key interface{}, field string) ContextExtractor {
+	return func(ctx context.Context, fields []Field) []Field {
+		if v := ctx.Value(key); v != nil {
+			fields = append(fields, Any(field, v))
+		}
+		return fields
+	}
+}
@@ -0,0 +1,110 @@
+package Logger
+
+import (
+	"context"
+	"encoding/json"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+/****************************************************************
+ * @author: Ihc
+ * @date: 2026/10/20 02:40
+ * @description:
+ ***************************************************************/
+
+func TestCaller(t *testing.T) {
+	logger, buf := newTestLogger(WithCaller(true))
+	_, _, line, _ := runtime.Caller(0)
+	logger.Info("here")
+	want := "INFO here caller=Logger/caller_test.go:" + strconv.Itoa(line+1) + " func=preseus/Logger.TestCaller\n"
+	if !strings.HasSuffix(buf.String(), want) {
+		t.Fatalf("got %q, want suffix %q", buf.String(), want)
+	}
+}
+
+// logVia 包装Logger的函数,调用位置应当是logVia的调用者
+func logVia(logger *Logger, msg string) {
+	logger.Warn(msg)
+}
+
+func TestCallerSkip(t *testing.T) {
+	logger, buf := newTestLogger(WithCaller(true), WithCallerSkip(1), WithEncoder(NewJSONEncoder()))
+	_, _, line, _ := runtime.Caller(0)
+	logVia(logger, "wrapped")
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded["caller"] != "Logger/caller_test.go:"+strconv.Itoa(line+1) || decoded["func"] != "preseus/Logger.TestCallerSkip" {
+		t.Fatalf("got %v", decoded)
+	}
+}
+
+func TestStacktrace(t *testing.T) {
+	logger, buf := newTestLogger(WithStacktrace(ErrorLevel))
+	logger.Warn("no stack")
+	logger.Error("with stack")
+	lines := strings.Split(buf.String(), "\n")
+	if !strings.HasSuffix(lines[0], "WARN no stack") || !strings.HasSuffix(lines[1], "ERROR with stack") {
+		t.Fatalf("got %q", buf.String())
+	}
+	if lines[2] != "preseus/Logger.TestStacktrace" || !strings.Contains(lines[3], "caller_test.go:") {
+		t.Fatalf("stack starts with %q %q", lines[2], lines[3])
+	}
+
+	logger, buf = newTestLogger(WithStacktrace(ErrorLevel), WithEncoder(NewJSONEncoder()))
+	logger.Error("json")
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if stack, _ := decoded["stack"].(string); !strings.HasPrefix(stack, "preseus/Logger.TestStacktrace\n\t") {
+		t.Fatalf("stack %q", stack)
+	}
+}
+
+type ctxKey string
+
+func TestContextExtractor(t *testing.T) {
+	logger, buf := newTestLogger(WithContextExtractor(
+		ContextValue(ctxKey("trace"), "trace_id"),
+		ContextValue(ctxKey("user"), "user_id"),
+	))
+	ctx := context.WithValue(context.Background(), ctxKey("trace"), "t-1")
+	ctx = context.WithValue(ctx, ctxKey("user"), 42)
+	logger.With(String("service", "api")).InfoCtx(ctx, "handled", Int("status", 200))
+	logger.ErrorCtx(context.WithValue(context.Background(), ctxKey("trace"), "t-2"), "failed")
+	logger.Info("plain")
+	want := []string{
+		"INFO handled service=api trace_id=t-1 user_id=42 status=200",
+		"ERROR failed trace_id=t-2",
+		"INFO plain",
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != len(want) {
+		t.Fatalf("got %q", buf.String())
+	}
+	for i := range want {
+		if !strings.HasSuffix(lines[i], want[i]) {
+			t.Fatalf("line %d: got %q, want suffix %q", i, lines[i], want[i])
+		}
+	}
+}
+
+func TestCtxMethods(t *testing.T) {
+	exited := false
+	logger, buf := newTestLogger(WithLevel(DebugLevel), WithExitFunc(func(int) { exited = true }),
+		WithContextExtractor(ContextValue(ctxKey("trace"), "trace_id")))
+	ctx := context.WithValue(context.Background(), ctxKey("trace"), "t")
+	logger.DebugCtx(ctx, "d")
+	logger.InfoCtx(ctx, "i")
+	logger.WarnCtx(ctx, "w")
+	logger.ErrorCtx(ctx, "e")
+	logger.FatalCtx(ctx, "f")
+	if !exited || strings.Count(buf.String(), "trace_id=t") != 5 {
+		t.Fatalf("exited %v, output %q", exited, buf.String())
+	}
+}
@@ -0,0 +1,280 @@
+package Logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+/****************************************************************
+ * @author: Ihc
+ * @date: 2026/10/19 21:30
+ * @description: 日志编码器
+	1.console: 便于人阅读, 2026-10-19T21:30:00.000Z INFO [name] msg key=value
+	2.logfmt: time=2026-10-19T21:30:00.000Z level=info logger=name msg=msg key=value
+	3.json: {"time":"2026-10-19T21:30:00.000Z","level":"info","logger":"name","msg":"msg","key":"value"}
+Logger没有名称时不输出名称;调用位置以caller与func字段输出在最后,
+调用栈在console中另起多行输出,在logfmt与json中为stack字段
+编码器直接追加到调用方提供的缓冲区,常用类型的字段编码不分配内存
+ ***************************************************************/
+
+// timeLayout 日志时间格式
+const timeLayout = "2006-01-02T15:04:05.000Z07:00"
+
+// Entry 一条日志
+type Entry struct {
+	Time    time.Time
+	Level   Level
+	Name    string // Name Logger的名称,根Logger为空
+	Message string
+	Fields  []Field // Fields 上下文字段在前,调用时传入的字段在后
+	PC      uintptr // PC 调用Logger方法的位置,只在需要时填充,否则为0
+	Caller  Caller  // Caller 调用位置,开启 WithCaller 时填充
+	Stack   string  // Stack 调用栈,开启 WithStacktrace 且等级足够时填充
+}
+
+// Encoder 日志编码器,必须并发安全
+type Encoder interface {
+	// Encode 将entry编码后追加到buf,以换行结尾
+	Encode(buf []byte, entry *Entry) []byte
+}
+
+// consoleEncoder 便于人阅读的编码器
+type consoleEncoder struct{}
+
+// NewConsoleEncoder 创建便于人阅读的编码器,Logger默认使用该编码器
+func NewConsoleEncoder() Encoder {
+	return consoleEncoder{}
+}
+
+func (consoleEncoder) Encode(buf []byte, entry *Entry) []byte {
+	buf = entry.Time.AppendFormat(buf, timeLayout)
+	buf = append(buf, ' ')
+	buf = append(buf, entry.Level.CapitalString()...)
+	buf = append(buf, ' ')
+	if entry.Name != "" {
+		buf = append(buf, '[')
+		buf = append(buf, entry.Name...)
+		buf = append(buf, "] "...)
+	}
+	buf = append(buf, entry.Message...)
+	for i := range entry.Fields {
+		buf = appendTextField(buf, &entry.Fields[i])
+	}
+	if entry.Caller.Defined {
+		buf = append(buf, " caller="...)
+		buf = append(buf, entry.Caller.TrimmedPath()...)
+		buf = append(buf, " func="...)
+		buf = appendText(buf, entry.Caller.Function)
+	}
+	if entry.Stack != "" {
+		buf = append(buf, '\n')
+		buf = append(buf, entry.Stack...)
+	}
+	return append(buf, '\n')
+}
+
+// logfmtEncoder logfmt编码器
+type logfmtEncoder struct{}
+
+// NewLogfmtEncoder 创建logfmt编码器
+func NewLogfmtEncoder() Encoder {
+	return logfmtEncoder{}
+}
+
+func (logfmtEncoder) Encode(buf []byte, entry *Entry) []byte {
+	buf = append(buf, "time="...)
+	buf = entry.Time.AppendFormat(buf, timeLayout)
+	buf = append(buf, " level="...)
+	buf = append(buf, entry.Level.String()...)
+	if entry.Name != "" {
+		buf = append(buf, " logger="...)
+		buf = appendText(buf, entry.Name)
+	}
+	buf = append(buf, " msg="...)
+	buf = appendText(buf, entry.Message)
+	for i := range entry.Fields {
+		buf = appendTextField(buf, &entry.Fields[i])
+	}
+	if entry.Caller.Defined {
+		buf = append(buf, " caller="...)
+		buf = appendText(buf, entry.Caller.TrimmedPath())
+		buf = append(buf, " func="...)
+		buf = appendText(buf, entry.Caller.Function)
+	}
+	if entry.Stack != "" {
+		buf = append(buf, " stack="...)
+		buf = appendText(buf, entry.Stack)
+	}
+	return append(buf, '\n')
+}
+
+// appendTextField 以 key=value 格式追加字段,console与logfmt共用
+func appendTextField(buf []byte, f *Field) []byte {
+	if f.Type == SkipType {
+		return buf
+	}
+	buf = append(buf, ' ')
+	buf = appendText(buf, f.Key)
+	buf = append(buf, '=')
+	switch f.Type {
+	case StringType:
+		return appendText(buf, f.String)
+	case IntType:
+		return strconv.AppendInt(buf, f.Integer, 10)
+	case FloatType:
+		return strconv.AppendFloat(buf, f.float(), 'g', -1, 64)
+	case BoolType:
+		return strconv.AppendBool(buf, f.Integer == 1)
+	case DurationType:
+		return appendDuration(buf, time.Duration(f.Integer))
+	case TimeType:
+		return f.time().AppendFormat(buf, timeLayout)
+	case ErrorType:
+		return appendText(buf, f.Interface.(error).Error())
+	case StringerType:
+		return appendText(buf, f.Interface.(fmt.Stringer).String())
+	}
+	return appendText(buf, fmt.Sprint(f.Interface))
+}
+
+// appendText 追加文本,包含空白,引号或等号时加引号
+func appendText(buf []byte, s string) []byte {
+	if s == "" || strings.IndexFunc(s, needsQuote) >= 0 {
+		return strconv.AppendQuote(buf, s)
+	}
+	return append(buf, s...)
+}
+
+// needsQuote 字符是否需要加引号
+func needsQuote(r rune) bool {
+	return r <= ' ' || r == '=' || r == '"' || r == utf8.RuneError || r == 0x7f
+}
+
+// jsonEncoder JSON编码器,每条日志一行
+type jsonEncoder struct{}
+
+// NewJSONEncoder 创建JSON编码器
+func NewJSONEncoder() Encoder {
+	return jsonEncoder{}
+}
+
+func (jsonEncoder) Encode(buf []byte, entry *Entry) []byte {
+	buf = append(buf, `{"time":"`...)
+	buf = entry.Time.AppendFormat(buf, timeLayout)
+	buf = append(buf, `","level":"`...)
+	buf = append(buf, entry.Level.String()...)
+	buf = append(buf, '"')
+	if entry.Name != "" {
+		buf = append(buf, `,"logger":`...)
+		buf = appendJSONString(buf, entry.Name)
+	}
+	buf = append(buf, `,"msg":`...)
+	buf = appendJSONString(buf, entry.Message)
+	for i := range entry.Fields {
+		buf = appendJSONField(buf, &entry.Fields[i])
+	}
+	if entry.Caller.Defined {
+		buf = append(buf, `,"caller":`...)
+		buf = appendJSONString(buf, entry.Caller.TrimmedPath())
+		buf = append(buf, `,"func":`...)
+		buf = appendJSONString(buf, entry.Caller.Function)
+	}
+	if entry.Stack != "" {
+		buf = append(buf, `,"stack":`...)
+		buf = appendJSONString(buf, entry.Stack)
+	}
+	return append(buf, "}\n"...)
+}
+
+// appendJSONField 以 ,"key":value 格式追加字段
+func appendJSONField(buf []byte, f *Field) []byte {
+	if f.Type == SkipType {
+		return buf
+	}
+	buf = append(buf, ',')
+	buf = appendJSONString(buf, f.Key)
+	buf = append(buf, ':')
+	switch f.Type {
+	case StringType:
+		return appendJSONString(buf, f.String)
+	case IntType:
+		return strconv.AppendInt(buf, f.Integer, 10)
+	case FloatType:
+		v := f.float()
+		// JSON没有NaN与无穷大,以字符串表示
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			buf = append(buf, '"')
+			buf = strconv.AppendFloat(buf, v, 'g', -1, 64)
+			return append(buf, '"')
+		}
+		return strconv.AppendFloat(buf, v, 'g', -1, 64)
+	case BoolType:
+		return strconv.AppendBool(buf, f.Integer == 1)
+	case DurationType:
+		buf = append(buf, '"')
+		buf = appendDuration(buf, time.Duration(f.Integer))
+		return append(buf, '"')
+	case TimeType:
+		buf = append(buf, '"')
+		buf = f.time().AppendFormat(buf, timeLayout)
+		return append(buf, '"')
+	case ErrorType:
+		return appendJSONString(buf, f.Interface.(error).Error())
+	case StringerType:
+		return appendJSONString(buf, f.Interface.(fmt.Stringer).String())
+	}
+	data, err := json.Marshal(f.Interface)
+	if err != nil {
+		return appendJSONString(buf, "!ERROR: "+err.Error())
+	}
+	return append(buf, data...)
+}
+
+const hexDigits = "0123456789abcdef"
+
+// appendJSONString 追加转义后带引号的JSON字符串,非法的UTF-8替换为U+FFFD
+func appendJSONString(buf []byte, s string) []byte {
+	buf = append(buf, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			if c >= 0x20 && c != '"' && c != '\\' {
+				i++
+				continue
+			}
+			buf = append(buf, s[start:i]...)
+			switch c {
+			case '"', '\\':
+				buf = append(buf, '\\', c)
+			case '\n':
+				buf = append(buf, '\\', 'n')
+			case '\r':
+				buf = append(buf, '\\', 'r')
+			case '\t':
+				buf = append(buf, '\\', 't')
+			default:
+				buf = append(buf, '\\', 'u', '0', '0', hexDigits[c>>4], hexDigits[c&0xf])
+			}
+			i++
+			start = i
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			buf = append(buf, s[start:i]...)
+			buf = append(buf, `�`...)
+			i++
+			start = i
+			continue
+		}
+		i += size
+	}
+	buf = append(buf, s[start:]...)
+	return append(buf, '"')
+}
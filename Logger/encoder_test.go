@@ -0,0 +1,199 @@
+package Logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+/****************************************************************
+ * @author: Ihc
+ * @date: 2026/10/19 21:50
+ * @description:
+ ***************************************************************/
+
+type point struct {
+	X, Y int
+}
+
+func TestEncoders(t *testing.T) {
+	entry := &Entry{
+		Time:    fixedTime,
+		Level:   WarnLevel,
+		Message: "slow request",
+		Fields: []Field{
+			String("path", "/a b"),
+			Int("status", 200),
+			Float64("ratio", 0.5),
+			Bool("cached", false),
+			Duration("elapsed", 1500*time.Millisecond),
+			Time("at", fixedTime),
+			Err(errors.New("timeout")),
+			Err(nil),
+			Any("point", point{1, 2}),
+		},
+	}
+	tests := []struct {
+		name    string
+		encoder Encoder
+		want    string
+	}{
+		{"console", NewConsoleEncoder(),
+			`2026-10-19T20:50:00.000Z WARN slow request path="/a b" status=200 ratio=0.5 cached=false elapsed=1.5s at=2026-10-19T20:50:00.000Z error=timeout point="{1 2}"`},
+		{"logfmt", NewLogfmtEncoder(),
+			`time=2026-10-19T20:50:00.000Z level=warn msg="slow request" path="/a b" status=200 ratio=0.5 cached=false elapsed=1.5s at=2026-10-19T20:50:00.000Z error=timeout point="{1 2}"`},
+		{"json", NewJSONEncoder(),
+			`{"time":"2026-10-19T20:50:00.000Z","level":"warn","msg":"slow request","path":"/a b","status":200,"ratio":0.5,"cached":false,"elapsed":"1.5s","at":"2026-10-19T20:50:00.000Z","error":"timeout","point":{"X":1,"Y":2}}`},
+	}
+	for _, test := range tests {
+		got := string(test.encoder.Encode(nil, entry))
+		if got != test.want+"\n" {
+			t.Fatalf("%s: got %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestJSONEscape(t *testing.T) {
+	entry := &Entry{
+		Time:    fixedTime,
+		Message: "quote\" backslash\\ newline\n tab\t ctrl\x01 中文 bad\xff",
+		Fields:  []Field{Float64("nan", math.NaN()), String("k\"ey", "v")},
+	}
+	data := NewJSONEncoder().Encode(nil, entry)
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("invalid json %q: %v", data, err)
+	}
+	if decoded["msg"] != "quote\" backslash\\ newline\n tab\t ctrl\x01 中文 bad�" {
+		t.Fatalf("msg %q", decoded["msg"])
+	}
+	if decoded["nan"] != "NaN" || decoded["k\"ey"] != "v" {
+		t.Fatalf("decoded %v", decoded)
+	}
+}
+
+func TestAppendDuration(t *testing.T) {
+	durations := []time.Duration{
+		0, 1, 999, time.Microsecond, 1500 * time.Nanosecond, time.Millisecond + 20*time.Microsecond,
+		time.Second, 90 * time.Second, 26*time.Hour + 3*time.Minute + 500*time.Millisecond,
+		-time.Minute, math.MaxInt64, math.MinInt64,
+	}
+	for _, d := range durations {
+		if got := string(appendDuration(nil, d)); got != d.String() {
+			t.Fatalf("appendDuration(%d) = %q, want %q", int64(d), got, d.String())
+		}
+	}
+}
+
+func TestAnyTypes(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		typ   FieldType
+	}{
+		{"s", StringType}, {1, IntType}, {int8(1), IntType}, {uint16(1), IntType}, {1.5, FloatType},
+		{true, BoolType}, {time.Second, DurationType}, {fixedTime, TimeType},
+		{errors.New("e"), ErrorType}, {WarnLevel, StringerType}, {[]int{1}, ReflectType},
+	}
+	for _, test := range tests {
+		if f := Any("k", test.value); f.Type != test.typ {
+			t.Fatalf("Any(%T) type %d, want %d", test.value, f.Type, test.typ)
+		}
+	}
+	old := time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC)
+	if got := Time("t", old).time(); !got.Equal(old) {
+		t.Fatalf("time out of range: %v", got)
+	}
+}
+
+func TestLoggerEncoder(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := NewLogger(
+		WithWriter(AddSync(buf)),
+		WithEncoder(NewJSONEncoder()),
+		WithNowFunc(func() time.Time { return fixedTime }),
+	)
+	logger.With(String("service", "api")).Info("started", Int("port", 8080))
+	want := `{"time":"2026-10-19T20:50:00.000Z","level":"info","msg":"started","service":"api","port":8080}` + "\n"
+	if buf.String() != want {
+		t.Fatalf("got %q, want %q", buf.String(), want)
+	}
+}
+
+func newDiscardLogger(encoder Encoder) *Logger {
+	return NewLogger(WithWriter(AddSync(io.Discard)), WithEncoder(encoder)).With(String("service", "api"))
+}
+
+var errTimeout = errors.New("timeout")
+
+func logCommon(logger *Logger) {
+	logger.Info("request done",
+		String("path", "/api/users"),
+		Int("status", 200),
+		Duration("elapsed", 1500*time.Microsecond),
+		Err(errTimeout),
+	)
+}
+
+func TestZeroAllocation(t *testing.T) {
+	if raceEnabled {
+		t.Skip("sync.Pool drops objects under the race detector")
+	}
+	logger := newDiscardLogger(NewJSONEncoder())
+	if n := testing.AllocsPerRun(100, func() {
+		logger.Debug("disabled", String("path", "/api/users"), Int("status", 200))
+	}); n != 0 {
+		t.Fatalf("disabled level allocates %v times", n)
+	}
+	for _, encoder := range []Encoder{NewConsoleEncoder(), NewLogfmtEncoder(), NewJSONEncoder()} {
+		logger := newDiscardLogger(encoder)
+		if n := testing.AllocsPerRun(100, func() { logCommon(logger) }); n != 0 {
+			t.Fatalf("%T allocates %v times", encoder, n)
+		}
+	}
+}
+
+func BenchmarkDisabledLevel(b *testing.B) {
+	logger := newDiscardLogger(NewJSONEncoder())
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		logger.Debug("disabled", String("path", "/api/users"), Int("status", 200))
+	}
+}
+
+func benchmarkEncoder(b *testing.B, encoder Encoder) {
+	logger := newDiscardLogger(encoder)
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			logCommon(logger)
+		}
+	})
+}
+
+func BenchmarkConsoleEncoder(b *testing.B) {
+	benchmarkEncoder(b, NewConsoleEncoder())
+}
+
+func BenchmarkLogfmtEncoder(b *testing.B) {
+	benchmarkEncoder(b, NewLogfmtEncoder())
+}
+
+func BenchmarkJSONEncoder(b *testing.B) {
+	benchmarkEncoder(b, NewJSONEncoder())
+}
+
+func TestEntryReuse(t *testing.T) {
+	// 复用的Entry不能残留上一条日志的字段
+	buf := &bytes.Buffer{}
+	logger := NewLogger(WithWriter(AddSync(buf)), WithNowFunc(func() time.Time { return fixedTime }))
+	logger.Info("first", String("a", "1"))
+	logger.Info("second")
+	if lines := strings.Split(buf.String(), "\n"); lines[1] != "2026-10-19T20:50:00.000Z INFO second" {
+		t.Fatalf("entry reuse leaked fields: %q", lines[1])
+	}
+}
@@ -0,0 +1,262 @@
+package Logger
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+/****************************************************************
+ * @author: Ihc
+ * @date: 2026/10/19 21:10
+ * @description: 类型化的日志字段
+常用类型的值直接保存在 Field 中,构造字段与编码时都不需要装箱,
+使用 Any 时对无法识别的类型才退化为反射编码
+ ***************************************************************/
+
+// FieldType 字段值的类型,决定编码器如何解释 Field
+type FieldType uint8
+
+const (
+	// UnknownType 未初始化的字段
+	UnknownType FieldType = iota
+	// SkipType 不输出的字段,如值为nil的 Err
+	SkipType
+	// StringType 值在 Field.String
+	StringType
+	// IntType 值在 Field.Integer
+	IntType
+	// FloatType 值为 Field.Integer 中的float64位模式
+	FloatType
+	// BoolType Field.Integer 为1表示true
+	BoolType
+	// DurationType 值为 Field.Integer 中的纳秒数
+	DurationType
+	// TimeType 值为 Field.Integer 中的Unix纳秒与 Field.Interface 中的*time.Location,
+	// 超出纳秒表示范围时 Field.Interface 为time.Time
+	TimeType
+	// ErrorType 值为 Field.Interface 中的error
+	ErrorType
+	// StringerType 值为 Field.Interface 中的fmt.Stringer,编码时才调用String
+	StringerType
+	// ReflectType 值为 Field.Interface,JSON编码器使用encoding/json,其他编码器使用fmt
+	ReflectType
+)
+
+// Field 日志字段
+type Field struct {
+	Key       string
+	Type      FieldType
+	Integer   int64
+	String    string
+	Interface interface{}
+}
+
+// String 字符串字段
+func String(key string, value string) Field {
+	return Field{Key: key, Type: StringType, String: value}
+}
+
+// Int 整数字段
+func Int(key string, value int) Field {
+	return Field{Key: key, Type: IntType, Integer: int64(value)}
+}
+
+// Int64 整数字段
+func Int64(key string, value int64) Field {
+	return Field{Key: key, Type: IntType, Integer: value}
+}
+
+// Float64 浮点数字段
+func Float64(key string, value float64) Field {
+	return Field{Key: key, Type: FloatType, Integer: int64(math.Float64bits(value))}
+}
+
+// Bool 布尔字段
+func Bool(key string, value bool) Field {
+	var integer int64
+	if value {
+		integer = 1
+	}
+	return Field{Key: key, Type: BoolType, Integer: integer}
+}
+
+// Duration 时长字段,以 time.Duration.String 的格式输出
+func Duration(key string, value time.Duration) Field {
+	return Field{Key: key, Type: DurationType, Integer: int64(value)}
+}
+
+// minTime maxTime UnixNano可以表示的时间范围
+var (
+	minTime = time.Unix(0, math.MinInt64)
+	maxTime = time.Unix(0, math.MaxInt64)
+)
+
+// Time 时间字段
+func Time(key string, value time.Time) Field {
+	if value.Before(minTime) || value.After(maxTime) {
+		return Field{Key: key, Type: TimeType, Interface: value}
+	}
+	return Field{Key: key, Type: TimeType, Integer: value.UnixNano(), Interface: value.Location()}
+}
+
+// Err 键为"error"的错误字段,err为nil时不输出
+func Err(err error) Field {
+	return NamedErr("error", err)
+}
+
+// NamedErr 错误字段,err为nil时不输出
+func NamedErr(key string, err error) Field {
+	if err == nil {
+		return Field{Key: key, Type: SkipType}
+	}
+	return Field{Key: key, Type: ErrorType, Interface: err}
+}
+
+// Stringer 字段,编码时才调用value.String
+func Stringer(key string, value fmt.Stringer) Field {
+	return Field{Key: key, Type: StringerType, Interface: value}
+}
+
+// Any 根据值的类型创建字段,无法识别的类型使用反射编码
+func Any(key string, value interface{}) Field {
+	switch v := value.(type) {
+	case string:
+		return String(key, v)
+	case int:
+		return Int(key, v)
+	case int64:
+		return Int64(key, v)
+	case int32:
+		return Int64(key, int64(v))
+	case int16:
+		return Int64(key, int64(v))
+	case int8:
+		return Int64(key, int64(v))
+	case uint32:
+		return Int64(key, int64(v))
+	case uint16:
+		return Int64(key, int64(v))
+	case uint8:
+		return Int64(key, int64(v))
+	case float64:
+		return Float64(key, v)
+	case float32:
+		return Float64(key, float64(v))
+	case bool:
+		return Bool(key, v)
+	case time.Duration:
+		return Duration(key, v)
+	case time.Time:
+		return Time(key, v)
+	case error:
+		return NamedErr(key, v)
+	case fmt.Stringer:
+		return Stringer(key, v)
+	}
+	return Field{Key: key, Type: ReflectType, Interface: value}
+}
+
+// float 还原浮点数
+func (f Field) float() float64 {
+	return math.Float64frombits(uint64(f.Integer))
+}
+
+// time 还原时间
+func (f Field) time() time.Time {
+	if t, ok := f.Interface.(time.Time); ok {
+		return t
+	}
+	return time.Unix(0, f.Integer).In(f.Interface.(*time.Location))
+}
+
+// appendDuration 按 time.Duration.String 的格式追加时长,不分配内存
+func appendDuration(bs []byte, d time.Duration) []byte {
+	var buf [32]byte
+	w := len(buf)
+	u := uint64(d)
+	neg := d < 0
+	if neg {
+		u = -u
+	}
+	if u < uint64(time.Second) {
+		// 小于1秒时使用更小的单位,如"1.2ms"
+		var prec int
+		w--
+		buf[w] = 's'
+		w--
+		switch {
+		case u == 0:
+			return append(bs, "0s"...)
+		case u < uint64(time.Microsecond):
+			buf[w] = 'n'
+		case u < uint64(time.Millisecond):
+			prec = 3
+			w--
+			copy(buf[w:], "µ")
+		default:
+			prec = 6
+			buf[w] = 'm'
+		}
+		w, u = fmtFrac(buf[:w], u, prec)
+		w = fmtInt(buf[:w], u)
+	} else {
+		w--
+		buf[w] = 's'
+		w, u = fmtFrac(buf[:w], u, 9)
+		w = fmtInt(buf[:w], u%60)
+		u /= 60
+		if u > 0 {
+			w--
+			buf[w] = 'm'
+			w = fmtInt(buf[:w], u%60)
+			u /= 60
+			if u > 0 {
+				w--
+				buf[w] = 'h'
+				w = fmtInt(buf[:w], u)
+			}
+		}
+	}
+	if neg {
+		w--
+		buf[w] = '-'
+	}
+	return append(bs, buf[w:]...)
+}
+
+// fmtFrac 从buf尾部写入v的小数部分(去掉末尾的0),返回写入的起始位置与整数部分
+func fmtFrac(buf []byte, v uint64, prec int) (int, uint64) {
+	w := len(buf)
+	print := false
+	for i := 0; i < prec; i++ {
+		digit := v % 10
+		print = print || digit != 0
+		if print {
+			w--
+			buf[w] = byte(digit) + '0'
+		}
+		v /= 10
+	}
+	if print {
+		w--
+		buf[w] = '.'
+	}
+	return w, v
+}
+
+// fmtInt 从buf尾部写入v,返回写入的起始位置
+func fmtInt(buf []byte, v uint64) int {
+	w := len(buf)
+	if v == 0 {
+		w--
+		buf[w] = '0'
+		return w
+	}
+	for v > 0 {
+		w--
+		buf[w] = byte(v%10) + '0'
+		v /= 10
+	}
+	return w
+}
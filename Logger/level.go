@@ -0,0 +1,110 @@
+package Logger
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+)
+
+/****************************************************************
+ * @author: Ihc
+ * @date: 2026/10/19 20:30
+ * @description: 日志等级
+ ***************************************************************/
+
+// Level 日志等级,数值越大越重要
+type Level int8
+
+const (
+	// DebugLevel 调试信息,生产环境通常关闭
+	DebugLevel Level = iota
+	// InfoLevel 常规信息,默认等级
+	InfoLevel
+	// WarnLevel 需要关注但不影响运行的问题
+	WarnLevel
+	// ErrorLevel 错误
+	ErrorLevel
+	// FatalLevel 致命错误,记录后进程退出
+	FatalLevel
+)
+
+// String 等级的小写名称
+func (l Level) String() string {
+	switch l {
+	case DebugLevel:
+		return "debug"
+	case InfoLevel:
+		return "info"
+	case WarnLevel:
+		return "warn"
+	case ErrorLevel:
+		return "error"
+	case FatalLevel:
+		return "fatal"
+	}
+	return fmt.Sprintf("Level(%d)", l)
+}
+
+// CapitalString 等级的大写名称
+func (l Level) CapitalString() string {
+	switch l {
+	case DebugLevel:
+		return "DEBUG"
+	case InfoLevel:
+		return "INFO"
+	case WarnLevel:
+		return "WARN"
+	case ErrorLevel:
+		return "ERROR"
+	case FatalLevel:
+		return "FATAL"
+	}
+	return strings.ToUpper(l.String())
+}
+
+// Enabled 等级为l时是否记录level等级的日志
+func (l Level) Enabled(level Level) bool {
+	return level >= l
+}
+
+// ParseLevel 解析等级名称,不区分大小写
+func ParseLevel(text string) (Level, error) {
+	switch strings.ToLower(text) {
+	case "debug":
+		return DebugLevel, nil
+	case "info", "":
+		return InfoLevel, nil
+	case "warn", "warning":
+		return WarnLevel, nil
+	case "error":
+		return ErrorLevel, nil
+	case "fatal":
+		return FatalLevel, nil
+	}
+	return InfoLevel, fmt.Errorf("logger: unknown level %q", text)
+}
+
+// AtomicLevel 可以在运行时并发修改的等级
+type AtomicLevel struct {
+	level int32
+}
+
+// NewAtomicLevel 创建 AtomicLevel
+func NewAtomicLevel(level Level) *AtomicLevel {
+	return &AtomicLevel{level: int32(level)}
+}
+
+// Level 当前等级
+func (a *AtomicLevel) Level() Level {
+	return Level(atomic.LoadInt32(&a.level))
+}
+
+// SetLevel 修改等级,立即对所有使用该等级的Logger生效
+func (a *AtomicLevel) SetLevel(level Level) {
+	atomic.StoreInt32(&a.level, int32(level))
+}
+
+// Enabled 是否记录level等级的日志
+func (a *AtomicLevel) Enabled(level Level) bool {
+	return a.Level().Enabled(level)
+}
@@ -0,0 +1,215 @@
+package Logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+/****************************************************************
+ * @author: Ihc
+ * @date: 2026/10/20 01:10
+ * @description: 按名称分级的日志等级
+Logger.Named创建的Logger名称以"."分隔层级,如"db.pool";
+等级取名称自身或最近的祖先设置的等级,都没有设置时使用根等级:
+	levels.SetLevel("db", DebugLevel) // db与db.pool输出调试日志
+	levels.SetLevel("db.pool", WarnLevel) // db.pool只输出警告以上
+Logger缓存解析结果,只有设置或取消某个名称的等级时才重新解析,
+修改已设置名称的等级只是原子地修改 AtomicLevel
+Levels同时是http.Handler:
+	GET 返回根等级与全部设置: {"level":"info","levels":{"db":"debug"}}
+	GET ?name=db.pool 返回该名称的生效等级: {"name":"db.pool","level":"debug"}
+	PUT {"name":"db","level":"debug"} 设置等级,name为空表示根等级,level为空表示取消设置
+ ***************************************************************/
+
+// Levels 按名称分级的日志等级,并发安全
+type Levels struct {
+	root    *AtomicLevel
+	mu      sync.RWMutex
+	levels  map[string]*AtomicLevel // levels 设置了等级的名称
+	version uint64                  // version 每次设置或取消名称时加一,使Logger的缓存失效
+	caches  sync.Map                // caches 名称 -> *levelCache,同名的Logger共享
+}
+
+// newLevels 创建 Levels
+func newLevels(root Level) *Levels {
+	return &Levels{
+		root:    NewAtomicLevel(root),
+		levels:  make(map[string]*AtomicLevel),
+		version: 1,
+	}
+}
+
+// Root 根等级
+func (ls *Levels) Root() *AtomicLevel {
+	return ls.root
+}
+
+// SetLevel 设置名称的等级,name为空时设置根等级
+func (ls *Levels) SetLevel(name string, level Level) {
+	if name == "" {
+		ls.root.SetLevel(level)
+		return
+	}
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	if a, ok := ls.levels[name]; ok {
+		a.SetLevel(level)
+		return
+	}
+	ls.levels[name] = NewAtomicLevel(level)
+	atomic.AddUint64(&ls.version, 1)
+}
+
+// UnsetLevel 取消名称的等级设置,之后使用祖先的等级
+func (ls *Levels) UnsetLevel(name string) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	if _, ok := ls.levels[name]; ok {
+		delete(ls.levels, name)
+		atomic.AddUint64(&ls.version, 1)
+	}
+}
+
+// Level 名称的生效等级
+func (ls *Levels) Level(name string) Level {
+	ls.mu.RLock()
+	defer ls.mu.RUnlock()
+	return ls.resolve(name).Level()
+}
+
+// All 全部设置了等级的名称与其等级
+func (ls *Levels) All() map[string]Level {
+	ls.mu.RLock()
+	defer ls.mu.RUnlock()
+	all := make(map[string]Level, len(ls.levels))
+	for name, a := range ls.levels {
+		all[name] = a.Level()
+	}
+	return all
+}
+
+// resolve 返回名称自身或最近的祖先设置的等级,调用方持有读锁
+func (ls *Levels) resolve(name string) *AtomicLevel {
+	for name != "" {
+		if a, ok := ls.levels[name]; ok {
+			return a
+		}
+		i := strings.LastIndexByte(name, '.')
+		if i < 0 {
+			break
+		}
+		name = name[:i]
+	}
+	return ls.root
+}
+
+// resolvedLevel Logger缓存的解析结果
+type resolvedLevel struct {
+	version uint64
+	level   *AtomicLevel
+}
+
+// levelCache 同名Logger共享的解析缓存
+type levelCache struct {
+	v atomic.Value // v *resolvedLevel
+}
+
+// cache 返回名称的解析缓存,同名的Logger共享同一个缓存
+func (ls *Levels) cache(name string) *levelCache {
+	if c, ok := ls.caches.Load(name); ok {
+		return c.(*levelCache)
+	}
+	c, _ := ls.caches.LoadOrStore(name, &levelCache{})
+	return c.(*levelCache)
+}
+
+// load 返回名称的等级,缓存过期时重新解析
+func (ls *Levels) load(name string, cache *levelCache) *AtomicLevel {
+	version := atomic.LoadUint64(&ls.version)
+	if r, ok := cache.v.Load().(*resolvedLevel); ok && r.version == version {
+		return r.level
+	}
+	// 先读版本号再解析,解析期间发生的修改会使这次的缓存在下次调用时失效
+	ls.mu.RLock()
+	a := ls.resolve(name)
+	ls.mu.RUnlock()
+	cache.v.Store(&resolvedLevel{version: version, level: a})
+	return a
+}
+
+// levelRequest PUT请求体
+type levelRequest struct {
+	Name  string `json:"name"`
+	Level string `json:"level"`
+}
+
+// levelsResponse GET请求的响应
+type levelsResponse struct {
+	Level  string            `json:"level"`
+	Levels map[string]string `json:"levels"`
+}
+
+// nameResponse 带name参数的GET请求的响应
+type nameResponse struct {
+	Name  string `json:"name"`
+	Level string `json:"level"`
+}
+
+// ServeHTTP 查询与修改等级
+func (ls *Levels) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		if name := r.URL.Query().Get("name"); name != "" {
+			writeJSON(w, http.StatusOK, nameResponse{Name: name, Level: ls.Level(name).String()})
+			return
+		}
+		writeJSON(w, http.StatusOK, ls.snapshot())
+	case http.MethodPut:
+		var req levelRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("logger: invalid request body: %v", err))
+			return
+		}
+		if req.Level == "" {
+			if req.Name == "" {
+				writeError(w, http.StatusBadRequest, fmt.Errorf("logger: root level cannot be unset"))
+				return
+			}
+			ls.UnsetLevel(req.Name)
+		} else {
+			level, err := ParseLevel(req.Level)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, err)
+				return
+			}
+			ls.SetLevel(req.Name, level)
+		}
+		writeJSON(w, http.StatusOK, ls.snapshot())
+	default:
+		w.Header().Set("Allow", "GET, PUT")
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("logger: method %s not allowed", r.Method))
+	}
+}
+
+// snapshot 根等级与全部设置
+func (ls *Levels) snapshot() levelsResponse {
+	resp := levelsResponse{Level: ls.root.Level().String(), Levels: make(map[string]string)}
+	for name, level := range ls.All() {
+		resp.Levels[name] = level.String()
+	}
+	return resp
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,184 @@
+package Logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+/****************************************************************
+ * @author: Ihc
+ * @date: 2026/10/20 01:40
+ * @description:
+ ***************************************************************/
+
+func TestNamedLevels(t *testing.T) {
+	logger, buf := newTestLogger()
+	db := logger.Named("db")
+	pool := db.Named("pool").With(String("conn", "c1"))
+	cache := logger.Named("cache")
+	if pool.Name() != "db.pool" {
+		t.Fatalf("name %q", pool.Name())
+	}
+	levels := logger.Levels()
+
+	db.Debug("db debug")
+	levels.SetLevel("db", DebugLevel)
+	db.Debug("db debug")
+	pool.Debug("pool debug")
+	cache.Debug("cache debug")
+	levels.SetLevel("db.pool", WarnLevel)
+	pool.Info("pool info")
+	pool.Warn("pool warn")
+	levels.UnsetLevel("db.pool")
+	pool.Debug("pool debug again")
+	levels.SetLevel("", ErrorLevel)
+	logger.Warn("root warn")
+	cache.Error("cache error")
+
+	want := []string{
+		"DEBUG [db] db debug",
+		"DEBUG [db.pool] pool debug conn=c1",
+		"WARN [db.pool] pool warn conn=c1",
+		"DEBUG [db.pool] pool debug again conn=c1",
+		"ERROR [cache] cache error",
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != len(want) {
+		t.Fatalf("got %q", buf.String())
+	}
+	for i, line := range lines {
+		if !strings.HasSuffix(line, want[i]) {
+			t.Fatalf("line %d: got %q, want suffix %q", i, line, want[i])
+		}
+	}
+	if levels.Level("db.pool.idle") != DebugLevel || levels.Level("dbx") != ErrorLevel {
+		t.Fatal("resolve error")
+	}
+	// 同名的Logger共享解析缓存
+	if logger.Named("db").Named("pool").cache != pool.cache || db.cache == pool.cache {
+		t.Fatal("loggers with the same name must share the level cache")
+	}
+}
+
+func TestNamedEncoders(t *testing.T) {
+	entry := &Entry{Time: fixedTime, Level: InfoLevel, Name: "db.pool", Message: "m"}
+	if got := string(NewLogfmtEncoder().Encode(nil, entry)); got != "time=2026-10-19T20:50:00.000Z level=info logger=db.pool msg=m\n" {
+		t.Fatalf("logfmt %q", got)
+	}
+	if got := string(NewJSONEncoder().Encode(nil, entry)); got != `{"time":"2026-10-19T20:50:00.000Z","level":"info","logger":"db.pool","msg":"m"}`+"\n" {
+		t.Fatalf("json %q", got)
+	}
+}
+
+func TestAtomicLevel(t *testing.T) {
+	logger, _ := newTestLogger()
+	root := logger.Levels().Root()
+	if logger.Enabled(DebugLevel) {
+		t.Fatal("debug enabled by default")
+	}
+	root.SetLevel(DebugLevel)
+	if !logger.Enabled(DebugLevel) || !logger.Named("x").Enabled(DebugLevel) {
+		t.Fatal("SetLevel not visible")
+	}
+}
+
+func TestLevelsConcurrent(t *testing.T) {
+	logger, _ := newTestLogger()
+	levels := logger.Levels()
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			named := logger.Named("a").Named("b")
+			for j := 0; j < 1000; j++ {
+				named.Debug("tick")
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				levels.SetLevel("a", DebugLevel)
+				levels.UnsetLevel("a")
+			}
+		}()
+	}
+	wg.Wait()
+	if logger.Named("a").Named("b").Enabled(DebugLevel) {
+		t.Fatal("stale level after unset")
+	}
+}
+
+func doLevels(t *testing.T, handler http.Handler, method, target, body string) (int, map[string]interface{}) {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	var resp map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid response %q: %v", rec.Body.String(), err)
+	}
+	return rec.Code, resp
+}
+
+func TestLevelsHandler(t *testing.T) {
+	logger, buf := newTestLogger()
+	handler := logger.Levels()
+	db := logger.Named("db")
+
+	code, resp := doLevels(t, handler, http.MethodGet, "/log/level", "")
+	if code != http.StatusOK || resp["level"] != "info" || len(resp["levels"].(map[string]interface{})) != 0 {
+		t.Fatalf("GET %d %v", code, resp)
+	}
+	code, resp = doLevels(t, handler, http.MethodPut, "/log/level", `{"name":"db","level":"DEBUG"}`)
+	if code != http.StatusOK || resp["levels"].(map[string]interface{})["db"] != "debug" {
+		t.Fatalf("PUT %d %v", code, resp)
+	}
+	db.Named("pool").Debug("visible")
+	if !strings.Contains(buf.String(), "DEBUG [db.pool] visible") {
+		t.Fatalf("output %q", buf.String())
+	}
+	code, resp = doLevels(t, handler, http.MethodGet, "/log/level?name=db.pool", "")
+	if code != http.StatusOK || resp["name"] != "db.pool" || resp["level"] != "debug" {
+		t.Fatalf("GET name %d %v", code, resp)
+	}
+	code, resp = doLevels(t, handler, http.MethodPut, "/log/level", `{"name":"db"}`)
+	if code != http.StatusOK || len(resp["levels"].(map[string]interface{})) != 0 || db.Enabled(DebugLevel) {
+		t.Fatalf("unset %d %v", code, resp)
+	}
+	code, resp = doLevels(t, handler, http.MethodPut, "/log/level", `{"level":"warn"}`)
+	if code != http.StatusOK || resp["level"] != "warn" || logger.Enabled(InfoLevel) {
+		t.Fatalf("root %d %v", code, resp)
+	}
+
+	for _, body := range []string{`{"name":"db","level":"loud"}`, `{"name":""}`, `not json`} {
+		if code, resp = doLevels(t, handler, http.MethodPut, "/log/level", body); code != http.StatusBadRequest || resp["error"] == nil {
+			t.Fatalf("PUT %s: %d %v", body, code, resp)
+		}
+	}
+	if code, _ = doLevels(t, handler, http.MethodPost, "/log/level", ""); code != http.StatusMethodNotAllowed {
+		t.Fatalf("POST %d", code)
+	}
+}
+
+func TestLevelsServer(t *testing.T) {
+	logger, _ := newTestLogger()
+	server := httptest.NewServer(logger.Levels())
+	defer server.Close()
+	req, err := http.NewRequest(http.MethodPut, server.URL, bytes.NewBufferString(`{"name":"rpc","level":"error"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || logger.Named("rpc").Enabled(WarnLevel) {
+		t.Fatalf("status %d", resp.StatusCode)
+	}
+}
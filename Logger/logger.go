@@ -1,7 +1,383 @@
 package Logger
 
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
 /****************************************************************
  * @author: Ihc
  * @date: 2022/4/19 23:02
  * @description: 日志模块
+	1.日志等级: Debug/Info/Warn/Error/Fatal
+	2.并发控制: 同一个Logger及其子Logger共享一把锁,每条日志一次写入
+	3.输出对象控制: Writer(标准输出,文件,多路输出)
+	4.接口: With携带上下文字段创建子Logger
+	5.编码: Encoder(console,logfmt,json),关闭的等级与常用字段的日志不分配内存
+	6.采样: Sampler(按消息采样,按调用位置或键限流),输出被丢弃日志的汇总
+	7.等级控制: Named创建分层命名的Logger,Levels按名称设置等级,可以通过HTTP在运行时修改
+	8.上下文: 调用位置,调用栈,XxxCtx方法从context.Context提取字段
  ***************************************************************/
+
+// Option 用于设置Logger的初始化选项
+type Option func(options *Options)
+
+// Options Logger初始化选项
+type Options struct {
+	level    Level            // level 根等级
+	writer   Writer           // writer 输出对象
+	encoder  Encoder          // encoder 日志编码器
+	nowFunc  func() time.Time // nowFunc 日志时间的来源
+	exitFunc func(code int)   // exitFunc Fatal日志记录后调用
+
+	sampler         Sampler       // sampler 采样器,为nil时记录全部日志
+	summaryInterval time.Duration // summaryInterval 两次丢弃汇总之间的最短间隔
+
+	caller     bool               // caller 是否记录调用位置
+	callerSkip int                // callerSkip 确定调用位置时额外跳过的层数
+	stacktrace bool               // stacktrace 是否记录调用栈
+	stackLevel Level              // stackLevel 记录调用栈的最低等级
+	extractors []ContextExtractor // extractors XxxCtx方法使用的字段提取器
+}
+
+// WithLevel 设置根等级,默认为 InfoLevel
+func WithLevel(level Level) Option {
+	return func(options *Options) {
+		options.level = level
+	}
+}
+
+// WithWriter 设置输出对象,默认为标准输出
+func WithWriter(writer Writer) Option {
+	return func(options *Options) {
+		options.writer = writer
+	}
+}
+
+// WithEncoder 设置日志编码器,默认为 NewConsoleEncoder
+func WithEncoder(encoder Encoder) Option {
+	return func(options *Options) {
+		options.encoder = encoder
+	}
+}
+
+// WithNowFunc 设置日志时间的来源
+func WithNowFunc(nowFunc func() time.Time) Option {
+	return func(options *Options) {
+		options.nowFunc = nowFunc
+	}
+}
+
+// WithExitFunc 设置Fatal日志记录后调用的退出函数,默认为os.Exit
+func WithExitFunc(exitFunc func(code int)) Option {
+	return func(options *Options) {
+		options.exitFunc = exitFunc
+	}
+}
+
+// WithSampler 设置采样器
+// 被丢弃的日志按消息计数,距上次汇总超过summaryInterval后,在下一条记录的日志之前输出汇总
+func WithSampler(sampler Sampler, summaryInterval time.Duration) Option {
+	return func(options *Options) {
+		options.sampler = sampler
+		options.summaryInterval = summaryInterval
+	}
+}
+
+// WithCaller 设置是否记录调用位置
+func WithCaller(caller bool) Option {
+	return func(options *Options) {
+		options.caller = caller
+	}
+}
+
+// WithCallerSkip 设置确定调用位置时额外跳过的层数,用于包装Logger的函数
+func WithCallerSkip(skip int) Option {
+	return func(options *Options) {
+		options.callerSkip = skip
+	}
+}
+
+// WithStacktrace 设置level及以上等级的日志附带调用栈
+func WithStacktrace(level Level) Option {
+	return func(options *Options) {
+		options.stacktrace = true
+		options.stackLevel = level
+	}
+}
+
+// WithContextExtractor 注册XxxCtx方法使用的字段提取器,按注册顺序追加字段
+func WithContextExtractor(extractors ...ContextExtractor) Option {
+	return func(options *Options) {
+		options.extractors = append(options.extractors, extractors...)
+	}
+}
+
+// core Logger树共享的状态
+type core struct {
+	mu       sync.Mutex // mu 串行化写入
+	options  Options
+	levels   *Levels   // levels 按名称分级的等级
+	sampling *sampling // sampling 采样状态,未设置采样器时为nil
+}
+
+// write 写入一条编码好的日志
+func (c *core) write(p []byte) error {
+	c.mu.Lock()
+	_, err := c.options.writer.Write(p)
+	c.mu.Unlock()
+	return err
+}
+
+// sync 刷新输出
+func (c *core) sync() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.options.writer.Sync()
+}
+
+// Logger 日志记录器,并发安全
+type Logger struct {
+	core   *core       // core 与父Logger共享
+	name   string      // name 以"."分隔层级的名称,根Logger为空
+	cache  *levelCache // cache 名称等级的解析缓存,同名的Logger共享
+	fields []Field     // fields 上下文字段,每条日志都会携带
+}
+
+// NewLogger 创建Logger
+func NewLogger(opts ...Option) *Logger {
+	options := Options{
+		level:    InfoLevel,
+		writer:   Stdout(),
+		encoder:  NewConsoleEncoder(),
+		nowFunc:  time.Now,
+		exitFunc: os.Exit,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	c := &core{options: options, levels: newLevels(options.level)}
+	if options.sampler != nil {
+		c.sampling = newSampling(options.sampler, options.summaryInterval, options.nowFunc())
+	}
+	return &Logger{core: c, cache: c.levels.cache("")}
+}
+
+// Named 创建名为"父名称.name"的子Logger,子Logger的等级由 Levels 按名称决定
+func (l *Logger) Named(name string) *Logger {
+	if name == "" {
+		return l
+	}
+	if l.name != "" {
+		name = l.name + "." + name
+	}
+	return &Logger{core: l.core, name: name, cache: l.core.levels.cache(name), fields: l.fields}
+}
+
+// Name Logger的名称
+func (l *Logger) Name() string {
+	return l.name
+}
+
+// Levels 返回Logger树共享的 Levels,用于在运行时修改等级
+func (l *Logger) Levels() *Levels {
+	return l.core.levels
+}
+
+// With 创建携带上下文字段的子Logger,子Logger与父Logger共享输出,名称与等级
+func (l *Logger) With(fields ...Field) *Logger {
+	if len(fields) == 0 {
+		return l
+	}
+	child := &Logger{core: l.core, name: l.name, cache: l.cache, fields: make([]Field, 0, len(l.fields)+len(fields))}
+	child.fields = append(child.fields, l.fields...)
+	child.fields = append(child.fields, fields...)
+	return child
+}
+
+// Enabled 是否记录level等级的日志
+func (l *Logger) Enabled(level Level) bool {
+	return l.core.levels.load(l.name, l.cache).Enabled(level)
+}
+
+// Debug 记录调试日志
+func (l *Logger) Debug(msg string, fields ...Field) {
+	l.log(nil, DebugLevel, msg, fields)
+}
+
+// DebugCtx 记录调试日志,附带从ctx中提取的字段
+func (l *Logger) DebugCtx(ctx context.Context, msg string, fields ...Field) {
+	l.log(ctx, DebugLevel, msg, fields)
+}
+
+// Info 记录常规日志
+func (l *Logger) Info(msg string, fields ...Field) {
+	l.log(nil, InfoLevel, msg, fields)
+}
+
+// InfoCtx 记录常规日志,附带从ctx中提取的字段
+func (l *Logger) InfoCtx(ctx context.Context, msg string, fields ...Field) {
+	l.log(ctx, InfoLevel, msg, fields)
+}
+
+// Warn 记录警告日志
+func (l *Logger) Warn(msg string, fields ...Field) {
+	l.log(nil, WarnLevel, msg, fields)
+}
+
+// WarnCtx 记录警告日志,附带从ctx中提取的字段
+func (l *Logger) WarnCtx(ctx context.Context, msg string, fields ...Field) {
+	l.log(ctx, WarnLevel, msg, fields)
+}
+
+// Error 记录错误日志
+func (l *Logger) Error(msg string, fields ...Field) {
+	l.log(nil, ErrorLevel, msg, fields)
+}
+
+// ErrorCtx 记录错误日志,附带从ctx中提取的字段
+func (l *Logger) ErrorCtx(ctx context.Context, msg string, fields ...Field) {
+	l.log(ctx, ErrorLevel, msg, fields)
+}
+
+// Fatal 记录致命错误日志,刷新输出后退出进程
+func (l *Logger) Fatal(msg string, fields ...Field) {
+	l.log(nil, FatalLevel, msg, fields)
+}
+
+// FatalCtx 记录致命错误日志,附带从ctx中提取的字段,刷新输出后退出进程
+func (l *Logger) FatalCtx(ctx context.Context, msg string, fields ...Field) {
+	l.log(ctx, FatalLevel, msg, fields)
+}
+
+// Sync 输出尚未汇总的丢弃计数并刷新输出
+func (l *Logger) Sync() error {
+	if l.core.sampling != nil {
+		l.summarize(l.core.options.nowFunc())
+	}
+	return l.core.sync()
+}
+
+// log 编码并写入一条日志,ctx为nil时不提取字段
+// fields只被复制到池化的 Entry 中,不会逃逸,调用方的可变参数切片可以分配在栈上
+// 必须由导出的日志方法直接调用,调用位置按此层数计算
+func (l *Logger) log(ctx context.Context, level Level, msg string, fields []Field) {
+	if !l.Enabled(level) {
+		return
+	}
+	options := &l.core.options
+	entry := getEntry()
+	entry.Time = options.nowFunc()
+	entry.Level = level
+	entry.Message = msg
+	entry.Name = l.name
+	entry.Fields = append(entry.Fields, l.fields...)
+	if ctx != nil {
+		for _, extract := range options.extractors {
+			entry.Fields = extract(ctx, entry.Fields)
+		}
+	}
+	entry.Fields = append(entry.Fields, fields...)
+	s := l.core.sampling
+	if options.caller || (s != nil && s.needCaller) {
+		entry.PC = callerPC(2 + options.callerSkip)
+	}
+	if s != nil && level < FatalLevel {
+		if !s.sampler.Sample(entry) {
+			s.suppress(msg)
+			putEntry(entry)
+			return
+		}
+		if s.due(entry.Time) {
+			l.summarize(entry.Time)
+		}
+	}
+	if options.caller {
+		entry.Caller = newCaller(entry.PC)
+	}
+	if options.stacktrace && level >= options.stackLevel {
+		entry.Stack = stacktrace(2 + options.callerSkip)
+	}
+	l.write(entry)
+	if level == FatalLevel {
+		_ = l.Sync()
+		l.core.options.exitFunc(1)
+	}
+}
+
+// write 编码并写入entry,之后将entry放回池中
+func (l *Logger) write(entry *Entry) {
+	buf := getBuffer()
+	buf.bs = l.core.options.encoder.Encode(buf.bs, entry)
+	putEntry(entry)
+	if err := l.core.write(buf.bs); err != nil {
+		fmt.Fprintf(os.Stderr, "logger: write failed: %v\n", err)
+	}
+	putBuffer(buf)
+}
+
+// summarize 为每个有丢弃的消息输出一条汇总日志
+func (l *Logger) summarize(now time.Time) {
+	for _, c := range l.core.sampling.take(now) {
+		entry := getEntry()
+		entry.Time = now
+		entry.Level = WarnLevel
+		entry.Message = "logger suppressed records"
+		entry.Fields = append(entry.Fields, String("message", c.message), Int64("suppressed", c.count))
+		l.write(entry)
+	}
+}
+
+// buffer 编码缓冲区
+type buffer struct {
+	bs []byte
+}
+
+// maxPooledBuffer 超过此容量的缓冲区不放回池中,避免偶发的大日志长期占用内存
+const maxPooledBuffer = 64 << 10
+
+var bufferPool = sync.Pool{
+	New: func() interface{} {
+		return &buffer{bs: make([]byte, 0, 1024)}
+	},
+}
+
+func getBuffer() *buffer {
+	buf := bufferPool.Get().(*buffer)
+	buf.bs = buf.bs[:0]
+	return buf
+}
+
+func putBuffer(buf *buffer) {
+	if cap(buf.bs) > maxPooledBuffer {
+		return
+	}
+	bufferPool.Put(buf)
+}
+
+var entryPool = sync.Pool{
+	New: func() interface{} {
+		return &Entry{Fields: make([]Field, 0, 16)}
+	},
+}
+
+func getEntry() *Entry {
+	return entryPool.Get().(*Entry)
+}
+
+// putEntry 清空字段引用后放回池中
+func putEntry(entry *Entry) {
+	for i := range entry.Fields {
+		entry.Fields[i] = Field{}
+	}
+	entry.Fields = entry.Fields[:0]
+	entry.Message = ""
+	entry.Name = ""
+	entry.PC = 0
+	entry.Caller = Caller{}
+	entry.Stack = ""
+	entryPool.Put(entry)
+}
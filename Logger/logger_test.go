@@ -0,0 +1,135 @@
+package Logger
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+/****************************************************************
+ * @author: Ihc
+ * @date: 2026/10/19 20:50
+ * @description:
+ ***************************************************************/
+
+var fixedTime = time.Date(2026, 10, 19, 20, 50, 0, 0, time.UTC)
+
+func newTestLogger(opts ...Option) (*Logger, *bytes.Buffer) {
+	buf := &bytes.Buffer{}
+	opts = append([]Option{
+		WithWriter(AddSync(buf)),
+		WithNowFunc(func() time.Time { return fixedTime }),
+	}, opts...)
+	return NewLogger(opts...), buf
+}
+
+func TestLoggerFormat(t *testing.T) {
+	logger, buf := newTestLogger()
+	logger.Info("user login", Any("user", "alice"), Any("id", 42), Any("remote", "10.0.0.1:80 x"), Any("err", errors.New("bad")))
+	want := `2026-10-19T20:50:00.000Z INFO user login user=alice id=42 remote="10.0.0.1:80 x" err=bad` + "\n"
+	if buf.String() != want {
+		t.Fatalf("got %q, want %q", buf.String(), want)
+	}
+}
+
+func TestLoggerLevel(t *testing.T) {
+	logger, buf := newTestLogger(WithLevel(WarnLevel))
+	logger.Debug("debug")
+	logger.Info("info")
+	logger.Warn("warn")
+	logger.Error("error")
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], " WARN warn") || !strings.Contains(lines[1], " ERROR error") {
+		t.Fatalf("unexpected output %q", buf.String())
+	}
+	if logger.Enabled(InfoLevel) || !logger.Enabled(ErrorLevel) {
+		t.Fatal("Enabled error")
+	}
+}
+
+func TestLoggerWith(t *testing.T) {
+	logger, buf := newTestLogger()
+	child := logger.With(Any("request", "r1"))
+	grandchild := child.With(Any("step", 2))
+	grandchild.Info("done", Any("ok", true))
+	logger.Info("root")
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if !strings.HasSuffix(lines[0], "INFO done request=r1 step=2 ok=true") {
+		t.Fatalf("unexpected child output %q", lines[0])
+	}
+	if !strings.HasSuffix(lines[1], "INFO root") {
+		t.Fatalf("parent got child fields %q", lines[1])
+	}
+}
+
+func TestLoggerFatal(t *testing.T) {
+	var code = -1
+	logger, buf := newTestLogger(WithExitFunc(func(c int) { code = c }))
+	logger.Fatal("boom")
+	if code != 1 || !strings.Contains(buf.String(), "FATAL boom") {
+		t.Fatalf("code %d, output %q", code, buf.String())
+	}
+}
+
+func TestLoggerConcurrent(t *testing.T) {
+	logger, buf := newTestLogger()
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			child := logger.With(Any("worker", i))
+			for j := 0; j < 100; j++ {
+				child.Info("tick", Any("n", j))
+			}
+		}(i)
+	}
+	wg.Wait()
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 800 {
+		t.Fatalf("got %d lines", len(lines))
+	}
+	for _, line := range lines {
+		if !strings.HasPrefix(line, "2026-10-19T20:50:00.000Z INFO tick worker=") {
+			t.Fatalf("interleaved line %q", line)
+		}
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	for _, level := range []Level{DebugLevel, InfoLevel, WarnLevel, ErrorLevel, FatalLevel} {
+		parsed, err := ParseLevel(strings.ToUpper(level.String()))
+		if err != nil || parsed != level {
+			t.Fatalf("ParseLevel(%s) = %v, %v", level, parsed, err)
+		}
+	}
+	if _, err := ParseLevel("verbose"); err == nil {
+		t.Fatal("expect error")
+	}
+}
+
+func TestFileAndMultiWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "app.log")
+	file, err := NewFileWriter(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := &bytes.Buffer{}
+	logger := NewLogger(WithWriter(MultiWriter(file, AddSync(buf))))
+	logger.Info("hello")
+	if err := logger.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != buf.String() || !strings.Contains(buf.String(), "INFO hello") {
+		t.Fatalf("file %q, buffer %q", data, buf.String())
+	}
+}
@@ -0,0 +1,7 @@
+//go:build race
+// +build race
+
+package Logger
+
+// raceEnabled 竞态检测下sync.Pool会随机丢弃对象,内存分配的断言不再成立
+const raceEnabled = true
@@ -0,0 +1,484 @@
+package Logger
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+/****************************************************************
+ * @author: Ihc
+ * @date: 2026/10/19 22:10
+ * @description: 滚动日志文件
+	1.按大小和/或时间(每小时,每天)滚动,当前文件重命名为 name-2006-01-02T15-04-05.000.ext
+	2.保留最多N个备份,删除超过最长保留时间的备份
+	3.后台goroutine压缩备份为.gz,不阻塞写入
+	4.收到SIGHUP时重新打开文件,配合外部logrotate的move方式使用
+所有方法并发安全
+ ***************************************************************/
+
+// ErrClosed 输出对象已关闭
+var ErrClosed = errors.New("logger: writer closed")
+
+// backupTimeFormat 备份文件名中的时间格式
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+// RotateInterval 按时间滚动的周期
+type RotateInterval int
+
+const (
+	// RotateNever 不按时间滚动
+	RotateNever RotateInterval = iota
+	// RotateHourly 每小时滚动
+	RotateHourly
+	// RotateDaily 每天滚动
+	RotateDaily
+)
+
+// periodStart t所在周期的起点,RotateNever 时返回零值
+func (i RotateInterval) periodStart(t time.Time) time.Time {
+	switch i {
+	case RotateHourly:
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, t.Location())
+	case RotateDaily:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	}
+	return time.Time{}
+}
+
+// RotateOption 用于设置滚动文件的初始化选项
+type RotateOption func(options *RotateOptions)
+
+// RotateOptions 滚动文件初始化选项
+type RotateOptions struct {
+	maxSize    int64            // maxSize 单个文件的最大字节数,0表示不按大小滚动
+	interval   RotateInterval   // interval 按时间滚动的周期
+	maxBackups int              // maxBackups 最多保留的备份数,0表示不限制
+	maxAge     time.Duration    // maxAge 备份的最长保留时间,0表示不限制
+	compress   bool             // compress 是否压缩备份
+	signals    []os.Signal      // signals 收到这些信号时重新打开文件
+	nowFunc    func() time.Time // nowFunc 时间来源,决定滚动时机与备份文件名
+}
+
+// WithMaxSize 设置单个文件的最大字节数
+func WithMaxSize(maxSize int64) RotateOption {
+	return func(options *RotateOptions) {
+		options.maxSize = maxSize
+	}
+}
+
+// WithRotateInterval 设置按时间滚动的周期
+func WithRotateInterval(interval RotateInterval) RotateOption {
+	return func(options *RotateOptions) {
+		options.interval = interval
+	}
+}
+
+// WithMaxBackups 设置最多保留的备份数
+func WithMaxBackups(maxBackups int) RotateOption {
+	return func(options *RotateOptions) {
+		options.maxBackups = maxBackups
+	}
+}
+
+// WithMaxAge 设置备份的最长保留时间,以备份文件名中的时间计算
+func WithMaxAge(maxAge time.Duration) RotateOption {
+	return func(options *RotateOptions) {
+		options.maxAge = maxAge
+	}
+}
+
+// WithCompress 设置是否以gzip压缩备份
+func WithCompress(compress bool) RotateOption {
+	return func(options *RotateOptions) {
+		options.compress = compress
+	}
+}
+
+// WithReopenSignals 设置触发重新打开文件的信号,默认为SIGHUP,不传入信号时不监听
+func WithReopenSignals(signals ...os.Signal) RotateOption {
+	return func(options *RotateOptions) {
+		options.signals = signals
+	}
+}
+
+// WithRotateNowFunc 设置时间来源
+func WithRotateNowFunc(nowFunc func() time.Time) RotateOption {
+	return func(options *RotateOptions) {
+		options.nowFunc = nowFunc
+	}
+}
+
+// RotatingFileWriter 滚动日志文件,实现 Writer
+type RotatingFileWriter struct {
+	mu      sync.Mutex
+	path    string
+	options RotateOptions
+	file    *os.File
+	size    int64     // size 当前文件的字节数
+	period  time.Time // period 当前文件所属周期的起点
+	closed  bool
+
+	millC   chan struct{}  // millC 通知后台goroutine压缩与清理备份
+	signalC chan os.Signal // signalC 重新打开文件的信号
+	done    chan struct{}
+	wg      sync.WaitGroup
+}
+
+// NewRotatingFileWriter 创建滚动日志文件,目录不存在时创建
+func NewRotatingFileWriter(path string, opts ...RotateOption) (*RotatingFileWriter, error) {
+	options := RotateOptions{
+		signals: []os.Signal{syscall.SIGHUP},
+		nowFunc: time.Now,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	w := &RotatingFileWriter{
+		path:    path,
+		options: options,
+		millC:   make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	w.wg.Add(1)
+	go w.millLoop()
+	if len(options.signals) > 0 {
+		w.signalC = make(chan os.Signal, 1)
+		signal.Notify(w.signalC, options.signals...)
+		w.wg.Add(1)
+		go w.signalLoop()
+	}
+	// 清理上次运行遗留的备份
+	w.notifyMill()
+	return w, nil
+}
+
+// open 打开当前文件,已有内容的周期以文件修改时间计算
+func (w *RotatingFileWriter) open() error {
+	if err := os.MkdirAll(filepath.Dir(w.path), 0755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(w.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+	w.file = file
+	w.size = info.Size()
+	w.period = w.options.interval.periodStart(w.options.nowFunc())
+	if w.size > 0 {
+		w.period = w.options.interval.periodStart(info.ModTime().In(w.period.Location()))
+	}
+	return nil
+}
+
+// Write 写入数据,需要时先滚动
+// 单次写入超过最大字节数时写入一个新文件,不拆分
+func (w *RotatingFileWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return 0, ErrClosed
+	}
+	if w.file == nil {
+		if err := w.open(); err != nil {
+			return 0, err
+		}
+	}
+	if w.shouldRotate(len(p)) {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// shouldRotate 写入n字节前是否需要滚动
+func (w *RotatingFileWriter) shouldRotate(n int) bool {
+	if w.size == 0 {
+		return false
+	}
+	if w.options.maxSize > 0 && w.size+int64(n) > w.options.maxSize {
+		return true
+	}
+	return w.options.interval != RotateNever &&
+		!w.options.interval.periodStart(w.options.nowFunc()).Equal(w.period)
+}
+
+// Rotate 立即滚动
+func (w *RotatingFileWriter) Rotate() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return ErrClosed
+	}
+	return w.rotate()
+}
+
+// rotate 关闭当前文件,重命名为备份后打开新文件
+func (w *RotatingFileWriter) rotate() error {
+	if w.file != nil {
+		if err := w.file.Close(); err != nil {
+			return err
+		}
+		w.file = nil
+	}
+	if _, err := os.Stat(w.path); err == nil {
+		if err := os.Rename(w.path, w.backupName(w.options.nowFunc())); err != nil {
+			return err
+		}
+	}
+	if err := w.open(); err != nil {
+		return err
+	}
+	w.notifyMill()
+	return nil
+}
+
+// backupName 备份文件名,与已有备份重名时时间后移1毫秒
+func (w *RotatingFileWriter) backupName(t time.Time) string {
+	dir, prefix, ext := w.nameParts()
+	for {
+		name := filepath.Join(dir, prefix+t.Format(backupTimeFormat)+ext)
+		if !exists(name) && !exists(name+".gz") {
+			return name
+		}
+		t = t.Add(time.Millisecond)
+	}
+}
+
+// nameParts 备份文件名的目录,前缀与扩展名
+func (w *RotatingFileWriter) nameParts() (dir, prefix, ext string) {
+	base := filepath.Base(w.path)
+	ext = filepath.Ext(base)
+	return filepath.Dir(w.path), strings.TrimSuffix(base, ext) + "-", ext
+}
+
+// Reopen 关闭并重新打开文件,用于外部程序移走文件之后
+func (w *RotatingFileWriter) Reopen() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return ErrClosed
+	}
+	if w.file != nil {
+		_ = w.file.Close()
+		w.file = nil
+	}
+	return w.open()
+}
+
+// Sync 将文件内容刷到存储
+func (w *RotatingFileWriter) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return nil
+	}
+	return w.file.Sync()
+}
+
+// Close 关闭文件,等待后台的压缩与清理完成
+func (w *RotatingFileWriter) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	var err error
+	if w.file != nil {
+		err = w.file.Close()
+		w.file = nil
+	}
+	w.mu.Unlock()
+	if w.signalC != nil {
+		signal.Stop(w.signalC)
+	}
+	close(w.done)
+	w.wg.Wait()
+	return err
+}
+
+// signalLoop 收到信号时重新打开文件
+func (w *RotatingFileWriter) signalLoop() {
+	defer w.wg.Done()
+	for {
+		select {
+		case <-w.signalC:
+			if err := w.Reopen(); err != nil && err != ErrClosed {
+				fmt.Fprintf(os.Stderr, "logger: reopen %s failed: %v\n", w.path, err)
+			}
+		case <-w.done:
+			return
+		}
+	}
+}
+
+// notifyMill 通知后台goroutine,已有未处理的通知时忽略
+func (w *RotatingFileWriter) notifyMill() {
+	select {
+	case w.millC <- struct{}{}:
+	default:
+	}
+}
+
+// millLoop 后台压缩与清理备份,关闭前处理完未处理的通知
+func (w *RotatingFileWriter) millLoop() {
+	defer w.wg.Done()
+	for {
+		select {
+		case <-w.millC:
+			w.mill()
+		case <-w.done:
+			select {
+			case <-w.millC:
+				w.mill()
+			default:
+			}
+			return
+		}
+	}
+}
+
+// backup 备份文件
+type backup struct {
+	path       string
+	time       time.Time
+	compressed bool
+}
+
+// mill 压缩未压缩的备份,删除多余与过期的备份
+func (w *RotatingFileWriter) mill() {
+	if w.options.compress {
+		backups, err := w.backups()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "logger: list backups of %s failed: %v\n", w.path, err)
+			return
+		}
+		for _, b := range backups {
+			if b.compressed {
+				continue
+			}
+			if err := compressFile(b.path); err != nil {
+				fmt.Fprintf(os.Stderr, "logger: compress %s failed: %v\n", b.path, err)
+			}
+		}
+	}
+	if w.options.maxBackups <= 0 && w.options.maxAge <= 0 {
+		return
+	}
+	backups, err := w.backups()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "logger: list backups of %s failed: %v\n", w.path, err)
+		return
+	}
+	cutoff := w.options.nowFunc().Add(-w.options.maxAge)
+	for i, b := range backups {
+		if (w.options.maxBackups > 0 && i >= w.options.maxBackups) ||
+			(w.options.maxAge > 0 && b.time.Before(cutoff)) {
+			if err := os.Remove(b.path); err != nil && !os.IsNotExist(err) {
+				fmt.Fprintf(os.Stderr, "logger: remove %s failed: %v\n", b.path, err)
+			}
+		}
+	}
+}
+
+// backups 列出全部备份,按时间从新到旧排序
+func (w *RotatingFileWriter) backups() ([]backup, error) {
+	dir, prefix, ext := w.nameParts()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	loc := w.options.nowFunc().Location()
+	var backups []backup
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		name := entry.Name()
+		trimmed := strings.TrimSuffix(name, ".gz")
+		if !strings.HasPrefix(trimmed, prefix) || !strings.HasSuffix(trimmed, ext) ||
+			len(trimmed) < len(prefix)+len(ext) {
+			continue
+		}
+		t, err := time.ParseInLocation(backupTimeFormat, trimmed[len(prefix):len(trimmed)-len(ext)], loc)
+		if err != nil {
+			continue
+		}
+		backups = append(backups, backup{
+			path:       filepath.Join(dir, name),
+			time:       t,
+			compressed: len(trimmed) < len(name),
+		})
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].time.After(backups[j].time)
+	})
+	return backups, nil
+}
+
+// compressFile 将src压缩为src.gz后删除src
+// 先写入临时文件再重命名,中途失败不会留下不完整的.gz
+func compressFile(src string) (err error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	tmp := src + ".gz.tmp"
+	out, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode())
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = out.Close()
+			_ = os.Remove(tmp)
+		}
+	}()
+	gz := gzip.NewWriter(out)
+	if _, err = io.Copy(gz, in); err != nil {
+		return err
+	}
+	if err = gz.Close(); err != nil {
+		return err
+	}
+	if err = out.Close(); err != nil {
+		return err
+	}
+	if err = os.Rename(tmp, src+".gz"); err != nil {
+		return err
+	}
+	_ = in.Close()
+	return os.Remove(src)
+}
+
+// exists 文件是否存在
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,275 @@
+package Logger
+
+import (
+	"bufio"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+/****************************************************************
+ * @author: Ihc
+ * @date: 2026/10/19 22:40
+ * @description:
+ ***************************************************************/
+
+// fakeClock 可以手动推进的时钟
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: fixedTime}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	c.mu.Unlock()
+}
+
+// listDir 目录下的文件名,已排序
+func listDir(t *testing.T, dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+// readLog 读取日志文件,.gz文件先解压
+func readLog(t *testing.T, path string) string {
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	var r io.Reader = file
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r = gz
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestRotateBySize(t *testing.T) {
+	dir := t.TempDir()
+	clock := newFakeClock()
+	w, err := NewRotatingFileWriter(filepath.Join(dir, "app.log"),
+		WithMaxSize(10), WithMaxBackups(2), WithRotateNowFunc(clock.Now), WithReopenSignals())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{"aaaaaaaa\n", "bbbbbbbb\n", "cccccccc\n", "dddddddd\n"} {
+		if _, err := w.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+		clock.Advance(time.Second)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"app-2026-10-19T20-50-02.000.log", "app-2026-10-19T20-50-03.000.log", "app.log"}
+	if got := listDir(t, dir); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("files %v, want %v", got, want)
+	}
+	if got := readLog(t, filepath.Join(dir, want[0])); got != "bbbbbbbb\n" {
+		t.Fatalf("backup content %q", got)
+	}
+	if got := readLog(t, filepath.Join(dir, "app.log")); got != "dddddddd\n" {
+		t.Fatalf("current content %q", got)
+	}
+	if _, err := w.Write([]byte("x")); err != ErrClosed {
+		t.Fatalf("write after close: %v", err)
+	}
+}
+
+func TestRotateSameMillisecond(t *testing.T) {
+	dir := t.TempDir()
+	w, err := NewRotatingFileWriter(filepath.Join(dir, "app.log"),
+		WithMaxSize(1), WithRotateNowFunc(newFakeClock().Now), WithReopenSignals())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := w.Write([]byte("x")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_ = w.Close()
+	want := []string{"app-2026-10-19T20-50-00.000.log", "app-2026-10-19T20-50-00.001.log", "app.log"}
+	if got := listDir(t, dir); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("files %v, want %v", got, want)
+	}
+}
+
+func TestRotateDailyWithCompressAndMaxAge(t *testing.T) {
+	dir := t.TempDir()
+	clock := newFakeClock()
+	w, err := NewRotatingFileWriter(filepath.Join(dir, "app.log"),
+		WithRotateInterval(RotateDaily), WithCompress(true), WithMaxAge(36*time.Hour),
+		WithRotateNowFunc(clock.Now), WithReopenSignals())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 每天20:50到22:50写入3行,第2天起第一次写入时滚动
+	for day := 0; day < 4; day++ {
+		if day > 0 {
+			clock.Advance(22 * time.Hour)
+		}
+		for i := 0; i < 3; i++ {
+			if i > 0 {
+				clock.Advance(time.Hour)
+			}
+			if _, err := w.Write([]byte("day\n")); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	// 备份时间为10-20,10-21,10-22的20:50,当前为10-22 22:50,10-20的备份超过36小时
+	want := []string{"app-2026-10-21T20-50-00.000.log.gz", "app-2026-10-22T20-50-00.000.log.gz", "app.log"}
+	if got := listDir(t, dir); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("files %v, want %v", got, want)
+	}
+	if got := readLog(t, filepath.Join(dir, want[0])); got != "day\nday\nday\n" {
+		t.Fatalf("compressed content %q", got)
+	}
+}
+
+func TestRotateHourlyExistingFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	if err := os.WriteFile(path, []byte("old\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	old := fixedTime.Add(-2 * time.Hour)
+	if err := os.Chtimes(path, old, old); err != nil {
+		t.Fatal(err)
+	}
+	w, err := NewRotatingFileWriter(path, WithRotateInterval(RotateHourly),
+		WithRotateNowFunc(newFakeClock().Now), WithReopenSignals())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte("new\n")); err != nil {
+		t.Fatal(err)
+	}
+	_ = w.Close()
+	if got := readLog(t, filepath.Join(dir, "app-2026-10-19T20-50-00.000.log")); got != "old\n" {
+		t.Fatalf("backup content %q", got)
+	}
+	if got := readLog(t, path); got != "new\n" {
+		t.Fatalf("current content %q", got)
+	}
+}
+
+func TestReopenOnSignal(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("SIGHUP is not supported on windows")
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	w, err := NewRotatingFileWriter(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	if _, err := w.Write([]byte("before\n")); err != nil {
+		t.Fatal(err)
+	}
+	// 模拟logrotate: 移走文件后发送SIGHUP
+	moved := filepath.Join(dir, "app.log.1")
+	if err := os.Rename(path, moved); err != nil {
+		t.Fatal(err)
+	}
+	if err := syscall.Kill(os.Getpid(), syscall.SIGHUP); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for !exists(path) {
+		if time.Now().After(deadline) {
+			t.Fatal("file not reopened after SIGHUP")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if _, err := w.Write([]byte("after\n")); err != nil {
+		t.Fatal(err)
+	}
+	if readLog(t, moved) != "before\n" || readLog(t, path) != "after\n" {
+		t.Fatalf("moved %q, current %q", readLog(t, moved), readLog(t, path))
+	}
+}
+
+func TestRotateConcurrent(t *testing.T) {
+	dir := t.TempDir()
+	w, err := NewRotatingFileWriter(filepath.Join(dir, "app.log"), WithMaxSize(4096), WithReopenSignals())
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := NewLogger(WithWriter(w))
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				logger.Info("concurrent", Int("n", j))
+				if j%50 == 0 {
+					_ = w.Reopen()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	lines := 0
+	for _, name := range listDir(t, dir) {
+		file, err := os.Open(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			if !strings.Contains(scanner.Text(), "INFO concurrent n=") {
+				t.Fatalf("corrupted line %q", scanner.Text())
+			}
+			lines++
+		}
+		_ = file.Close()
+	}
+	if lines != 1600 {
+		t.Fatalf("got %d lines", lines)
+	}
+}
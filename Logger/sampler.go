@@ -0,0 +1,260 @@
+package Logger
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"preseus/TokenBucket"
+)
+
+/****************************************************************
+ * @author: Ihc
+ * @date: 2026/10/20 00:10
+ * @description: 日志采样与限流
+	1.NewSampler: 每个周期内同一等级同一消息的前first条全部记录,之后每thereafter条记录一条
+	2.NewRateLimiter: 每个调用位置(或自定义的键)使用独立的令牌桶限流
+被丢弃的日志按消息计数,Logger在之后第一条记录的日志之前,或Sync时,
+为每个消息输出一条WARN级别的汇总日志;Fatal日志不参与采样
+ ***************************************************************/
+
+// Sampler 决定一条日志是否记录,必须并发安全
+type Sampler interface {
+	// Sample 返回是否记录entry,此时entry已包含全部字段
+	Sample(entry *Entry) bool
+}
+
+// callerSampler 需要调用位置的 Sampler,Logger会在采样前填充 Entry.PC
+type callerSampler interface {
+	needCaller() bool
+}
+
+// samplerBuckets 每个等级的计数器数量,不同消息哈希冲突时共享计数
+const samplerBuckets = 1024
+
+// counter 一个周期内的计数
+type counter struct {
+	resetAt int64  // resetAt 周期结束的时间,Unix纳秒
+	count   uint64 // count 周期内的日志条数
+}
+
+// incr 计数加一并返回周期内的条数,周期结束时重新计数
+func (c *counter) incr(now int64, interval time.Duration) uint64 {
+	resetAt := atomic.LoadInt64(&c.resetAt)
+	if resetAt > now {
+		return atomic.AddUint64(&c.count, 1)
+	}
+	if !atomic.CompareAndSwapInt64(&c.resetAt, resetAt, now+int64(interval)) {
+		// 其他goroutine已经开始新周期
+		return atomic.AddUint64(&c.count, 1)
+	}
+	atomic.StoreUint64(&c.count, 1)
+	return 1
+}
+
+// sampler 按消息计数的采样器
+type sampler struct {
+	interval   time.Duration
+	first      uint64
+	thereafter uint64
+	counters   [FatalLevel + 1][samplerBuckets]counter
+}
+
+// NewSampler 创建采样器
+// 每个interval内,同一等级同一消息的前first条全部记录,之后每thereafter条记录一条,thereafter为0时不再记录
+// 使用日志的时间计数,不分配内存
+func NewSampler(interval time.Duration, first, thereafter int) Sampler {
+	return &sampler{
+		interval:   interval,
+		first:      uint64(first),
+		thereafter: uint64(thereafter),
+	}
+}
+
+func (s *sampler) Sample(entry *Entry) bool {
+	if entry.Level < DebugLevel || entry.Level > FatalLevel {
+		return true
+	}
+	c := &s.counters[entry.Level][hashString(entry.Message)%samplerBuckets]
+	n := c.incr(entry.Time.UnixNano(), s.interval)
+	if n <= s.first {
+		return true
+	}
+	return s.thereafter > 0 && (n-s.first)%s.thereafter == 0
+}
+
+// hashString FNV-1a哈希
+func hashString(s string) uint32 {
+	h := uint32(2166136261)
+	for i := 0; i < len(s); i++ {
+		h ^= uint32(s[i])
+		h *= 16777619
+	}
+	return h
+}
+
+// RateLimitKey 返回日志所属的限流键,每个键使用独立的令牌桶
+type RateLimitKey func(entry *Entry) string
+
+// KeyByMessage 以消息为限流键
+func KeyByMessage(entry *Entry) string {
+	return entry.Message
+}
+
+// KeyByField 以字段的值为限流键,没有该字段的日志共用一个令牌桶
+func KeyByField(key string) RateLimitKey {
+	return func(entry *Entry) string {
+		for i := len(entry.Fields) - 1; i >= 0; i-- {
+			f := &entry.Fields[i]
+			if f.Key != key {
+				continue
+			}
+			switch f.Type {
+			case StringType:
+				return f.String
+			case IntType:
+				return strconv.FormatInt(f.Integer, 10)
+			}
+			return string(appendTextField(nil, f))
+		}
+		return ""
+	}
+}
+
+// maxRateLimitKeys 令牌桶数量的上限,超过后清空重建,避免键无限增长时占用内存
+const maxRateLimitKeys = 10000
+
+// rateLimiter 每个键独立限流的采样器
+type rateLimiter struct {
+	rate    float64
+	burst   int64
+	key     RateLimitKey
+	clock   TokenBucket.Clock
+	mu      sync.Mutex
+	callers map[uintptr]*TokenBucket.TokenBucket // callers 按调用位置的令牌桶
+	keys    map[string]*TokenBucket.TokenBucket  // keys 按限流键的令牌桶
+}
+
+// NewRateLimiter 创建限流采样器
+// 每个键每秒最多记录rate条,允许burst条的突发;key为nil时按调用 Logger 方法的位置限流
+// clock为nil时使用系统时钟;rate或burst不大于0时panic,而不是在第一条日志创建令牌桶时panic
+func NewRateLimiter(rate float64, burst int64, key RateLimitKey, clock TokenBucket.Clock) Sampler {
+	if !(rate > 0) {
+		panic("logger: rate limit is not > 0")
+	}
+	if burst <= 0 {
+		panic("logger: rate limit burst is not > 0")
+	}
+	return &rateLimiter{
+		rate:    rate,
+		burst:   burst,
+		key:     key,
+		clock:   clock,
+		callers: make(map[uintptr]*TokenBucket.TokenBucket),
+		keys:    make(map[string]*TokenBucket.TokenBucket),
+	}
+}
+
+func (r *rateLimiter) needCaller() bool {
+	return r.key == nil
+}
+
+func (r *rateLimiter) Sample(entry *Entry) bool {
+	return r.bucket(entry).TakeAvailable(1) == 1
+}
+
+// bucket 返回entry所属的令牌桶,不存在时创建
+func (r *rateLimiter) bucket(entry *Entry) *TokenBucket.TokenBucket {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.key == nil {
+		bucket, ok := r.callers[entry.PC]
+		if !ok {
+			if len(r.callers) >= maxRateLimitKeys {
+				r.callers = make(map[uintptr]*TokenBucket.TokenBucket)
+			}
+			bucket = r.newBucket()
+			r.callers[entry.PC] = bucket
+		}
+		return bucket
+	}
+	key := r.key(entry)
+	bucket, ok := r.keys[key]
+	if !ok {
+		if len(r.keys) >= maxRateLimitKeys {
+			r.keys = make(map[string]*TokenBucket.TokenBucket)
+		}
+		bucket = r.newBucket()
+		r.keys[key] = bucket
+	}
+	return bucket
+}
+
+func (r *rateLimiter) newBucket() *TokenBucket.TokenBucket {
+	return TokenBucket.NewTokenBucketWithRateAndClock(r.rate, r.burst, r.clock)
+}
+
+// sampling Logger的采样状态
+type sampling struct {
+	sampler    Sampler
+	interval   time.Duration    // interval 两次汇总之间的最短间隔
+	needCaller bool             // needCaller 采样前是否需要填充调用位置
+	pending    int64            // pending 尚未汇总的丢弃条数
+	last       int64            // last 上次汇总的时间,Unix纳秒
+	mu         sync.Mutex       // mu 保护suppressed
+	suppressed map[string]int64 // suppressed 每个消息尚未汇总的丢弃条数
+}
+
+func newSampling(sampler Sampler, interval time.Duration, now time.Time) *sampling {
+	s := &sampling{
+		sampler:    sampler,
+		interval:   interval,
+		last:       now.UnixNano(),
+		suppressed: make(map[string]int64),
+	}
+	if cs, ok := sampler.(callerSampler); ok {
+		s.needCaller = cs.needCaller()
+	}
+	return s
+}
+
+// suppress 记录一条被丢弃的日志
+func (s *sampling) suppress(msg string) {
+	s.mu.Lock()
+	s.suppressed[msg]++
+	atomic.AddInt64(&s.pending, 1)
+	s.mu.Unlock()
+}
+
+// due 是否需要输出汇总
+func (s *sampling) due(now time.Time) bool {
+	return atomic.LoadInt64(&s.pending) > 0 && now.UnixNano()-atomic.LoadInt64(&s.last) >= int64(s.interval)
+}
+
+// suppressedCount 一个消息的丢弃条数
+type suppressedCount struct {
+	message string
+	count   int64
+}
+
+// take 取出全部丢弃计数,按消息排序
+func (s *sampling) take(now time.Time) []suppressedCount {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	atomic.StoreInt64(&s.last, now.UnixNano())
+	if len(s.suppressed) == 0 {
+		return nil
+	}
+	counts := make([]suppressedCount, 0, len(s.suppressed))
+	for msg, count := range s.suppressed {
+		counts = append(counts, suppressedCount{message: msg, count: count})
+		delete(s.suppressed, msg)
+	}
+	atomic.StoreInt64(&s.pending, 0)
+	sort.Slice(counts, func(i, j int) bool {
+		return counts[i].message < counts[j].message
+	})
+	return counts
+}
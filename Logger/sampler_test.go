@@ -0,0 +1,184 @@
+package Logger
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+/****************************************************************
+ * @author: Ihc
+ * @date: 2026/10/20 00:40
+ * @description:
+ ***************************************************************/
+
+// Sleep 实现TokenBucket.Clock,直接推进时钟
+func (c *fakeClock) Sleep(d time.Duration) {
+	c.Advance(d)
+}
+
+func newSampledLogger(sampler Sampler, clock *fakeClock) (*Logger, *bytes.Buffer) {
+	buf := &bytes.Buffer{}
+	logger := NewLogger(
+		WithWriter(AddSync(buf)),
+		WithNowFunc(clock.Now),
+		WithEncoder(NewLogfmtEncoder()),
+		WithSampler(sampler, time.Second),
+	)
+	return logger, buf
+}
+
+// messages 输出中每行的msg与字段,去掉时间与等级
+func messages(buf *bytes.Buffer) []string {
+	var lines []string
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n") {
+		lines = append(lines, line[strings.Index(line, "msg="):])
+	}
+	buf.Reset()
+	return lines
+}
+
+func TestSampler(t *testing.T) {
+	clock := newFakeClock()
+	logger, buf := newSampledLogger(NewSampler(time.Second, 2, 3), clock)
+	for i := 1; i <= 10; i++ {
+		logger.Error("db timeout", Int("n", i))
+		logger.Info("other", Int("n", i))
+	}
+	var timeouts []string
+	for _, line := range messages(buf) {
+		if strings.HasPrefix(line, `msg="db timeout"`) {
+			timeouts = append(timeouts, line)
+		}
+	}
+	want := []string{`msg="db timeout" n=1`, `msg="db timeout" n=2`, `msg="db timeout" n=5`, `msg="db timeout" n=8`}
+	if strings.Join(timeouts, ",") != strings.Join(want, ",") {
+		t.Fatalf("sampled %v, want %v", timeouts, want)
+	}
+
+	// 新周期重新计数,并在第一条记录的日志之前输出汇总
+	clock.Advance(time.Second)
+	logger.Error("db timeout", Int("n", 11))
+	want = []string{
+		`msg="logger suppressed records" message="db timeout" suppressed=6`,
+		`msg="logger suppressed records" message=other suppressed=6`,
+		`msg="db timeout" n=11`,
+	}
+	if got := messages(buf); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestSamplerSummaryOnSync(t *testing.T) {
+	clock := newFakeClock()
+	logger, buf := newSampledLogger(NewSampler(time.Minute, 1, 0), clock)
+	for i := 0; i < 5; i++ {
+		logger.Warn("storm")
+	}
+	_ = logger.Sync()
+	want := []string{`msg=storm`, `msg="logger suppressed records" message=storm suppressed=4`}
+	if got := messages(buf); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	_ = logger.Sync()
+	if buf.Len() != 0 {
+		t.Fatalf("summary repeated: %q", buf.String())
+	}
+}
+
+func TestRateLimiterByCaller(t *testing.T) {
+	clock := newFakeClock()
+	logger, buf := newSampledLogger(NewRateLimiter(1, 2, nil, clock), clock)
+	for i := 0; i < 5; i++ {
+		logger.Error("a")
+		logger.Error("b")
+	}
+	if got := strings.Join(messages(buf), ","); got != "msg=a,msg=b,msg=a,msg=b" {
+		t.Fatalf("got %s", got)
+	}
+	// 同一个消息在不同位置调用,各自限流
+	logger.Error("a")
+	logger.Error("a")
+	if got := strings.Join(messages(buf), ","); got != "msg=a,msg=a" {
+		t.Fatalf("got %s", got)
+	}
+	// 令牌按速率补充
+	for i := 0; i < 10; i++ {
+		if i == 5 {
+			clock.Advance(time.Second)
+		}
+		logger.Error("c")
+	}
+	want := "msg=c,msg=c," +
+		`msg="logger suppressed records" message=a suppressed=3,` +
+		`msg="logger suppressed records" message=b suppressed=3,` +
+		`msg="logger suppressed records" message=c suppressed=3,msg=c`
+	if got := strings.Join(messages(buf), ","); got != want {
+		t.Fatalf("got %s, want %s", got, want)
+	}
+}
+
+func TestRateLimiterByField(t *testing.T) {
+	clock := newFakeClock()
+	logger, buf := newSampledLogger(NewRateLimiter(1, 1, KeyByField("tenant"), clock), clock)
+	for i := 0; i < 3; i++ {
+		logger.Error("quota", String("tenant", "a"))
+		logger.With(String("tenant", "b")).Error("quota")
+		logger.Error("quota", Int("tenant", 7))
+	}
+	want := "msg=quota tenant=a,msg=quota tenant=b,msg=quota tenant=7"
+	if got := strings.Join(messages(buf), ","); got != want {
+		t.Fatalf("got %s, want %s", got, want)
+	}
+	logger, buf = newSampledLogger(NewRateLimiter(1, 1, KeyByMessage, clock), clock)
+	logger.Error("x")
+	logger.Error("x")
+	logger.Error("y")
+	if got := strings.Join(messages(buf), ","); got != "msg=x,msg=y" {
+		t.Fatalf("got %s", got)
+	}
+}
+
+func TestRateLimiterInvalid(t *testing.T) {
+	for _, c := range []struct {
+		rate  float64
+		burst int64
+	}{{0, 1}, {-1, 1}, {math.NaN(), 1}, {1, 0}, {1, -1}} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("rate %v, burst %d: expect panic", c.rate, c.burst)
+				}
+			}()
+			NewRateLimiter(c.rate, c.burst, nil, nil)
+		}()
+	}
+}
+
+func TestSamplerFatalNotSampled(t *testing.T) {
+	clock := newFakeClock()
+	buf := &bytes.Buffer{}
+	exits := 0
+	logger := NewLogger(WithWriter(AddSync(buf)), WithNowFunc(clock.Now),
+		WithSampler(NewSampler(time.Second, 0, 0), time.Second), WithExitFunc(func(int) { exits++ }))
+	logger.Fatal("down")
+	logger.Fatal("down")
+	if exits != 2 || strings.Count(buf.String(), "FATAL down") != 2 {
+		t.Fatalf("exits %d, output %q", exits, buf.String())
+	}
+}
+
+func TestSamplerZeroAllocation(t *testing.T) {
+	if raceEnabled {
+		t.Skip("sync.Pool drops objects under the race detector")
+	}
+	logger := NewLogger(WithWriter(AddSync(&bytes.Buffer{})), WithEncoder(NewJSONEncoder()),
+		WithSampler(NewSampler(time.Hour, 1, 0), time.Hour))
+	logCommon(logger)
+	logCommon(logger)
+	if n := testing.AllocsPerRun(100, func() { logCommon(logger) }); n != 0 {
+		t.Fatalf("suppressed record allocates %v times", n)
+	}
+}
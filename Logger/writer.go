@@ -0,0 +1,118 @@
+package Logger
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+)
+
+/****************************************************************
+ * @author: Ihc
+ * @date: 2026/10/19 20:40
+ * @description: 日志输出对象
+Logger对同一个Writer的写入是串行的,Writer本身不需要并发安全
+每条日志只调用一次Write
+ ***************************************************************/
+
+// Writer 日志输出
+type Writer interface {
+	io.Writer
+	// Sync 将缓冲的数据刷到存储
+	Sync() error
+}
+
+// syncer 可以Sync的对象
+type syncer interface {
+	Sync() error
+}
+
+// writerWrapper 将io.Writer包装为 Writer
+type writerWrapper struct {
+	io.Writer
+}
+
+// Sync 底层对象可以Sync时调用其Sync
+func (w writerWrapper) Sync() error {
+	if s, ok := w.Writer.(syncer); ok {
+		return s.Sync()
+	}
+	return nil
+}
+
+// AddSync 将io.Writer包装为 Writer,底层对象没有Sync方法时Sync为空操作
+func AddSync(w io.Writer) Writer {
+	if writer, ok := w.(Writer); ok {
+		return writer
+	}
+	return writerWrapper{Writer: w}
+}
+
+// consoleWriter 标准输出与标准错误
+// 终端与管道不支持fsync,Sync忽略错误
+type consoleWriter struct {
+	*os.File
+}
+
+func (w consoleWriter) Sync() error {
+	_ = w.File.Sync()
+	return nil
+}
+
+// Stdout 标准输出
+func Stdout() Writer {
+	return consoleWriter{File: os.Stdout}
+}
+
+// Stderr 标准错误
+func Stderr() Writer {
+	return consoleWriter{File: os.Stderr}
+}
+
+// NewFileWriter 以追加方式打开日志文件,目录不存在时创建
+func NewFileWriter(path string) (Writer, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	return os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+}
+
+// multiWriter 同时写入多个 Writer
+type multiWriter []Writer
+
+// MultiWriter 将日志同时写入多个 Writer
+// 某个Writer出错不影响其他Writer,返回第一个错误
+func MultiWriter(writers ...Writer) Writer {
+	all := make(multiWriter, 0, len(writers))
+	for _, w := range writers {
+		if m, ok := w.(multiWriter); ok {
+			all = append(all, m...)
+			continue
+		}
+		all = append(all, w)
+	}
+	return all
+}
+
+func (m multiWriter) Write(p []byte) (int, error) {
+	var firstErr error
+	for _, w := range m {
+		n, err := w.Write(p)
+		if err == nil && n < len(p) {
+			err = io.ErrShortWrite
+		}
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return len(p), firstErr
+}
+
+func (m multiWriter) Sync() error {
+	var firstErr error
+	for _, w := range m {
+		if err := w.Sync(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,366 @@
+package ORM
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+/****************************************************************
+ * @author: Ihc
+ * @date: 2026/10/19 19:10
+ * @description: 批量插入与插入或更新
+多行合并为一条INSERT语句,按方言的参数上限自动分批;分片模型先按分片分组再分批
+分成多条语句时,不在事务中的会话为每个节点开启事务,失败时不会留下部分写入的行
+ ***************************************************************/
+
+// WithConsecutiveAutoIncrement 声明数据库保证多行INSERT的自增主键连续,CreateBatch据此回填MySQL的自增主键
+// MySQL需要innodb_autoinc_lock_mode为0或1,且auto_increment_increment为1
+func WithConsecutiveAutoIncrement() Option {
+	return func(options *Options) {
+		options.consecutiveID = true
+	}
+}
+
+// batchGroup 同一执行位置的一组模型
+type batchGroup struct {
+	route  route
+	values []reflect.Value
+}
+
+// CreateBatch 批量插入模型
+// models 为结构体切片,结构体指针切片或它们的指针;每个模型都会调用 BeforeCreateHook 与 AfterCreateHook
+// 由数据库生成的自增主键会被回填: PostgreSQL使用RETURNING,SQLite由LastInsertId推算;
+// MySQL同一语句的主键不保证连续,设置 WithConsecutiveAutoIncrement 后才由LastInsertId推算,否则在插入前返回错误
+// 需要多条语句时,不在事务中的会话在每个节点上开启事务,任一语句失败时全部回滚,并将回填的自增主键恢复为零值;
+// 各节点的事务在全部语句成功后依次提交,某个节点提交失败时之前已提交的节点不会回滚
+func (s *Session) CreateBatch(ctx context.Context, models interface{}) error {
+	return s.insertBatch(ctx, models, nil, false)
+}
+
+// Upsert 批量插入,主键或唯一键冲突时更新
+// conflict 为判断冲突的列,为空时使用主键(MySQL总是按主键与唯一索引判断);
+// 冲突时更新除冲突列,主键与版本号外的全部列,不做乐观锁校验,不回填自增主键
+// models 也可以是单个结构体指针;多条语句时的事务与 CreateBatch 相同
+func (s *Session) Upsert(ctx context.Context, models interface{}, conflict ...string) error {
+	sch, err := parseSchema(models)
+	if err != nil {
+		return err
+	}
+	if len(conflict) == 0 {
+		if sch.primaryKey == nil {
+			return fmt.Errorf("%w: %s", ErrMissingPrimaryKey, sch.typ)
+		}
+		conflict = []string{sch.primaryKey.column}
+	}
+	for _, column := range conflict {
+		if _, ok := sch.columns[column]; !ok {
+			return fmt.Errorf("orm: conflict column %q not found in %s", column, sch.typ)
+		}
+	}
+	return s.insertBatch(ctx, models, conflict, true)
+}
+
+// insertBatch 批量插入,upsert为true时追加冲突更新子句
+func (s *Session) insertBatch(ctx context.Context, models interface{}, conflict []string, upsert bool) error {
+	values, sch, err := modelValues(models)
+	if err != nil || len(values) == 0 {
+		return err
+	}
+	for _, v := range values {
+		if hook, ok := v.Addr().Interface().(BeforeCreateHook); ok {
+			if err := hook.BeforeCreate(ctx); err != nil {
+				return err
+			}
+		}
+	}
+	// 第一个模型的自增主键为零值时,全部模型都由数据库生成主键
+	omitPK := sch.primaryKey != nil && sch.primaryKey.autoIncrement &&
+		fieldByIndex(values[0], sch.primaryKey.index).IsZero()
+	fields := make([]*field, 0, len(sch.fields))
+	for _, f := range sch.fields {
+		if !(omitPK && f == sch.primaryKey) {
+			fields = append(fields, f)
+		}
+	}
+	groups, err := s.groupByRoute(values, sch)
+	if err != nil {
+		return err
+	}
+	for _, v := range values {
+		if sch.primaryKey != nil && sch.primaryKey.autoIncrement &&
+			fieldByIndex(v, sch.primaryKey.index).IsZero() != omitPK {
+			return fmt.Errorf("orm: batch of %s mixes zero and non-zero auto increment keys", sch.typ)
+		}
+		if sch.version != nil {
+			if fv := fieldByIndex(v, sch.version.index); fv.IsZero() {
+				setInteger(fv, 1)
+			}
+		}
+	}
+	var suffix string
+	dialect := s.db.dialect
+	if upsert {
+		suffix = dialect.Upsert(quoteAll(dialect, conflict), quoteAll(dialect, upsertColumns(sch, fields, conflict)))
+	}
+	mode := noBackfill
+	if omitPK && !upsert {
+		if mode, err = s.backfillMode(sch); err != nil {
+			return err
+		}
+	}
+	if mode == backfillReturning {
+		suffix = " RETURNING " + dialect.Quote(sch.primaryKey.column)
+	}
+	chunk := dialect.MaxParams() / len(fields)
+	if chunk < 1 {
+		return fmt.Errorf("orm: %s has more columns than %s allows parameters", sch.typ, dialect.Name())
+	}
+	if err := s.insertGroups(ctx, groups, sch, fields, chunk, suffix, mode); err != nil {
+		if mode != noBackfill {
+			for _, v := range values {
+				pk := fieldByIndex(v, sch.primaryKey.index)
+				pk.Set(reflect.Zero(pk.Type()))
+			}
+		}
+		return err
+	}
+	for _, v := range values {
+		if hook, ok := v.Addr().Interface().(AfterCreateHook); ok {
+			if err := hook.AfterCreate(ctx); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// insertGroups 每组按chunk行一条语句插入
+// 会话不在事务中且需要多条语句时,每个节点的语句在该节点主库的事务中执行,全部成功后依次提交,出错时全部回滚
+func (s *Session) insertGroups(ctx context.Context, groups []*batchGroup, sch *schema, fields []*field, chunk int, suffix string, mode backfill) (err error) {
+	statements := 0
+	for _, group := range groups {
+		statements += (len(group.values) + chunk - 1) / chunk
+	}
+	var txs []*Session
+	if s.tx == nil && statements > 1 {
+		defer func() {
+			if err != nil {
+				for _, tx := range txs {
+					_ = tx.tx.Rollback()
+				}
+			}
+		}()
+	}
+	for _, group := range groups {
+		session := s
+		if s.tx == nil && statements > 1 {
+			if session, err = s.nodeTx(ctx, &txs, group.route.node); err != nil {
+				return err
+			}
+		}
+		for start := 0; start < len(group.values); start += chunk {
+			end := start + chunk
+			if end > len(group.values) {
+				end = len(group.values)
+			}
+			if err = session.insertChunk(ctx, group.route, sch, fields, group.values[start:end], suffix, mode); err != nil {
+				return err
+			}
+		}
+	}
+	for _, tx := range txs {
+		if err = tx.tx.Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// nodeTx 返回txs中节点n上的事务会话,不存在时开启事务并加入txs
+func (s *Session) nodeTx(ctx context.Context, txs *[]*Session, n *node) (*Session, error) {
+	for _, tx := range *txs {
+		if tx.txNode == n {
+			return tx, nil
+		}
+	}
+	sqlTx, err := n.primary.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	tx := *s
+	tx.tx = sqlTx
+	tx.txNode = n
+	*txs = append(*txs, &tx)
+	return &tx, nil
+}
+
+// backfill 批量插入回填自增主键的方式
+type backfill int
+
+const (
+	// noBackfill 不回填
+	noBackfill backfill = iota
+	// backfillReturning 通过RETURNING读取每一行的主键
+	backfillReturning
+	// backfillFirstID LastInsertId为第一行的主键,之后的行依次加1
+	backfillFirstID
+	// backfillLastID LastInsertId为最后一行的主键,之前的行依次减1
+	backfillLastID
+)
+
+// backfillMode 返回回填自增主键的方式,方言无法可靠获得每一行的主键时返回错误
+func (s *Session) backfillMode(sch *schema) (backfill, error) {
+	dialect := s.db.dialect
+	if !dialect.SupportsLastInsertID() {
+		return backfillReturning, nil
+	}
+	first, consecutive := dialect.BatchInsertID()
+	if !consecutive && !s.db.options.consecutiveID {
+		return noBackfill, fmt.Errorf("orm: %s cannot report auto increment keys of a batch insert of %s, assign the keys or use WithConsecutiveAutoIncrement", dialect.Name(), sch.typ)
+	}
+	if first {
+		return backfillFirstID, nil
+	}
+	return backfillLastID, nil
+}
+
+// insertChunk 用一条语句插入一批模型
+func (s *Session) insertChunk(ctx context.Context, r route, sch *schema, fields []*field, values []reflect.Value, suffix string, mode backfill) error {
+	dialect := s.db.dialect
+	columns := make([]string, len(fields))
+	for i, f := range fields {
+		columns[i] = dialect.Quote(f.column)
+	}
+	marks := "(" + strings.TrimSuffix(strings.Repeat("?, ", len(fields)), ", ") + ")"
+	var b strings.Builder
+	b.WriteString("INSERT INTO " + dialect.Quote(r.table) + " (" + strings.Join(columns, ", ") + ") VALUES ")
+	stmt := &statement{args: make([]interface{}, 0, len(fields)*len(values))}
+	for i, v := range values {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(marks)
+		for _, f := range fields {
+			stmt.bind(fieldByIndex(v, f.index).Interface(), f.sensitive)
+		}
+	}
+	b.WriteString(suffix)
+	stmt.query = b.String()
+	switch mode {
+	case noBackfill:
+		_, err := s.exec(ctx, r, stmt)
+		return err
+	case backfillFirstID, backfillLastID:
+		result, err := s.exec(ctx, r, stmt)
+		if err != nil {
+			return err
+		}
+		id, err := result.LastInsertId()
+		if err != nil {
+			return err
+		}
+		if mode == backfillLastID {
+			id -= int64(len(values) - 1)
+		}
+		for i, v := range values {
+			setInteger(fieldByIndex(v, sch.primaryKey.index), id+int64(i))
+		}
+		return nil
+	}
+	rows, err := s.query(ctx, r, stmt, true)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for _, v := range values {
+		if !rows.Next() {
+			break
+		}
+		if err := rows.Scan(fieldByIndex(v, sch.primaryKey.index).Addr().Interface()); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// groupByRoute 按执行位置分组,组的顺序与模型第一次出现的顺序一致
+func (s *Session) groupByRoute(values []reflect.Value, sch *schema) ([]*batchGroup, error) {
+	var (
+		groups []*batchGroup
+		index  = make(map[route]int)
+	)
+	for _, v := range values {
+		r, err := s.modelRoute(v, sch)
+		if err != nil {
+			return nil, err
+		}
+		i, ok := index[r]
+		if !ok {
+			i = len(groups)
+			index[r] = i
+			groups = append(groups, &batchGroup{route: r})
+		}
+		groups[i].values = append(groups[i].values, v)
+	}
+	return groups, nil
+}
+
+// upsertColumns 冲突时更新的列: 除冲突列,主键与版本号外插入的全部列
+func upsertColumns(sch *schema, fields []*field, conflict []string) []string {
+	var columns []string
+outer:
+	for _, f := range fields {
+		if f.primaryKey || f.version {
+			continue
+		}
+		for _, column := range conflict {
+			if f.column == column {
+				continue outer
+			}
+		}
+		columns = append(columns, f.column)
+	}
+	return columns
+}
+
+// quoteAll 对列名逐个加引号
+func quoteAll(dialect Dialect, columns []string) []string {
+	quoted := make([]string, len(columns))
+	for i, column := range columns {
+		quoted[i] = dialect.Quote(column)
+	}
+	return quoted
+}
+
+// modelValues 返回批量操作的全部模型(可寻址的结构体Value)
+// models 为结构体切片,结构体指针切片,它们的指针,或单个结构体指针
+func modelValues(models interface{}) ([]reflect.Value, *schema, error) {
+	sch, err := parseSchema(models)
+	if err != nil {
+		return nil, nil, err
+	}
+	v := reflect.ValueOf(models)
+	if v.Kind() == reflect.Ptr && !v.IsNil() && v.Elem().Kind() == reflect.Struct {
+		return []reflect.Value{v.Elem()}, sch, nil
+	}
+	if v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Slice {
+		return nil, nil, fmt.Errorf("%w: batch needs a slice of models, got %T", ErrInvalidModel, models)
+	}
+	values := make([]reflect.Value, 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		elem := v.Index(i)
+		if elem.Kind() == reflect.Ptr {
+			if elem.IsNil() {
+				return nil, nil, fmt.Errorf("%w: nil model at index %d", ErrInvalidModel, i)
+			}
+			elem = elem.Elem()
+		}
+		values = append(values, elem)
+	}
+	return values, sch, nil
+}
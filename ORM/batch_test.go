@@ -0,0 +1,234 @@
+package ORM
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+/****************************************************************
+ * @author: Ihc
+ * @date: 2026/10/19 20:05
+ * @description:
+ ***************************************************************/
+
+type metric struct {
+	ID    int64  `orm:"id,auto"`
+	Name  string `orm:"name"`
+	Value int64  `orm:"value"`
+}
+
+func TestCreateBatchChunking(t *testing.T) {
+	db, recorder := newTestDB(t, SQLite)
+	metrics := make([]metric, 700)
+	for i := range metrics {
+		metrics[i] = metric{Name: "m", Value: int64(i)}
+	}
+	if err := db.CreateBatch(context.Background(), metrics); err != nil {
+		t.Fatal(err)
+	}
+	statements := recorder.All()
+	// 每行2个参数,999个参数上限下每批499行
+	if len(statements) != 2 || len(statements[0].Args) != 998 || len(statements[1].Args) != 402 {
+		t.Fatal("chunking", len(statements))
+	}
+	if !strings.HasPrefix(statements[0].Query, `INSERT INTO "metric" ("name", "value") VALUES (?, ?), (?, ?), `) {
+		t.Fatal(statements[0].Query[:80])
+	}
+	if statements[1].Args[1] != int64(499) {
+		t.Fatal("second chunk must start at row 499", statements[1].Args[1])
+	}
+	if recorder.Commits() != 1 {
+		t.Fatal("chunks must be committed in one transaction", recorder.Commits())
+	}
+}
+
+func TestCreateBatchRollback(t *testing.T) {
+	db, recorder := newTestDB(t, SQLite)
+	metrics := make([]*metric, 700)
+	for i := range metrics {
+		metrics[i] = &metric{Name: "m"}
+	}
+	// 第二批失败时第一批回滚,已回填的主键恢复为零值
+	boom := errors.New("boom")
+	recorder.PushErrors(nil, boom)
+	if err := db.CreateBatch(context.Background(), metrics); err != boom {
+		t.Fatal(err)
+	}
+	if recorder.Commits() != 0 || recorder.Rollbacks() != 1 {
+		t.Fatal("commits", recorder.Commits(), "rollbacks", recorder.Rollbacks())
+	}
+	for i, m := range metrics {
+		if m.ID != 0 {
+			t.Fatalf("metric %d keeps id %d", i, m.ID)
+		}
+	}
+
+	// 已在事务中时使用该事务,不再开启事务
+	err := db.Transaction(context.Background(), func(tx *Tx) error {
+		return tx.CreateBatch(context.Background(), metrics)
+	})
+	if err != nil || recorder.Commits() != 1 || recorder.Rollbacks() != 1 {
+		t.Fatal(err, "commits", recorder.Commits(), "rollbacks", recorder.Rollbacks())
+	}
+	// 一条语句不需要事务
+	if err := db.CreateBatch(context.Background(), []*metric{{Name: "a"}}); err != nil || recorder.Commits() != 1 {
+		t.Fatal(err, "commits", recorder.Commits())
+	}
+}
+
+func TestCreateBatchBackfill(t *testing.T) {
+	db, recorder := newTestDB(t, PostgreSQL)
+	recorder.PushRows([]string{"id"}, []interface{}{int64(5)}, []interface{}{int64(6)})
+	metrics := []*metric{{Name: "a"}, {Name: "b"}}
+	if err := db.CreateBatch(context.Background(), &metrics); err != nil {
+		t.Fatal(err)
+	}
+	want := `INSERT INTO "metric" ("name", "value") VALUES ($1, $2), ($3, $4) RETURNING "id"`
+	if stmt := recorder.Last(); stmt.Query != want {
+		t.Fatal(stmt.Query)
+	}
+	if metrics[0].ID != 5 || metrics[1].ID != 6 {
+		t.Fatal("ids not backfilled")
+	}
+	mixed := []*metric{{ID: 1}, {}}
+	if err := db.CreateBatch(context.Background(), mixed); err == nil {
+		t.Fatal("mixed auto increment keys must be rejected")
+	}
+}
+
+func TestCreateBatchLastInsertID(t *testing.T) {
+	// SQLite: LastInsertId为每批最后一行的主键
+	db, recorder := newTestDB(t, SQLite)
+	recorder.PushLastInsertID(499, 700)
+	metrics := make([]*metric, 700)
+	for i := range metrics {
+		metrics[i] = &metric{Name: "m"}
+	}
+	if err := db.CreateBatch(context.Background(), metrics); err != nil {
+		t.Fatal(err)
+	}
+	for i, m := range metrics {
+		if m.ID != int64(i+1) {
+			t.Fatalf("metric %d has id %d", i, m.ID)
+		}
+	}
+
+	// MySQL: 默认无法回填,插入前返回错误
+	db, recorder = newTestDB(t, MySQL)
+	if err := db.CreateBatch(context.Background(), []*metric{{Name: "a"}, {Name: "b"}}); err == nil {
+		t.Fatal("batch insert without reliable keys must fail")
+	}
+	if len(recorder.All()) != 0 {
+		t.Fatal("nothing may be inserted when keys cannot be backfilled")
+	}
+	if err := db.CreateBatch(context.Background(), []*metric{{ID: 1, Name: "a"}}); err != nil {
+		t.Fatal("assigned keys need no backfill", err)
+	}
+
+	// MySQL声明主键连续后,LastInsertId为第一行的主键
+	sqlDB, recorder := openFake(t)
+	db = NewDB(sqlDB, MySQL, WithConsecutiveAutoIncrement())
+	recorder.PushLastInsertID(10)
+	pair := []metric{{Name: "a"}, {Name: "b"}}
+	if err := db.CreateBatch(context.Background(), pair); err != nil {
+		t.Fatal(err)
+	}
+	if pair[0].ID != 10 || pair[1].ID != 11 {
+		t.Fatal("ids", pair[0].ID, pair[1].ID)
+	}
+}
+
+func TestUpsert(t *testing.T) {
+	cases := []struct {
+		dialect  Dialect
+		conflict []string
+		want     string
+	}{
+		{MySQL, nil, "INSERT INTO `metric` (`id`, `name`, `value`) VALUES (?, ?, ?), (?, ?, ?) ON DUPLICATE KEY UPDATE `name` = VALUES(`name`), `value` = VALUES(`value`)"},
+		{PostgreSQL, []string{"name"}, `INSERT INTO "metric" ("id", "name", "value") VALUES ($1, $2, $3), ($4, $5, $6) ON CONFLICT ("name") DO UPDATE SET "value" = EXCLUDED."value"`},
+		{SQLite, []string{"name", "value"}, `INSERT INTO "metric" ("id", "name", "value") VALUES (?, ?, ?), (?, ?, ?) ON CONFLICT ("name", "value") DO NOTHING`},
+	}
+	for _, c := range cases {
+		db, recorder := newTestDB(t, c.dialect)
+		metrics := []metric{{ID: 1, Name: "a", Value: 1}, {ID: 2, Name: "b", Value: 2}}
+		if err := db.Upsert(context.Background(), metrics, c.conflict...); err != nil {
+			t.Fatal(err)
+		}
+		if stmt := recorder.Last(); stmt.Query != c.want {
+			t.Fatal(c.dialect.Name(), stmt.Query)
+		}
+	}
+	db, _ := newTestDB(t, MySQL)
+	if err := db.Upsert(context.Background(), &metric{ID: 1}, "missing"); err == nil {
+		t.Fatal("unknown conflict column must be rejected")
+	}
+}
+
+func TestCursor(t *testing.T) {
+	db, recorder := newTestDB(t, MySQL)
+	columns := []string{"id", "name", "value"}
+	recorder.PushRows(columns,
+		[]interface{}{int64(1), "a", int64(10)},
+		[]interface{}{int64(2), "b", int64(20)},
+		[]interface{}{int64(3), "c", int64(30)},
+	)
+	cursor, err := db.Cursor(context.Background(), &metric{}, "value > ?", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cursor.Close()
+	if err := cursor.Scan(&metric{}); err != errNoRow {
+		t.Fatal("Scan before Next", err)
+	}
+	var (
+		m   metric
+		sum int64
+	)
+	for cursor.Next() {
+		if err := cursor.Scan(&m); err != nil {
+			t.Fatal(err)
+		}
+		sum += m.Value
+	}
+	if err := cursor.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if sum != 60 || !reflect.DeepEqual(m, metric{ID: 3, Name: "c", Value: 30}) {
+		t.Fatal("cursor rows", sum, m)
+	}
+	if err := cursor.Scan(&user{}); err == nil {
+		t.Fatal("Scan into another model must fail")
+	}
+}
+
+func TestCursorAcrossShards(t *testing.T) {
+	db, recorder := newTestDB(t, MySQL)
+	if err := db.RegisterShardRule(&order{}, ModShardRule("user_id", 1, 3)); err != nil {
+		t.Fatal(err)
+	}
+	columns := []string{"id", "user_id", "amount"}
+	recorder.PushRows(columns, []interface{}{int64(1), int64(0), int64(1)})
+	recorder.PushRows(columns)
+	recorder.PushRows(columns, []interface{}{int64(2), int64(2), int64(1)}, []interface{}{int64(3), int64(5), int64(1)})
+	cursor, err := db.Cursor(context.Background(), &order{}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []int64
+	for cursor.Next() {
+		var o order
+		if err := cursor.Scan(&o); err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, o.ID)
+	}
+	if !reflect.DeepEqual(ids, []int64{1, 2, 3}) || cursor.Err() != nil {
+		t.Fatal(ids, cursor.Err())
+	}
+	if stmt := recorder.Last(); stmt.Query != "SELECT `id`, `user_id`, `amount` FROM `order_2`" {
+		t.Fatal(stmt.Query)
+	}
+}
@@ -0,0 +1,209 @@
+package ORM
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"reflect"
+	"sync/atomic"
+)
+
+/****************************************************************
+ * @author: Ihc
+ * @date: 2026/10/19 17:20
+ * @description: 读写分离与分库分表
+节点: 一个主库加若干从库,NewDB传入的*sql.DB为0号节点的主库
+	写操作发往主库,读操作轮询发往从库(没有从库时发往主库)
+	事务内的读写全部在主库的事务上执行,保证读到本事务的写入
+	Primary 返回强制读主库的会话
+分片: 为模型注册 ShardRule 后,按分片键把行路由到节点与物理表
+	Create/Update/Delete 从模型的分片键列取值
+	Find/First 通过 ShardKey 指定分片键,未指定时分发到全部分片并合并结果,First返回主键最小的记录
+	原生语句(Exec/Query)不做分片路由
+ ***************************************************************/
+
+var (
+	// ErrCrossNodeTx 事务中访问了事务所在节点(Begin开启的事务在0号节点)以外的分片
+	ErrCrossNodeTx = errors.New("orm: transaction cannot access shard on another node")
+)
+
+// node 数据库节点
+type node struct {
+	primary  *sql.DB   // primary 主库
+	replicas []*sql.DB // replicas 从库
+	next     uint32    // next 轮询从库的计数
+}
+
+// replica 轮询选择从库,没有从库时返回主库
+func (n *node) replica() *sql.DB {
+	if len(n.replicas) == 0 {
+		return n.primary
+	}
+	i := atomic.AddUint32(&n.next, 1)
+	return n.replicas[int(i)%len(n.replicas)]
+}
+
+// WithReplicas 设置0号节点的从库
+func WithReplicas(replicas ...*sql.DB) Option {
+	return func(options *Options) {
+		options.replicas = append(options.replicas, replicas...)
+	}
+}
+
+// WithNode 添加分库节点,节点编号按添加顺序从1开始
+func WithNode(primary *sql.DB, replicas ...*sql.DB) Option {
+	return func(options *Options) {
+		options.nodes = append(options.nodes, &node{primary: primary, replicas: replicas})
+	}
+}
+
+// Shard 分片
+type Shard struct {
+	Node   int    // Node 节点编号,0为NewDB传入的数据库
+	Suffix string // Suffix 物理表名后缀,物理表名 = 逻辑表名 + Suffix
+}
+
+// ShardRule 分片规则
+type ShardRule struct {
+	Column string                             // Column 分片键所在的列
+	Shards []Shard                            // Shards 全部分片,无分片键的查询分发到全部分片
+	Locate func(key interface{}) (int, error) // Locate 返回分片键所在分片在Shards中的下标
+}
+
+// ModShardRule 按分片键取模的分片规则
+// 共nodes个节点,每个节点tables张表,物理表名为 逻辑表名_序号(序号从0开始全局编号)
+// 整数分片键直接取模,其他类型取fmt.Sprint结果的FNV哈希后取模
+func ModShardRule(column string, nodes, tables int) *ShardRule {
+	if nodes <= 0 || tables <= 0 {
+		panic("orm: shard nodes and tables must be > 0")
+	}
+	rule := &ShardRule{Column: column}
+	for i := 0; i < nodes*tables; i++ {
+		rule.Shards = append(rule.Shards, Shard{Node: i / tables, Suffix: fmt.Sprintf("_%d", i)})
+	}
+	total := uint64(len(rule.Shards))
+	rule.Locate = func(key interface{}) (int, error) {
+		v := reflect.ValueOf(key)
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			// 在uint64上取绝对值,math.MinInt64取反后仍为负数
+			n := uint64(v.Int())
+			if v.Int() < 0 {
+				n = -n
+			}
+			return int(n % total), nil
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return int(v.Uint() % total), nil
+		case reflect.Invalid:
+			return 0, fmt.Errorf("orm: shard key of column %q is nil", column)
+		}
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(fmt.Sprint(key)))
+		return int(h.Sum64() % total), nil
+	}
+	return rule
+}
+
+// RegisterShardRule 为模型注册分片规则,应在使用模型前调用
+func (db *DB) RegisterShardRule(model interface{}, rule *ShardRule) error {
+	sch, err := parseSchema(model)
+	if err != nil {
+		return err
+	}
+	if _, ok := sch.columns[rule.Column]; !ok {
+		return fmt.Errorf("orm: shard column %q not found in %s", rule.Column, sch.typ)
+	}
+	if len(rule.Shards) == 0 || rule.Locate == nil {
+		return fmt.Errorf("orm: shard rule of %s has no shards or Locate", sch.typ)
+	}
+	for _, shard := range rule.Shards {
+		if shard.Node < 0 || shard.Node >= len(db.nodes) {
+			return fmt.Errorf("orm: shard rule of %s refers to unknown node %d", sch.typ, shard.Node)
+		}
+	}
+	db.shardRules.Store(sch.typ, rule)
+	return nil
+}
+
+// route 语句的执行位置
+type route struct {
+	node  *node  // node 节点
+	table string // table 物理表名
+}
+
+// ShardKey 返回指定分片键的会话,Find与First只查询分片键所在的分片
+func (s *Session) ShardKey(key interface{}) *Session {
+	session := *s
+	session.shardKey = key
+	session.hasShardKey = true
+	return &session
+}
+
+// Primary 返回强制读主库的会话
+// 用于写入后立即读取,避免从库复制延迟
+func (s *Session) Primary() *Session {
+	session := *s
+	session.primary = true
+	return &session
+}
+
+// routes 返回模型语句的执行位置
+// 模型未注册分片规则时为0号节点上的逻辑表;
+// key有效时为分片键所在的分片,否则为全部分片
+func (s *Session) routes(sch *schema, key interface{}, hasKey bool) ([]route, error) {
+	value, ok := s.db.shardRules.Load(sch.typ)
+	if !ok {
+		return []route{{node: s.db.nodes[0], table: sch.table}}, nil
+	}
+	rule := value.(*ShardRule)
+	if hasKey {
+		i, err := rule.Locate(key)
+		if err != nil {
+			return nil, err
+		}
+		if i < 0 || i >= len(rule.Shards) {
+			return nil, fmt.Errorf("orm: shard index %d of %s out of range", i, sch.typ)
+		}
+		return []route{s.db.shardRoute(sch, rule.Shards[i])}, nil
+	}
+	routes := make([]route, len(rule.Shards))
+	for i, shard := range rule.Shards {
+		routes[i] = s.db.shardRoute(sch, shard)
+	}
+	return routes, nil
+}
+
+// modelRoute 返回单个模型的执行位置,分片键取自模型
+func (s *Session) modelRoute(v reflect.Value, sch *schema) (route, error) {
+	value, ok := s.db.shardRules.Load(sch.typ)
+	if !ok {
+		return route{node: s.db.nodes[0], table: sch.table}, nil
+	}
+	key := fieldByIndex(v, sch.columns[value.(*ShardRule).Column].index).Interface()
+	routes, err := s.routes(sch, key, true)
+	if err != nil {
+		return route{}, err
+	}
+	return routes[0], nil
+}
+
+// shardRoute 分片对应的执行位置
+func (db *DB) shardRoute(sch *schema, shard Shard) route {
+	return route{node: db.nodes[shard.Node], table: sch.table + shard.Suffix}
+}
+
+// executor 返回执行语句的连接
+// 事务会话使用事务;写操作与强制读主库的会话使用主库;其余读操作使用从库
+func (s *Session) executor(n *node, write bool) (executor, error) {
+	if s.tx != nil {
+		if n != s.txNode {
+			return nil, ErrCrossNodeTx
+		}
+		return s.tx, nil
+	}
+	if write || s.primary {
+		return n.primary, nil
+	}
+	return n.replica(), nil
+}
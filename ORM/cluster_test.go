@@ -0,0 +1,202 @@
+package ORM
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math"
+	"testing"
+
+	"preseus/ORM/internal/fakedriver"
+)
+
+/****************************************************************
+ * @author: Ihc
+ * @date: 2026/10/19 18:05
+ * @description:
+ ***************************************************************/
+
+type order struct {
+	ID     int64 `orm:"id,pk"`
+	UserID int64
+	Amount int64
+}
+
+func openFake(t *testing.T) (*sql.DB, *fakedriver.Recorder) {
+	sqlDB, recorder, err := fakedriver.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	return sqlDB, recorder
+}
+
+func TestReadWriteSplitting(t *testing.T) {
+	primary, primaryRecorder := openFake(t)
+	replica1, replicaRecorder1 := openFake(t)
+	replica2, replicaRecorder2 := openFake(t)
+	db := NewDB(primary, MySQL, WithReplicas(replica1, replica2))
+	ctx := context.Background()
+
+	if err := db.Create(ctx, &order{ID: 1, UserID: 1}); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 4; i++ {
+		var orders []order
+		if err := db.Find(ctx, &orders, "user_id = ?", 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(primaryRecorder.All()) != 1 || len(replicaRecorder1.All()) != 2 || len(replicaRecorder2.All()) != 2 {
+		t.Fatal("writes must go to primary and reads must be balanced across replicas")
+	}
+
+	var orders []order
+	if err := db.Primary().Find(ctx, &orders, ""); err != nil {
+		t.Fatal(err)
+	}
+	err := db.Transaction(ctx, func(tx *Tx) error {
+		if err := tx.Update(ctx, &order{ID: 1, UserID: 1, Amount: 10}); err != nil {
+			return err
+		}
+		return tx.Find(ctx, &orders, "id = ?", 1)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(primaryRecorder.All()) != 4 || len(replicaRecorder1.All())+len(replicaRecorder2.All()) != 4 {
+		t.Fatal("reads of Primary sessions and transactions must stay on primary")
+	}
+}
+
+func TestSharding(t *testing.T) {
+	node0, recorder0 := openFake(t)
+	node1, recorder1 := openFake(t)
+	db := NewDB(node0, MySQL, WithNode(node1))
+	if err := db.RegisterShardRule(&order{}, ModShardRule("user_id", 2, 2)); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	if err := db.Create(ctx, &order{ID: 1, UserID: 3}); err != nil {
+		t.Fatal(err)
+	}
+	if stmt := recorder1.Last(); stmt.Query != "INSERT INTO `order_3` (`id`, `user_id`, `amount`) VALUES (?, ?, ?)" {
+		t.Fatal(stmt.Query)
+	}
+	if err := db.Delete(ctx, &order{ID: 1, UserID: 4}); err != nil {
+		t.Fatal(err)
+	}
+	if stmt := recorder0.Last(); stmt.Query != "DELETE FROM `order_0` WHERE `id` = ?" {
+		t.Fatal(stmt.Query)
+	}
+
+	var orders []order
+	if err := db.ShardKey(int64(2)).Find(ctx, &orders, "user_id = ?", 2); err != nil {
+		t.Fatal(err)
+	}
+	if stmt := recorder1.Last(); stmt.Query != "SELECT `id`, `user_id`, `amount` FROM `order_2` WHERE (user_id = ?)" {
+		t.Fatal(stmt.Query)
+	}
+
+	columns := []string{"id", "user_id", "amount"}
+	recorder0.PushRows(columns, []interface{}{int64(10), int64(0), int64(1)})
+	recorder1.PushRows(columns, []interface{}{int64(11), int64(3), int64(1)})
+	orders = nil
+	if err := db.Find(ctx, &orders, "amount > ?", 0); err != nil {
+		t.Fatal(err)
+	}
+	if len(orders) != 2 || len(recorder0.All()) != 3 || len(recorder1.All()) != 4 {
+		t.Fatal("scatter-gather must query every shard and merge rows", len(orders))
+	}
+
+	err := db.Transaction(ctx, func(tx *Tx) error {
+		return tx.Create(ctx, &order{ID: 2, UserID: 3})
+	})
+	if !errors.Is(err, ErrCrossNodeTx) {
+		t.Fatal("expect ErrCrossNodeTx", err)
+	}
+
+	// 批量插入在每个节点的事务中执行,任一节点失败时全部回滚
+	batch := []order{{ID: 3, UserID: 0}, {ID: 4, UserID: 3}}
+	boom := errors.New("boom")
+	recorder1.PushErrors(boom)
+	rollbacks := recorder0.Rollbacks()
+	if err := db.CreateBatch(ctx, batch); err != boom {
+		t.Fatal(err)
+	}
+	if recorder0.Commits() != 0 || recorder0.Rollbacks() != rollbacks+1 || recorder1.Rollbacks() != 1 {
+		t.Fatal("rollbacks", recorder0.Rollbacks(), recorder1.Rollbacks())
+	}
+	if err := db.CreateBatch(ctx, batch); err != nil {
+		t.Fatal(err)
+	}
+	if recorder0.Commits() != 1 || recorder1.Commits() != 1 {
+		t.Fatal("commits", recorder0.Commits(), recorder1.Commits())
+	}
+	if err := db.RegisterShardRule(&order{}, ModShardRule("missing", 1, 1)); err == nil {
+		t.Fatal("unknown shard column must be rejected")
+	}
+}
+
+func TestModShardRule(t *testing.T) {
+	rule := ModShardRule("user_id", 1, 3)
+	cases := map[interface{}]int{
+		int64(4):             1,
+		int64(-4):            1,
+		int64(math.MinInt64): int(uint64(1<<63) % 3),
+		uint8(5):             2,
+	}
+	for key, want := range cases {
+		if i, err := rule.Locate(key); err != nil || i != want {
+			t.Fatalf("Locate(%v) = %d, %v, want %d", key, i, err, want)
+		}
+	}
+	if _, err := rule.Locate(nil); err == nil {
+		t.Fatal("nil shard key must be rejected")
+	}
+}
+
+func TestFirstAcrossShards(t *testing.T) {
+	node0, recorder := openFake(t)
+	db := NewDB(node0, MySQL)
+	if err := db.RegisterShardRule(&order{}, ModShardRule("user_id", 1, 3)); err != nil {
+		t.Fatal(err)
+	}
+	columns := []string{"id", "user_id", "amount"}
+	recorder.PushRows(columns, []interface{}{int64(7), int64(0), int64(1)})
+	recorder.PushRows(columns)
+	recorder.PushRows(columns, []interface{}{int64(3), int64(2), int64(1)})
+	var o order
+	if err := db.First(context.Background(), &o, "amount > ?", 0); err != nil {
+		t.Fatal(err)
+	}
+	if o.ID != 3 || len(recorder.All()) != 3 {
+		t.Fatal("First must return the lowest primary key across shards", o)
+	}
+	if stmt := recorder.Last(); stmt.Query != "SELECT `id`, `user_id`, `amount` FROM `order_2` WHERE (amount > ?) ORDER BY `id` LIMIT 1" {
+		t.Fatal(stmt.Query)
+	}
+	recorder.PushRows(columns)
+	if err := db.First(context.Background(), &o, "amount > ?", 100); !errors.Is(err, ErrRecordNotFound) {
+		t.Fatal("expect ErrRecordNotFound", err)
+	}
+}
+
+func TestStatsAcrossNodes(t *testing.T) {
+	primary, _ := openFake(t)
+	replica, _ := openFake(t)
+	node1, _ := openFake(t)
+	primary.SetMaxOpenConns(1)
+	replica.SetMaxOpenConns(2)
+	node1.SetMaxOpenConns(4)
+	db := NewDB(primary, MySQL, WithReplicas(replica), WithNode(node1, primary))
+	stats := db.Stats()
+	if stats.MaxOpenConnections != 7 {
+		t.Fatal("pools must be summed once each", stats.MaxOpenConnections)
+	}
+	if len(stats.Nodes) != 2 || stats.Nodes[0].Replicas[0].MaxOpenConnections != 2 ||
+		stats.Nodes[1].Primary.MaxOpenConnections != 4 || len(stats.Nodes[1].Replicas) != 1 {
+		t.Fatal("per node stats", stats.Nodes)
+	}
+}
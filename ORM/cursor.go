@@ -0,0 +1,130 @@
+package ORM
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+/****************************************************************
+ * @author: Ihc
+ * @date: 2026/10/19 19:40
+ * @description: 游标,逐行流式读取查询结果
+内存中只保留当前行,适合遍历大量数据:
+	cursor, err := db.Cursor(ctx, &User{}, "age > ?", 18)
+	defer cursor.Close()
+	for cursor.Next() {
+		var u User
+		if err := cursor.Scan(&u); err != nil { ... }
+	}
+	err = cursor.Err()
+分片模型未指定分片键时依次遍历每个分片
+ ***************************************************************/
+
+// errNoRow 未成功调用Next就调用Scan
+var errNoRow = errors.New("orm: Scan called without a successful Next")
+
+// Cursor 查询结果游标,非并发安全
+type Cursor struct {
+	ctx     context.Context
+	session *Session
+	sch     *schema
+	routes  []route // routes 尚未打开的执行位置
+	where   string
+	args    []interface{}
+	rows    *sql.Rows // rows 当前执行位置的结果集
+	hasRow  bool      // hasRow 当前是否有可以Scan的行
+	err     error
+}
+
+// Cursor 查询满足条件的记录并返回游标
+// model 为模型的结构体指针,仅用于确定表与列
+func (s *Session) Cursor(ctx context.Context, model interface{}, where string, args ...interface{}) (*Cursor, error) {
+	sch, err := parseSchema(model)
+	if err != nil {
+		return nil, err
+	}
+	routes, err := s.routes(sch, s.shardKey, s.hasShardKey)
+	if err != nil {
+		return nil, err
+	}
+	c := &Cursor{
+		ctx:     ctx,
+		session: s,
+		sch:     sch,
+		routes:  routes,
+		where:   where,
+		args:    args,
+	}
+	if !c.open() {
+		return nil, c.err
+	}
+	return c, nil
+}
+
+// open 打开下一个执行位置的结果集
+func (c *Cursor) open() bool {
+	r := c.routes[0]
+	c.routes = c.routes[1:]
+	stmt := &statement{query: c.session.selectQuery(c.sch, r.table, c.where, false), args: c.args}
+	rows, err := c.session.query(c.ctx, r, stmt, false)
+	if err != nil {
+		c.err = err
+		return false
+	}
+	c.rows = rows
+	return true
+}
+
+// Next 移动到下一行,没有更多行或出错时返回false并自动关闭游标
+func (c *Cursor) Next() bool {
+	c.hasRow = false
+	for c.rows != nil {
+		if c.rows.Next() {
+			c.hasRow = true
+			return true
+		}
+		err := c.rows.Err()
+		_ = c.rows.Close()
+		c.rows = nil
+		if err != nil {
+			c.err = err
+			return false
+		}
+		if len(c.routes) == 0 || !c.open() {
+			return false
+		}
+	}
+	return false
+}
+
+// Scan 将当前行扫描到dest,dest必须是模型类型的指针
+func (c *Cursor) Scan(dest interface{}) error {
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Type() != c.sch.typ {
+		return fmt.Errorf("%w: Scan needs *%s, got %T", ErrInvalidModel, c.sch.typ, dest)
+	}
+	if !c.hasRow {
+		return errNoRow
+	}
+	return scanModel(c.ctx, c.rows, c.sch, v)
+}
+
+// Err 返回遍历过程中的错误
+func (c *Cursor) Err() error {
+	return c.err
+}
+
+// Close 关闭游标,可以重复调用
+func (c *Cursor) Close() error {
+	c.routes = nil
+	c.hasRow = false
+	if c.rows == nil {
+		return nil
+	}
+	err := c.rows.Close()
+	c.rows = nil
+	return err
+}
@@ -0,0 +1,159 @@
+package ORM
+
+import (
+	"strconv"
+	"strings"
+)
+
+/****************************************************************
+ * @author: Ihc
+ * @date: 2026/10/19 10:12
+ * @description: 数据库方言
+ORM内部统一使用?作为占位符生成SQL,执行前由方言改写为数据库支持的格式
+ ***************************************************************/
+
+// Dialect 数据库方言接口
+type Dialect interface {
+	// Name 方言名称
+	Name() string
+	// Quote 对表名,列名等标识符加引号
+	Quote(identifier string) string
+	// Placeholder 第index(从1开始)个参数的占位符
+	Placeholder(index int) string
+	// SupportsLastInsertID 驱动是否支持LastInsertId获取自增主键
+	// 不支持时使用RETURNING子句
+	SupportsLastInsertID() bool
+	// BatchInsertID 多行INSERT后LastInsertId的含义,批量插入据此回填自增主键
+	// first为true时是第一行的主键,否则是最后一行的主键;consecutive表示数据库保证同一语句的自增主键连续
+	BatchInsertID() (first, consecutive bool)
+	// MaxParams 单条语句最多绑定的参数个数,批量插入据此分批
+	MaxParams() int
+	// Upsert 返回追加在INSERT语句后的冲突更新子句
+	// conflict为冲突判断的列,update为冲突时更新的列,均已加引号;update为空时忽略冲突
+	Upsert(conflict, update []string) string
+}
+
+var (
+	// MySQL 方言
+	MySQL Dialect = mysqlDialect{}
+	// PostgreSQL 方言
+	PostgreSQL Dialect = postgresDialect{}
+	// SQLite 方言
+	SQLite Dialect = sqliteDialect{}
+)
+
+type mysqlDialect struct{}
+
+func (mysqlDialect) Name() string { return "mysql" }
+
+func (mysqlDialect) Quote(identifier string) string {
+	return "`" + strings.ReplaceAll(identifier, "`", "``") + "`"
+}
+
+func (mysqlDialect) Placeholder(int) string { return "?" }
+
+func (mysqlDialect) SupportsLastInsertID() bool { return true }
+
+// BatchInsertID innodb_autoinc_lock_mode为2(MySQL 8的默认值)时同一语句的主键可能不连续
+func (mysqlDialect) BatchInsertID() (bool, bool) { return true, false }
+
+func (mysqlDialect) MaxParams() int { return 65535 }
+
+// Upsert MySQL按主键与唯一索引判断冲突,conflict不参与生成语句
+func (mysqlDialect) Upsert(conflict, update []string) string {
+	if len(update) == 0 {
+		update = conflict[:1]
+	}
+	sets := make([]string, len(update))
+	for i, column := range update {
+		sets[i] = column + " = VALUES(" + column + ")"
+	}
+	return " ON DUPLICATE KEY UPDATE " + strings.Join(sets, ", ")
+}
+
+type postgresDialect struct{}
+
+func (postgresDialect) Name() string { return "postgres" }
+
+func (postgresDialect) Quote(identifier string) string {
+	return `"` + strings.ReplaceAll(identifier, `"`, `""`) + `"`
+}
+
+func (postgresDialect) Placeholder(index int) string { return "$" + strconv.Itoa(index) }
+
+func (postgresDialect) SupportsLastInsertID() bool { return false }
+
+// BatchInsertID 不使用LastInsertId,批量插入通过RETURNING回填
+func (postgresDialect) BatchInsertID() (bool, bool) { return false, false }
+
+func (postgresDialect) MaxParams() int { return 65535 }
+
+func (postgresDialect) Upsert(conflict, update []string) string {
+	return onConflict(conflict, update)
+}
+
+type sqliteDialect struct{}
+
+func (sqliteDialect) Name() string { return "sqlite3" }
+
+func (sqliteDialect) Quote(identifier string) string {
+	return `"` + strings.ReplaceAll(identifier, `"`, `""`) + `"`
+}
+
+func (sqliteDialect) Placeholder(int) string { return "?" }
+
+func (sqliteDialect) SupportsLastInsertID() bool { return true }
+
+// BatchInsertID 同一语句中的行依次取最大rowid加1
+func (sqliteDialect) BatchInsertID() (bool, bool) { return false, true }
+
+// MaxParams SQLite 3.32之前SQLITE_MAX_VARIABLE_NUMBER默认为999
+func (sqliteDialect) MaxParams() int { return 999 }
+
+func (sqliteDialect) Upsert(conflict, update []string) string {
+	return onConflict(conflict, update)
+}
+
+// onConflict PostgreSQL与SQLite的冲突更新子句
+func onConflict(conflict, update []string) string {
+	clause := " ON CONFLICT (" + strings.Join(conflict, ", ") + ")"
+	if len(update) == 0 {
+		return clause + " DO NOTHING"
+	}
+	sets := make([]string, len(update))
+	for i, column := range update {
+		sets[i] = column + " = EXCLUDED." + column
+	}
+	return clause + " DO UPDATE SET " + strings.Join(sets, ", ")
+}
+
+// rebind 将语句中的?占位符改写为方言的占位符
+// 引号内的?不做替换
+func rebind(dialect Dialect, query string) string {
+	if dialect.Placeholder(1) == "?" {
+		return query
+	}
+	var (
+		b     strings.Builder
+		quote byte
+		index int
+	)
+	b.Grow(len(query) + 8)
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '?':
+			index++
+			b.WriteString(dialect.Placeholder(index))
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
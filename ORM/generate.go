@@ -0,0 +1,118 @@
+package ORM
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+/****************************************************************
+ * @author: Ihc
+ * @date: 2026/10/19 13:30
+ * @description: 代码生成支持
+ormgen(见ormgen目录)为模型生成扫描方法,列名常量和查询方法,
+生成的代码依赖此文件中的接口,热点查询不再经过反射
+生成的代码在init中用 CheckColumns 校验列与反射解析的结果一致,不一致时panic
+	//go:generate go run preseus/ORM/ormgen -type User
+ ***************************************************************/
+
+// RowScanner *sql.Rows与*sql.Row的公共方法
+type RowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// ModelScanner 模型按列顺序扫描自身
+// 模型实现此接口时 Find 与 First 不再使用反射扫描,通常由ormgen生成
+type ModelScanner interface {
+	// ScanRow 按模型全部列的定义顺序扫描一行
+	ScanRow(row RowScanner) error
+}
+
+// Querier DB,Tx与Session的查询方法,供生成的代码使用
+type Querier interface {
+	// Dialect 返回数据库方言
+	Dialect() Dialect
+	// Query 执行原生查询,占位符统一使用?
+	Query(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	// QueryByColumn 按模型的分片规则执行以column = value为条件的查询
+	// build按物理表名构造语句;each处理每个执行位置的结果,返回false时不再查询后续分片
+	QueryByColumn(ctx context.Context, model interface{}, column string, value interface{},
+		build func(dialect Dialect, table string) string, each func(rows *sql.Rows) (bool, error)) error
+}
+
+// QueryByColumn 见 Querier.QueryByColumn
+// column为分片键列时只查询value所在的分片,否则查询 ShardKey 指定的分片,未指定时按分片顺序查询全部分片
+func (s *Session) QueryByColumn(ctx context.Context, model interface{}, column string, value interface{},
+	build func(dialect Dialect, table string) string, each func(rows *sql.Rows) (bool, error)) error {
+	sch, err := parseSchema(model)
+	if err != nil {
+		return err
+	}
+	key, hasKey := s.shardKey, s.hasShardKey
+	if rule, ok := s.db.shardRules.Load(sch.typ); ok && rule.(*ShardRule).Column == column {
+		key, hasKey = value, true
+	}
+	routes, err := s.routes(sch, key, hasKey)
+	if err != nil {
+		return err
+	}
+	for _, r := range routes {
+		rows, err := s.query(ctx, r, &statement{query: build(s.db.dialect, r.table), args: []interface{}{value}}, false)
+		if err != nil {
+			return err
+		}
+		more, err := each(rows)
+		_ = rows.Close()
+		if err != nil || !more {
+			return err
+		}
+	}
+	return nil
+}
+
+// CheckColumns 校验生成代码中模型的列与反射解析的列一致(包括顺序)
+// 生成的ScanRow按列顺序扫描,不一致时会把列扫描到错误的字段
+func CheckColumns(model interface{}, columns []string) error {
+	sch, err := parseSchema(model)
+	if err != nil {
+		return err
+	}
+	names := sch.columnNames()
+	if strings.Join(names, ",") != strings.Join(columns, ",") {
+		return fmt.Errorf("orm: generated columns %v of %s do not match %v, run go generate again", columns, sch.typ, names)
+	}
+	return nil
+}
+
+// sqlCacheKey SQLCache 的键
+type sqlCacheKey struct {
+	dialect string
+	table   string
+}
+
+// SQLCache 按方言与物理表名缓存生成的语句
+// 生成的代码对每个查询声明一个 SQLCache,语句只在每种方言与表第一次使用时构造
+type SQLCache struct {
+	statements sync.Map // statements sqlCacheKey -> 语句
+}
+
+// Get 返回方言与表对应的语句,不存在时调用build构造
+func (c *SQLCache) Get(dialect Dialect, table string, build func(dialect Dialect, table string) string) string {
+	key := sqlCacheKey{dialect: dialect.Name(), table: table}
+	if query, ok := c.statements.Load(key); ok {
+		return query.(string)
+	}
+	query, _ := c.statements.LoadOrStore(key, build(dialect, table))
+	return query.(string)
+}
+
+// QuoteColumns 对列名加引号并以逗号连接
+func QuoteColumns(dialect Dialect, columns []string) string {
+	quoted := make([]string, len(columns))
+	for i, column := range columns {
+		quoted[i] = dialect.Quote(column)
+	}
+	return strings.Join(quoted, ", ")
+}
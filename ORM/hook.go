@@ -0,0 +1,47 @@
+package ORM
+
+import "context"
+
+/****************************************************************
+ * @author: Ihc
+ * @date: 2026/10/19 10:20
+ * @description: 模型生命周期钩子
+模型(指针)实现对应接口即可,ORM在执行时通过类型断言发现钩子
+Before钩子返回错误会中止操作;After钩子返回错误会作为操作结果返回,
+需要原子性时请在事务中执行
+ ***************************************************************/
+
+// BeforeCreateHook 插入前调用
+type BeforeCreateHook interface {
+	BeforeCreate(ctx context.Context) error
+}
+
+// AfterCreateHook 插入后调用
+type AfterCreateHook interface {
+	AfterCreate(ctx context.Context) error
+}
+
+// BeforeUpdateHook 更新前调用
+type BeforeUpdateHook interface {
+	BeforeUpdate(ctx context.Context) error
+}
+
+// AfterUpdateHook 更新后调用
+type AfterUpdateHook interface {
+	AfterUpdate(ctx context.Context) error
+}
+
+// BeforeDeleteHook 删除(包括软删除)前调用
+type BeforeDeleteHook interface {
+	BeforeDelete(ctx context.Context) error
+}
+
+// AfterDeleteHook 删除(包括软删除)后调用
+type AfterDeleteHook interface {
+	AfterDelete(ctx context.Context) error
+}
+
+// AfterFindHook 查询并填充模型后调用
+type AfterFindHook interface {
+	AfterFind(ctx context.Context) error
+}
@@ -0,0 +1,28 @@
+package example
+
+import stdtime "time"
+
+/****************************************************************
+ * @author: Ihc
+ * @date: 2026/10/20 13:10
+ * @description: 覆盖ormgen与ORM包字段规则的示例模型:
+	指针嵌入的结构体,打了标签的匿名字段,以别名导入的time包
+ ***************************************************************/
+
+// Audit 审计字段
+type Audit struct {
+	CreatedBy string
+	DeletedAt *stdtime.Time
+}
+
+// Label 标签
+type Label string
+
+// Event 事件
+type Event struct {
+	*Audit
+	stdtime.Time `orm:"happened_at"`
+	Label        `orm:"label"`
+	ID           int64  `orm:"id,auto"`
+	Kind         string `orm:"kind,index"`
+}
@@ -0,0 +1,27 @@
+package example
+
+import "time"
+
+/****************************************************************
+ * @author: Ihc
+ * @date: 2026/10/19 14:20
+ * @description: ormgen生成代码的示例模型,同时用于基准测试
+ ***************************************************************/
+
+//go:generate go run preseus/ORM/ormgen -type User,Event
+
+// Model 公共字段
+type Model struct {
+	ID        int64 `orm:"id,auto"`
+	CreatedAt time.Time
+	DeletedAt *time.Time
+}
+
+// User 用户
+type User struct {
+	Model
+	Name    string
+	Email   string `orm:"email,unique"`
+	Age     int    `orm:"age,index"`
+	Version int64  `orm:"version,version"`
+}
@@ -0,0 +1,243 @@
+// Code generated by ormgen. DO NOT EDIT.
+
+package example
+
+import (
+	"context"
+	"database/sql"
+
+	"preseus/ORM"
+)
+
+// User 的列名
+const (
+	UserColumnID        = "id"
+	UserColumnCreatedAt = "created_at"
+	UserColumnDeletedAt = "deleted_at"
+	UserColumnName      = "name"
+	UserColumnEmail     = "email"
+	UserColumnAge       = "age"
+	UserColumnVersion   = "version"
+)
+
+// UserTable User 的表名
+const UserTable = "user"
+
+// UserColumns User 映射的全部列,顺序与 ScanRow 一致
+var UserColumns = []string{
+	UserColumnID,
+	UserColumnCreatedAt,
+	UserColumnDeletedAt,
+	UserColumnName,
+	UserColumnEmail,
+	UserColumnAge,
+	UserColumnVersion,
+}
+
+func init() {
+	if err := ORM.CheckColumns(&User{}, UserColumns); err != nil {
+		panic(err)
+	}
+}
+
+// ScanRow 按 UserColumns 的顺序扫描一行,实现 ORM.ModelScanner
+func (m *User) ScanRow(row ORM.RowScanner) error {
+	return row.Scan(
+		&m.Model.ID,
+		&m.Model.CreatedAt,
+		&m.Model.DeletedAt,
+		&m.Name,
+		&m.Email,
+		&m.Age,
+		&m.Version,
+	)
+}
+
+var findUserByIDSQL ORM.SQLCache
+
+// FindUserByID 按id查询User
+// 未查询到时返回 ORM.ErrRecordNotFound;分片模型按分片顺序查询,返回第一个查询到的记录
+func FindUserByID(ctx context.Context, q ORM.Querier, id int64) (*User, error) {
+	build := func(d ORM.Dialect, table string) string {
+		return findUserByIDSQL.Get(d, table, func(d ORM.Dialect, table string) string {
+			return "SELECT " + ORM.QuoteColumns(d, UserColumns) + " FROM " + d.Quote(table) +
+				" WHERE " + d.Quote(UserColumnID) + " = ?" +
+				" AND " + d.Quote(UserColumnDeletedAt) + " IS NULL LIMIT 1"
+		})
+	}
+	var m *User
+	err := q.QueryByColumn(ctx, (*User)(nil), UserColumnID, id, build, func(rows *sql.Rows) (bool, error) {
+		if !rows.Next() {
+			return true, rows.Err()
+		}
+		m = new(User)
+		return false, m.ScanRow(rows)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if m == nil {
+		return nil, ORM.ErrRecordNotFound
+	}
+	return m, nil
+}
+
+var findUserByEmailSQL ORM.SQLCache
+
+// FindUserByEmail 按email查询User
+// 未查询到时返回 ORM.ErrRecordNotFound;分片模型按分片顺序查询,返回第一个查询到的记录
+func FindUserByEmail(ctx context.Context, q ORM.Querier, email string) (*User, error) {
+	build := func(d ORM.Dialect, table string) string {
+		return findUserByEmailSQL.Get(d, table, func(d ORM.Dialect, table string) string {
+			return "SELECT " + ORM.QuoteColumns(d, UserColumns) + " FROM " + d.Quote(table) +
+				" WHERE " + d.Quote(UserColumnEmail) + " = ?" +
+				" AND " + d.Quote(UserColumnDeletedAt) + " IS NULL LIMIT 1"
+		})
+	}
+	var m *User
+	err := q.QueryByColumn(ctx, (*User)(nil), UserColumnEmail, email, build, func(rows *sql.Rows) (bool, error) {
+		if !rows.Next() {
+			return true, rows.Err()
+		}
+		m = new(User)
+		return false, m.ScanRow(rows)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if m == nil {
+		return nil, ORM.ErrRecordNotFound
+	}
+	return m, nil
+}
+
+var findUserByAgeSQL ORM.SQLCache
+
+// FindUserByAge 按age查询User
+// 分片模型按分片顺序查询,结果按分片顺序合并
+func FindUserByAge(ctx context.Context, q ORM.Querier, age int) ([]*User, error) {
+	build := func(d ORM.Dialect, table string) string {
+		return findUserByAgeSQL.Get(d, table, func(d ORM.Dialect, table string) string {
+			return "SELECT " + ORM.QuoteColumns(d, UserColumns) + " FROM " + d.Quote(table) +
+				" WHERE " + d.Quote(UserColumnAge) + " = ?" +
+				" AND " + d.Quote(UserColumnDeletedAt) + " IS NULL"
+		})
+	}
+	var models []*User
+	err := q.QueryByColumn(ctx, (*User)(nil), UserColumnAge, age, build, func(rows *sql.Rows) (bool, error) {
+		for rows.Next() {
+			m := new(User)
+			if err := m.ScanRow(rows); err != nil {
+				return false, err
+			}
+			models = append(models, m)
+		}
+		return true, rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return models, nil
+}
+
+// Event 的列名
+const (
+	EventColumnCreatedBy = "created_by"
+	EventColumnDeletedAt = "deleted_at"
+	EventColumnTime      = "happened_at"
+	EventColumnLabel     = "label"
+	EventColumnID        = "id"
+	EventColumnKind      = "kind"
+)
+
+// EventTable Event 的表名
+const EventTable = "event"
+
+// EventColumns Event 映射的全部列,顺序与 ScanRow 一致
+var EventColumns = []string{
+	EventColumnCreatedBy,
+	EventColumnDeletedAt,
+	EventColumnTime,
+	EventColumnLabel,
+	EventColumnID,
+	EventColumnKind,
+}
+
+func init() {
+	if err := ORM.CheckColumns(&Event{}, EventColumns); err != nil {
+		panic(err)
+	}
+}
+
+// ScanRow 按 EventColumns 的顺序扫描一行,实现 ORM.ModelScanner
+func (m *Event) ScanRow(row ORM.RowScanner) error {
+	if m.Audit == nil {
+		m.Audit = new(Audit)
+	}
+	return row.Scan(
+		&m.Audit.CreatedBy,
+		&m.Audit.DeletedAt,
+		&m.Time,
+		&m.Label,
+		&m.ID,
+		&m.Kind,
+	)
+}
+
+var findEventByIDSQL ORM.SQLCache
+
+// FindEventByID 按id查询Event
+// 未查询到时返回 ORM.ErrRecordNotFound;分片模型按分片顺序查询,返回第一个查询到的记录
+func FindEventByID(ctx context.Context, q ORM.Querier, id int64) (*Event, error) {
+	build := func(d ORM.Dialect, table string) string {
+		return findEventByIDSQL.Get(d, table, func(d ORM.Dialect, table string) string {
+			return "SELECT " + ORM.QuoteColumns(d, EventColumns) + " FROM " + d.Quote(table) +
+				" WHERE " + d.Quote(EventColumnID) + " = ?" +
+				" AND " + d.Quote(EventColumnDeletedAt) + " IS NULL LIMIT 1"
+		})
+	}
+	var m *Event
+	err := q.QueryByColumn(ctx, (*Event)(nil), EventColumnID, id, build, func(rows *sql.Rows) (bool, error) {
+		if !rows.Next() {
+			return true, rows.Err()
+		}
+		m = new(Event)
+		return false, m.ScanRow(rows)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if m == nil {
+		return nil, ORM.ErrRecordNotFound
+	}
+	return m, nil
+}
+
+var findEventByKindSQL ORM.SQLCache
+
+// FindEventByKind 按kind查询Event
+// 分片模型按分片顺序查询,结果按分片顺序合并
+func FindEventByKind(ctx context.Context, q ORM.Querier, kind string) ([]*Event, error) {
+	build := func(d ORM.Dialect, table string) string {
+		return findEventByKindSQL.Get(d, table, func(d ORM.Dialect, table string) string {
+			return "SELECT " + ORM.QuoteColumns(d, EventColumns) + " FROM " + d.Quote(table) +
+				" WHERE " + d.Quote(EventColumnKind) + " = ?" +
+				" AND " + d.Quote(EventColumnDeletedAt) + " IS NULL"
+		})
+	}
+	var models []*Event
+	err := q.QueryByColumn(ctx, (*Event)(nil), EventColumnKind, kind, build, func(rows *sql.Rows) (bool, error) {
+		for rows.Next() {
+			m := new(Event)
+			if err := m.ScanRow(rows); err != nil {
+				return false, err
+			}
+			models = append(models, m)
+		}
+		return true, rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return models, nil
+}
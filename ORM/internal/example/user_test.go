@@ -0,0 +1,193 @@
+package example
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"preseus/ORM"
+	"preseus/ORM/internal/fakedriver"
+)
+
+/****************************************************************
+ * @author: Ihc
+ * @date: 2026/10/19 15:05
+ * @description: 生成代码的测试,以及生成扫描与反射扫描的基准测试
+go test -bench . -benchmem preseus/ORM/internal/example
+ ***************************************************************/
+
+// reflectUser 与User字段相同但没有ScanRow,查询时走反射扫描
+type reflectUser User
+
+var userColumns = []string{"id", "created_at", "deleted_at", "name", "email", "age", "version"}
+
+func userRow(id int64) []interface{} {
+	return []interface{}{id, time.Unix(id, 0), nil, "ihc", "ihc@example.com", int64(30), int64(1)}
+}
+
+func newTestDB(tb testing.TB) (*ORM.DB, *fakedriver.Recorder) {
+	sqlDB, recorder, err := fakedriver.Open()
+	if err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(func() { _ = sqlDB.Close() })
+	return ORM.NewDB(sqlDB, ORM.PostgreSQL), recorder
+}
+
+func TestGeneratedFinder(t *testing.T) {
+	db, recorder := newTestDB(t)
+	recorder.PushRows(userColumns, userRow(1), userRow(2))
+	users, err := FindUserByAge(context.Background(), db, 30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `SELECT "id", "created_at", "deleted_at", "name", "email", "age", "version" FROM "user" WHERE "age" = $1 AND "deleted_at" IS NULL`
+	if stmt := recorder.Last(); stmt.Query != want {
+		t.Fatal(stmt.Query)
+	}
+	if len(users) != 2 || users[1].ID != 2 || users[1].Email != "ihc@example.com" || users[1].DeletedAt != nil {
+		t.Fatal("scan error")
+	}
+
+	if _, err := FindUserByEmail(context.Background(), db, "nobody@example.com"); !errors.Is(err, ORM.ErrRecordNotFound) {
+		t.Fatal("expect ErrRecordNotFound", err)
+	}
+	if stmt := recorder.Last(); stmt.Query != `SELECT "id", "created_at", "deleted_at", "name", "email", "age", "version" FROM "user" WHERE "email" = $1 AND "deleted_at" IS NULL LIMIT 1` {
+		t.Fatal(stmt.Query)
+	}
+}
+
+// TestFindUsesScanRow ORM的Find使用生成的ScanRow,结果与反射扫描一致
+func TestFindUsesScanRow(t *testing.T) {
+	db, recorder := newTestDB(t)
+	recorder.PushRows(userColumns, userRow(7))
+	recorder.PushRows(userColumns, userRow(7))
+	var generated []User
+	if err := db.Find(context.Background(), &generated, ""); err != nil {
+		t.Fatal(err)
+	}
+	var reflected []reflectUser
+	if err := db.Find(context.Background(), &reflected, ""); err != nil {
+		t.Fatal(err)
+	}
+	if len(generated) != 1 || len(reflected) != 1 || User(reflected[0]) != generated[0] {
+		t.Fatal("generated and reflective scanning differ")
+	}
+}
+
+const benchRows = 100
+
+func newBenchDB(b *testing.B) *ORM.DB {
+	db, recorder := newTestDB(b)
+	rows := make([][]interface{}, benchRows)
+	for i := range rows {
+		rows[i] = userRow(int64(i + 1))
+	}
+	recorder.Repeat(userColumns, rows...)
+	return db
+}
+
+func BenchmarkFindReflect(b *testing.B) {
+	db := newBenchDB(b)
+	ctx := context.Background()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var users []*reflectUser
+		if err := db.Find(ctx, &users, "age = ?", 30); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkFindScanRow(b *testing.B) {
+	db := newBenchDB(b)
+	ctx := context.Background()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var users []*User
+		if err := db.Find(ctx, &users, "age = ?", 30); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkFindGenerated(b *testing.B) {
+	db := newBenchDB(b)
+	ctx := context.Background()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := FindUserByAge(ctx, db, 30); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// reflectEvent 与Event字段相同但没有ScanRow
+type reflectEvent Event
+
+// TestGeneratedFieldRules 指针嵌入,打了标签的匿名字段与别名导入的time包,生成扫描与反射扫描一致
+func TestGeneratedFieldRules(t *testing.T) {
+	db, recorder := newTestDB(t)
+	columns := []string{"created_by", "deleted_at", "happened_at", "label", "id", "kind"}
+	row := []interface{}{"ihc", nil, time.Unix(100, 0), "ops", int64(3), "deploy"}
+	recorder.PushRows(columns, row)
+	recorder.PushRows(columns, row)
+	events, err := FindEventByKind(context.Background(), db, "deploy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `SELECT "created_by", "deleted_at", "happened_at", "label", "id", "kind" FROM "event" WHERE "kind" = $1 AND "deleted_at" IS NULL`
+	if stmt := recorder.Last(); stmt.Query != want {
+		t.Fatal(stmt.Query)
+	}
+	var reflected []reflectEvent
+	if err := db.Find(context.Background(), &reflected, ""); err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || len(reflected) != 1 || events[0].CreatedBy != "ihc" || events[0].Label != "ops" || events[0].ID != 3 ||
+		*events[0].Audit != *reflected[0].Audit || !events[0].Time.Equal(reflected[0].Time) || Event(reflected[0]).Kind != events[0].Kind {
+		t.Fatal("generated and reflective scanning differ")
+	}
+	if err := ORM.CheckColumns(&Event{}, UserColumns); err == nil {
+		t.Fatal("mismatched columns must be reported")
+	}
+}
+
+func TestGeneratedFinderSharded(t *testing.T) {
+	db, recorder := newTestDB(t)
+	if err := db.RegisterShardRule(&User{}, ORM.ModShardRule("id", 1, 2)); err != nil {
+		t.Fatal(err)
+	}
+	// 按分片键查询只访问所在的分片
+	recorder.PushRows(userColumns, userRow(3))
+	user, err := FindUserByID(context.Background(), db, 3)
+	if err != nil || user.ID != 3 {
+		t.Fatal(user, err)
+	}
+	if stmt := recorder.Last(); !strings.Contains(stmt.Query, `FROM "user_1" WHERE "id" = $1`) || len(recorder.All()) != 1 {
+		t.Fatal(stmt.Query)
+	}
+	// 其他列查询全部分片
+	recorder.PushRows(userColumns, userRow(2))
+	recorder.PushRows(userColumns, userRow(1), userRow(3))
+	users, err := FindUserByAge(context.Background(), db, 30)
+	if err != nil || len(users) != 3 || users[0].ID != 2 || users[2].ID != 3 {
+		t.Fatal(users, err)
+	}
+	if stmt := recorder.Last(); !strings.Contains(stmt.Query, `FROM "user_1"`) {
+		t.Fatal(stmt.Query)
+	}
+	// 单条记录查询到即停止
+	recorder.PushRows(userColumns, userRow(4))
+	if _, err := FindUserByEmail(context.Background(), db, "ihc@example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if stmt := recorder.Last(); !strings.Contains(stmt.Query, `FROM "user_0"`) {
+		t.Fatal("must stop at the first shard with a match", stmt.Query)
+	}
+}
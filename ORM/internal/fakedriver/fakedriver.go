@@ -0,0 +1,266 @@
+package fakedriver
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+/****************************************************************
+ * @author: Ihc
+ * @date: 2026/10/19 10:40
+ * @description: 测试用的database/sql驱动
+记录执行的语句,返回预先设置的结果,不解析SQL
+ ***************************************************************/
+
+var (
+	recorders sync.Map // recorders dsn -> *Recorder
+	sequence  int64
+)
+
+func init() {
+	sql.Register("ormfake", fakeDriver{})
+}
+
+// Statement 执行过的语句
+type Statement struct {
+	Query string
+	Args  []interface{}
+}
+
+// result 预设的查询结果
+type result struct {
+	columns []string
+	rows    [][]interface{}
+}
+
+// Recorder 记录语句与预设结果
+type Recorder struct {
+	mu         sync.Mutex
+	statements []Statement
+	affected   []int64   // affected 依次返回的影响行数,耗尽后返回1
+	results    []*result // results 依次返回的查询结果,耗尽后返回 repeat 或空结果
+	repeat     *result   // repeat 每次查询都返回的结果
+	discard    bool      // discard 为true时不记录语句
+	lastIDs    []int64   // lastIDs 依次返回的LastInsertId,耗尽后返回递增的序号
+	errs       []error   // errs 依次返回的执行错误,nil表示正常执行
+	lastID     int64
+	commits    int
+	rollbacks  int
+}
+
+// Open 创建连接到新记录器的*sql.DB
+func Open() (*sql.DB, *Recorder, error) {
+	dsn := "fake" + strconv.FormatInt(atomic.AddInt64(&sequence, 1), 10)
+	recorder := &Recorder{}
+	recorders.Store(dsn, recorder)
+	sqlDB, err := sql.Open("ormfake", dsn)
+	if err != nil {
+		recorders.Delete(dsn)
+		return nil, nil, err
+	}
+	return sqlDB, recorder, nil
+}
+
+func (r *Recorder) record(query string, args []driver.NamedValue) {
+	if r.discard {
+		return
+	}
+	values := make([]interface{}, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+	r.statements = append(r.statements, Statement{Query: query, Args: values})
+}
+
+// PushAffected 设置后续Exec返回的影响行数
+func (r *Recorder) PushAffected(n ...int64) {
+	r.mu.Lock()
+	r.affected = append(r.affected, n...)
+	r.mu.Unlock()
+}
+
+// PushLastInsertID 设置后续Exec返回的LastInsertId
+func (r *Recorder) PushLastInsertID(ids ...int64) {
+	r.mu.Lock()
+	r.lastIDs = append(r.lastIDs, ids...)
+	r.mu.Unlock()
+}
+
+// PushErrors 设置后续Exec与Query依次返回的错误,nil表示正常执行
+func (r *Recorder) PushErrors(errs ...error) {
+	r.mu.Lock()
+	r.errs = append(r.errs, errs...)
+	r.mu.Unlock()
+}
+
+// popError 取出下一个预设的错误,调用方持有锁
+func (r *Recorder) popError() error {
+	if len(r.errs) == 0 {
+		return nil
+	}
+	err := r.errs[0]
+	r.errs = r.errs[1:]
+	return err
+}
+
+// PushRows 设置后续Query返回的结果
+func (r *Recorder) PushRows(columns []string, rows ...[]interface{}) {
+	r.mu.Lock()
+	r.results = append(r.results, &result{columns: columns, rows: rows})
+	r.mu.Unlock()
+}
+
+// Repeat 设置每次Query都返回的结果,并不再记录语句(用于基准测试)
+func (r *Recorder) Repeat(columns []string, rows ...[]interface{}) {
+	r.mu.Lock()
+	r.repeat = &result{columns: columns, rows: rows}
+	r.discard = true
+	r.mu.Unlock()
+}
+
+// Last 最后执行的语句
+func (r *Recorder) Last() Statement {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.statements) == 0 {
+		return Statement{}
+	}
+	return r.statements[len(r.statements)-1]
+}
+
+// All 执行过的全部语句
+func (r *Recorder) All() []Statement {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Statement(nil), r.statements...)
+}
+
+// Commits 提交事务的次数
+func (r *Recorder) Commits() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.commits
+}
+
+// Rollbacks 回滚事务的次数
+func (r *Recorder) Rollbacks() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rollbacks
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(dsn string) (driver.Conn, error) {
+	recorder, ok := recorders.Load(dsn)
+	if !ok {
+		return nil, fmt.Errorf("fakedriver: unknown dsn %q", dsn)
+	}
+	return &fakeConn{recorder: recorder.(*Recorder)}, nil
+}
+
+type fakeConn struct {
+	recorder *Recorder
+}
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) {
+	return nil, driver.ErrSkip
+}
+
+func (c *fakeConn) Close() error { return nil }
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return &fakeTx{recorder: c.recorder}, nil
+}
+
+func (c *fakeConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	r := c.recorder
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.record(query, args)
+	if err := r.popError(); err != nil {
+		return nil, err
+	}
+	affected := int64(1)
+	if len(r.affected) > 0 {
+		affected, r.affected = r.affected[0], r.affected[1:]
+	}
+	r.lastID++
+	lastID := r.lastID
+	if len(r.lastIDs) > 0 {
+		lastID, r.lastIDs = r.lastIDs[0], r.lastIDs[1:]
+	}
+	return fakeResult{lastID: lastID, affected: affected}, nil
+}
+
+func (c *fakeConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	r := c.recorder
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.record(query, args)
+	if err := r.popError(); err != nil {
+		return nil, err
+	}
+	res := r.repeat
+	if len(r.results) > 0 {
+		res, r.results = r.results[0], r.results[1:]
+	}
+	if res == nil {
+		res = &result{}
+	}
+	return &fakeRows{result: res}, nil
+}
+
+type fakeTx struct {
+	recorder *Recorder
+}
+
+func (tx *fakeTx) Commit() error {
+	tx.recorder.mu.Lock()
+	tx.recorder.commits++
+	tx.recorder.mu.Unlock()
+	return nil
+}
+
+func (tx *fakeTx) Rollback() error {
+	tx.recorder.mu.Lock()
+	tx.recorder.rollbacks++
+	tx.recorder.mu.Unlock()
+	return nil
+}
+
+type fakeResult struct {
+	lastID   int64
+	affected int64
+}
+
+func (r fakeResult) LastInsertId() (int64, error) { return r.lastID, nil }
+
+func (r fakeResult) RowsAffected() (int64, error) { return r.affected, nil }
+
+type fakeRows struct {
+	result *result
+	pos    int
+}
+
+func (r *fakeRows) Columns() []string { return r.result.columns }
+
+func (r *fakeRows) Close() error { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.pos >= len(r.result.rows) {
+		return io.EOF
+	}
+	row := r.result.rows[r.pos]
+	r.pos++
+	for i := range dest {
+		dest[i] = row[i]
+	}
+	return nil
+}
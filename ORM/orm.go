@@ -1,7 +1,745 @@
 package ORM
 
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
 /****************************************************************
  * @author: Ihc
  * @date: 2022/4/19 22:52
- * @description:
+ * @description: 对象关系映射
+基于database/sql,通过反射将结构体映射为表的行
+	1.生命周期钩子(见hook.go)
+	2.软删除: 模型含DeletedAt字段时,删除变为更新删除时间,查询自动过滤已删除行
+	3.乐观锁: 模型含version列时,更新与删除校验版本号,并发修改返回 ErrStaleObject
+	4.语句追踪与慢查询日志(见trace.go)
+	5.读写分离与分库分表(见cluster.go)
+	6.批量插入,插入或更新(见batch.go)与游标流式读取(见cursor.go)
  ***************************************************************/
+
+var (
+	// ErrInvalidModel 模型不是结构体指针
+	ErrInvalidModel = errors.New("orm: model must be a pointer to struct")
+	// ErrMissingPrimaryKey 模型没有主键或主键为零值
+	ErrMissingPrimaryKey = errors.New("orm: model has no primary key value")
+	// ErrRecordNotFound 未查询到记录
+	ErrRecordNotFound = errors.New("orm: record not found")
+)
+
+// ErrStaleObject 乐观锁冲突
+// 更新或删除时数据库中的版本号与模型中的不一致(已被其他人修改或删除)
+type ErrStaleObject struct {
+	Table      string      // Table 表名
+	PrimaryKey interface{} // PrimaryKey 主键值
+	Version    int64       // Version 模型持有的版本号
+}
+
+func (e *ErrStaleObject) Error() string {
+	return fmt.Sprintf("orm: stale object %s(%v) at version %d", e.Table, e.PrimaryKey, e.Version)
+}
+
+// Option 用于设置DB的初始化选项
+type Option func(options *Options)
+
+// Options DB初始化选项
+type Options struct {
+	nowFunc       func() time.Time // nowFunc 软删除时间的时间来源
+	queryHooks    []QueryHook      // queryHooks 语句执行钩子
+	slowThreshold time.Duration    // slowThreshold 慢查询阈值,为0时不记录慢查询
+	slowPrinter   Printer          // slowPrinter 慢查询日志输出
+	replicas      []*sql.DB        // replicas 0号节点的从库
+	nodes         []*node          // nodes 1号起的分库节点
+	consecutiveID bool             // consecutiveID 数据库保证多行INSERT的自增主键连续
+}
+
+// WithNowFunc 指定软删除使用的时间来源
+func WithNowFunc(nowFunc func() time.Time) Option {
+	return func(options *Options) {
+		options.nowFunc = nowFunc
+	}
+}
+
+// executor *sql.DB与*sql.Tx的公共方法
+type executor interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// statement 待执行的语句
+type statement struct {
+	query     string
+	args      []interface{}
+	sensitive []bool // sensitive 与args一一对应,为true的参数在日志与追踪中脱敏;全部不敏感时为nil
+}
+
+// bind 追加参数,sensitive为true时标记为敏感
+func (st *statement) bind(arg interface{}, sensitive bool) {
+	if sensitive && st.sensitive == nil {
+		st.sensitive = make([]bool, len(st.args), cap(st.args))
+	}
+	st.args = append(st.args, arg)
+	if st.sensitive != nil {
+		st.sensitive = append(st.sensitive, sensitive)
+	}
+}
+
+// bindArgs 追加不敏感的参数
+func (st *statement) bindArgs(args []interface{}) {
+	for _, arg := range args {
+		st.bind(arg, false)
+	}
+}
+
+// Session 会话,执行模型的增删改查
+// DB与Tx均内嵌Session
+type Session struct {
+	db          *DB         // db 所属的DB
+	tx          *sql.Tx     // tx 事务,非事务会话为nil
+	txNode      *node       // txNode 事务所在的节点
+	unscoped    bool        // unscoped 为true时忽略软删除
+	primary     bool        // primary 为true时读操作也使用主库
+	shardKey    interface{} // shardKey 查询使用的分片键
+	hasShardKey bool        // hasShardKey 是否指定了分片键
+}
+
+// DB 数据库
+type DB struct {
+	Session
+	nodes      []*node   // nodes 数据库节点,0号节点的主库为NewDB传入的数据库
+	dialect    Dialect   // dialect 数据库方言
+	options    Options   // options 初始化选项
+	counters   *counters // counters 语句计数
+	shardRules sync.Map  // shardRules 模型类型 -> *ShardRule
+}
+
+// Tx 事务
+type Tx struct {
+	Session
+}
+
+// NewDB 使用已打开的*sql.DB创建DB
+func NewDB(sqlDB *sql.DB, dialect Dialect, opts ...Option) *DB {
+	options := Options{
+		nowFunc: time.Now,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	db := &DB{
+		nodes:    append([]*node{{primary: sqlDB, replicas: options.replicas}}, options.nodes...),
+		dialect:  dialect,
+		options:  options,
+		counters: &counters{},
+	}
+	db.Session = Session{db: db}
+	return db
+}
+
+// Open 打开数据库并创建DB
+func Open(driverName, dataSourceName string, dialect Dialect, opts ...Option) (*DB, error) {
+	sqlDB, err := sql.Open(driverName, dataSourceName)
+	if err != nil {
+		return nil, err
+	}
+	return NewDB(sqlDB, dialect, opts...), nil
+}
+
+// SQLDB 返回0号节点主库的*sql.DB
+func (db *DB) SQLDB() *sql.DB {
+	return db.nodes[0].primary
+}
+
+// Close 关闭全部节点的主库与从库
+func (db *DB) Close() error {
+	var firstErr error
+	for _, n := range db.nodes {
+		for _, sqlDB := range append([]*sql.DB{n.primary}, n.replicas...) {
+			if err := sqlDB.Close(); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
+
+// Begin 在0号节点的主库上开启事务
+func (db *DB) Begin(ctx context.Context, opts *sql.TxOptions) (*Tx, error) {
+	tx, err := db.nodes[0].primary.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &Tx{Session: Session{db: db, tx: tx, txNode: db.nodes[0]}}, nil
+}
+
+// Transaction 在事务中执行fn
+// fn返回错误或panic时回滚,否则提交
+func (db *DB) Transaction(ctx context.Context, fn func(tx *Tx) error) (err error) {
+	tx, err := db.Begin(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			_ = tx.Rollback()
+			panic(r)
+		}
+	}()
+	if err = fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// Commit 提交事务
+func (tx *Tx) Commit() error {
+	return tx.tx.Commit()
+}
+
+// Rollback 回滚事务
+func (tx *Tx) Rollback() error {
+	return tx.tx.Rollback()
+}
+
+// Unscoped 返回忽略软删除的会话
+// 查询包含已删除的行,删除变为物理删除
+func (s *Session) Unscoped() *Session {
+	session := *s
+	session.unscoped = true
+	return &session
+}
+
+// Dialect 返回数据库方言
+func (s *Session) Dialect() Dialect {
+	return s.db.dialect
+}
+
+// exec 在指定节点执行语句,所有写操作都经过此处
+func (s *Session) exec(ctx context.Context, r route, stmt *statement) (sql.Result, error) {
+	conn, err := s.executor(r.node, true)
+	if err != nil {
+		return nil, err
+	}
+	query := rebind(s.db.dialect, stmt.query)
+	start := time.Now()
+	result, err := conn.ExecContext(ctx, query, stmt.args...)
+	affected := int64(-1)
+	if err == nil && s.db.traced() {
+		if n, err := result.RowsAffected(); err == nil {
+			affected = n
+		}
+	}
+	s.db.trace(ctx, query, stmt, time.Since(start), affected, err)
+	return result, err
+}
+
+// query 在指定节点执行查询,所有读操作都经过此处
+// write为true表示带返回结果的写语句,必须在主库执行;记录的耗时不包含读取结果集的时间
+func (s *Session) query(ctx context.Context, r route, stmt *statement, write bool) (*sql.Rows, error) {
+	conn, err := s.executor(r.node, write)
+	if err != nil {
+		return nil, err
+	}
+	query := rebind(s.db.dialect, stmt.query)
+	start := time.Now()
+	rows, err := conn.QueryContext(ctx, query, stmt.args...)
+	s.db.trace(ctx, query, stmt, time.Since(start), -1, err)
+	return rows, err
+}
+
+// Exec 在0号节点的主库执行原生语句,占位符统一使用?
+// 需要在日志中脱敏的参数可以用 Sensitive 包装
+func (s *Session) Exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return s.exec(ctx, route{node: s.db.nodes[0]}, &statement{query: query, args: args})
+}
+
+// Query 在0号节点执行原生查询,占位符统一使用?
+func (s *Session) Query(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return s.query(ctx, route{node: s.db.nodes[0]}, &statement{query: query, args: args}, false)
+}
+
+// Create 插入模型
+// 自增主键为零值时由数据库生成并回填;版本号为零值时置为1
+func (s *Session) Create(ctx context.Context, model interface{}) error {
+	v, sch, err := modelValue(model)
+	if err != nil {
+		return err
+	}
+	if hook, ok := model.(BeforeCreateHook); ok {
+		if err := hook.BeforeCreate(ctx); err != nil {
+			return err
+		}
+	}
+	r, err := s.modelRoute(v, sch)
+	if err != nil {
+		return err
+	}
+	var (
+		dialect = s.db.dialect
+		columns = make([]string, 0, len(sch.fields))
+		marks   = make([]string, 0, len(sch.fields))
+		stmt    = &statement{args: make([]interface{}, 0, len(sch.fields))}
+		autoPK  reflect.Value
+	)
+	for _, f := range sch.fields {
+		fv := fieldByIndex(v, f.index)
+		if f.autoIncrement && fv.IsZero() {
+			autoPK = fv
+			continue
+		}
+		if f.version && fv.IsZero() {
+			setInteger(fv, 1)
+		}
+		columns = append(columns, dialect.Quote(f.column))
+		marks = append(marks, "?")
+		stmt.bind(fv.Interface(), f.sensitive)
+	}
+	stmt.query = fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)",
+		dialect.Quote(r.table), strings.Join(columns, ", "), strings.Join(marks, ", "))
+	switch {
+	case !autoPK.IsValid():
+		if _, err := s.exec(ctx, r, stmt); err != nil {
+			return err
+		}
+	case dialect.SupportsLastInsertID():
+		result, err := s.exec(ctx, r, stmt)
+		if err != nil {
+			return err
+		}
+		id, err := result.LastInsertId()
+		if err != nil {
+			return err
+		}
+		setInteger(autoPK, id)
+	default:
+		stmt.query += " RETURNING " + dialect.Quote(sch.primaryKey.column)
+		rows, err := s.query(ctx, r, stmt, true)
+		if err != nil {
+			return err
+		}
+		if err := scanOne(rows, autoPK.Addr().Interface()); err != nil {
+			return err
+		}
+	}
+	if hook, ok := model.(AfterCreateHook); ok {
+		return hook.AfterCreate(ctx)
+	}
+	return nil
+}
+
+// Update 按主键更新模型的全部列
+// 含版本号时仅当数据库中的版本号与模型一致才更新,并将版本号加1,否则返回 ErrStaleObject
+func (s *Session) Update(ctx context.Context, model interface{}) error {
+	v, sch, err := modelValue(model)
+	if err != nil {
+		return err
+	}
+	pk, err := primaryKeyValue(v, sch)
+	if err != nil {
+		return err
+	}
+	if hook, ok := model.(BeforeUpdateHook); ok {
+		if err := hook.BeforeUpdate(ctx); err != nil {
+			return err
+		}
+	}
+	r, err := s.modelRoute(v, sch)
+	if err != nil {
+		return err
+	}
+	var (
+		dialect = s.db.dialect
+		sets    = make([]string, 0, len(sch.fields))
+		stmt    = &statement{args: make([]interface{}, 0, len(sch.fields)+2)}
+	)
+	for _, f := range sch.fields {
+		if f.primaryKey || f.softDelete {
+			continue
+		}
+		column := dialect.Quote(f.column)
+		if f.version {
+			sets = append(sets, column+" = "+column+" + 1")
+			continue
+		}
+		sets = append(sets, column+" = ?")
+		stmt.bind(fieldByIndex(v, f.index).Interface(), f.sensitive)
+	}
+	where, whereArgs := s.rowCondition(v, sch, pk)
+	stmt.query = fmt.Sprintf("UPDATE %s SET %s WHERE %s", dialect.Quote(r.table), strings.Join(sets, ", "), where)
+	stmt.bindArgs(whereArgs)
+	result, err := s.exec(ctx, r, stmt)
+	if err != nil {
+		return err
+	}
+	if err := s.checkVersion(result, v, sch, pk); err != nil {
+		return err
+	}
+	if hook, ok := model.(AfterUpdateHook); ok {
+		return hook.AfterUpdate(ctx)
+	}
+	return nil
+}
+
+// Delete 按主键删除模型
+// 模型支持软删除且会话未调用 Unscoped 时,仅将删除时间置为当前时间
+// 含版本号时与 Update 一样校验版本号
+func (s *Session) Delete(ctx context.Context, model interface{}) error {
+	v, sch, err := modelValue(model)
+	if err != nil {
+		return err
+	}
+	pk, err := primaryKeyValue(v, sch)
+	if err != nil {
+		return err
+	}
+	if hook, ok := model.(BeforeDeleteHook); ok {
+		if err := hook.BeforeDelete(ctx); err != nil {
+			return err
+		}
+	}
+	r, err := s.modelRoute(v, sch)
+	if err != nil {
+		return err
+	}
+	var (
+		dialect          = s.db.dialect
+		table            = dialect.Quote(r.table)
+		where, whereArgs = s.rowCondition(v, sch, pk)
+		stmt             = &statement{}
+		deletedAt        interface{}
+	)
+	if sch.deletedAt != nil && !s.unscoped {
+		now := s.db.options.nowFunc()
+		if sch.deletedAt.typ == nullTimeType {
+			deletedAt = sql.NullTime{Time: now, Valid: true}
+		} else {
+			deletedAt = &now
+		}
+		sets := dialect.Quote(sch.deletedAt.column) + " = ?"
+		if sch.version != nil {
+			column := dialect.Quote(sch.version.column)
+			sets += ", " + column + " = " + column + " + 1"
+		}
+		stmt.query = fmt.Sprintf("UPDATE %s SET %s WHERE %s", table, sets, where)
+		stmt.bind(deletedAt, false)
+	} else {
+		stmt.query = fmt.Sprintf("DELETE FROM %s WHERE %s", table, where)
+	}
+	stmt.bindArgs(whereArgs)
+	result, err := s.exec(ctx, r, stmt)
+	if err != nil {
+		return err
+	}
+	if err := s.checkVersion(result, v, sch, pk); err != nil {
+		return err
+	}
+	if deletedAt != nil {
+		fieldByIndex(v, sch.deletedAt.index).Set(reflect.ValueOf(deletedAt))
+	}
+	if hook, ok := model.(AfterDeleteHook); ok {
+		return hook.AfterDelete(ctx)
+	}
+	return nil
+}
+
+// Find 查询满足条件的全部记录
+// dest 为结构体切片的指针(元素可以是结构体或结构体指针); where 为空时查询全部
+// 分片模型未指定分片键时并发查询全部分片,结果按分片顺序合并
+func (s *Session) Find(ctx context.Context, dest interface{}, where string, args ...interface{}) error {
+	sliceValue := reflect.ValueOf(dest)
+	if sliceValue.Kind() != reflect.Ptr || sliceValue.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("%w: Find needs a pointer to slice, got %T", ErrInvalidModel, dest)
+	}
+	sch, err := parseSchema(dest)
+	if err != nil {
+		return err
+	}
+	routes, err := s.routes(sch, s.shardKey, s.hasShardKey)
+	if err != nil {
+		return err
+	}
+	sliceValue = sliceValue.Elem()
+	if len(routes) == 1 {
+		return s.findIn(ctx, routes[0], sch, sliceValue, where, args)
+	}
+	var (
+		results = make([]reflect.Value, len(routes))
+		errs    = make([]error, len(routes))
+		wg      sync.WaitGroup
+	)
+	for i := range routes {
+		results[i] = reflect.New(sliceValue.Type()).Elem()
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = s.findIn(ctx, routes[i], sch, results[i], where, args)
+		}(i)
+	}
+	wg.Wait()
+	for i := range routes {
+		if errs[i] != nil {
+			return errs[i]
+		}
+		sliceValue.Set(reflect.AppendSlice(sliceValue, results[i]))
+	}
+	return nil
+}
+
+// findIn 在单个执行位置查询,结果追加到sliceValue
+func (s *Session) findIn(ctx context.Context, r route, sch *schema, sliceValue reflect.Value, where string, args []interface{}) error {
+	rows, err := s.query(ctx, r, &statement{query: s.selectQuery(sch, r.table, where, false), args: args}, false)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	isPtr := sliceValue.Type().Elem().Kind() == reflect.Ptr
+	for rows.Next() {
+		elem := reflect.New(sch.typ)
+		if err := scanModel(ctx, rows, sch, elem); err != nil {
+			return err
+		}
+		if isPtr {
+			sliceValue.Set(reflect.Append(sliceValue, elem))
+		} else {
+			sliceValue.Set(reflect.Append(sliceValue, elem.Elem()))
+		}
+	}
+	return rows.Err()
+}
+
+// First 查询满足条件的第一条记录(按主键排序)
+// 未查询到时返回 ErrRecordNotFound
+// 分片模型未指定分片键时查询全部分片,返回各分片第一条记录中主键最小的一条;
+// 模型没有主键时返回按分片顺序第一个查询到的记录
+func (s *Session) First(ctx context.Context, dest interface{}, where string, args ...interface{}) error {
+	v, sch, err := modelValue(dest)
+	if err != nil {
+		return err
+	}
+	routes, err := s.routes(sch, s.shardKey, s.hasShardKey)
+	if err != nil {
+		return err
+	}
+	if len(routes) == 1 {
+		return s.firstIn(ctx, routes[0], sch, v, where, args)
+	}
+	var found reflect.Value
+	for _, r := range routes {
+		candidate := reflect.New(sch.typ).Elem()
+		err := s.firstIn(ctx, r, sch, candidate, where, args)
+		if err == ErrRecordNotFound {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if sch.primaryKey == nil {
+			found = candidate
+			break
+		}
+		if !found.IsValid() || lessKey(fieldByIndex(candidate, sch.primaryKey.index), fieldByIndex(found, sch.primaryKey.index)) {
+			found = candidate
+		}
+	}
+	if !found.IsValid() {
+		return ErrRecordNotFound
+	}
+	v.Set(found)
+	return nil
+}
+
+// lessKey 比较主键,与数据库的ORDER BY一致;不支持比较的类型视为相等,保留先查询到的记录
+func lessKey(a, b reflect.Value) bool {
+	switch a.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return a.Int() < b.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return a.Uint() < b.Uint()
+	case reflect.Float32, reflect.Float64:
+		return a.Float() < b.Float()
+	case reflect.String:
+		return a.String() < b.String()
+	}
+	return false
+}
+
+// firstIn 在单个执行位置查询第一条记录
+func (s *Session) firstIn(ctx context.Context, r route, sch *schema, v reflect.Value, where string, args []interface{}) error {
+	rows, err := s.query(ctx, r, &statement{query: s.selectQuery(sch, r.table, where, true), args: args}, false)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		return ErrRecordNotFound
+	}
+	return scanModel(ctx, rows, sch, v.Addr())
+}
+
+// selectQuery 生成查询语句,未调用 Unscoped 时过滤已软删除的行
+func (s *Session) selectQuery(sch *schema, table string, where string, first bool) string {
+	dialect := s.db.dialect
+	columns := make([]string, len(sch.fields))
+	for i, f := range sch.fields {
+		columns[i] = dialect.Quote(f.column)
+	}
+	var conditions []string
+	if where != "" {
+		conditions = append(conditions, "("+where+")")
+	}
+	if sch.deletedAt != nil && !s.unscoped {
+		conditions = append(conditions, dialect.Quote(sch.deletedAt.column)+" IS NULL")
+	}
+	query := "SELECT " + strings.Join(columns, ", ") + " FROM " + dialect.Quote(table)
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	if first {
+		if sch.primaryKey != nil {
+			query += " ORDER BY " + dialect.Quote(sch.primaryKey.column)
+		}
+		query += " LIMIT 1"
+	}
+	return query
+}
+
+// rowCondition 定位单行的条件: 主键,版本号,以及未删除
+func (s *Session) rowCondition(v reflect.Value, sch *schema, pk interface{}) (string, []interface{}) {
+	dialect := s.db.dialect
+	where := dialect.Quote(sch.primaryKey.column) + " = ?"
+	args := []interface{}{pk}
+	if sch.version != nil {
+		where += " AND " + dialect.Quote(sch.version.column) + " = ?"
+		args = append(args, fieldByIndex(v, sch.version.index).Interface())
+	}
+	if sch.deletedAt != nil && !s.unscoped {
+		where += " AND " + dialect.Quote(sch.deletedAt.column) + " IS NULL"
+	}
+	return where, args
+}
+
+// checkVersion 校验乐观锁,成功时将模型的版本号加1
+func (s *Session) checkVersion(result sql.Result, v reflect.Value, sch *schema, pk interface{}) error {
+	if sch.version == nil {
+		return nil
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	fv := fieldByIndex(v, sch.version.index)
+	version := integerOf(fv)
+	if affected == 0 {
+		return &ErrStaleObject{Table: sch.table, PrimaryKey: pk, Version: version}
+	}
+	setInteger(fv, version+1)
+	return nil
+}
+
+// modelValue 校验模型为结构体指针,返回结构体的Value与结构
+func modelValue(model interface{}) (reflect.Value, *schema, error) {
+	v := reflect.ValueOf(model)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return reflect.Value{}, nil, fmt.Errorf("%w, got %T", ErrInvalidModel, model)
+	}
+	sch, err := parseSchema(model)
+	if err != nil {
+		return reflect.Value{}, nil, err
+	}
+	return v.Elem(), sch, nil
+}
+
+// primaryKeyValue 返回主键的值,主键不存在或为零值时返回 ErrMissingPrimaryKey
+func primaryKeyValue(v reflect.Value, sch *schema) (interface{}, error) {
+	if sch.primaryKey == nil {
+		return nil, fmt.Errorf("%w: %s", ErrMissingPrimaryKey, sch.typ)
+	}
+	fv := fieldByIndex(v, sch.primaryKey.index)
+	if fv.IsZero() {
+		return nil, fmt.Errorf("%w: %s", ErrMissingPrimaryKey, sch.typ)
+	}
+	return fv.Interface(), nil
+}
+
+// scanModel 将当前行扫描到模型中并调用 AfterFindHook
+// model 为结构体指针的Value,模型实现 ModelScanner 时不再使用反射逐字段取地址
+func scanModel(ctx context.Context, rows *sql.Rows, sch *schema, model reflect.Value) error {
+	m := model.Interface()
+	if scanner, ok := m.(ModelScanner); ok {
+		if err := scanner.ScanRow(rows); err != nil {
+			return err
+		}
+	} else {
+		v := model.Elem()
+		dest := make([]interface{}, len(sch.fields))
+		for i, f := range sch.fields {
+			dest[i] = fieldByIndex(v, f.index).Addr().Interface()
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return err
+		}
+	}
+	if hook, ok := m.(AfterFindHook); ok {
+		return hook.AfterFind(ctx)
+	}
+	return nil
+}
+
+// scanOne 读取单行单列并关闭rows
+func scanOne(rows *sql.Rows, dest interface{}) error {
+	defer rows.Close()
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		return sql.ErrNoRows
+	}
+	if err := rows.Scan(dest); err != nil {
+		return err
+	}
+	return rows.Close()
+}
+
+// fieldByIndex 按索引路径获取字段,路径上为nil的嵌入指针会被分配
+func fieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
+
+// integerOf 读取整数字段的值
+func integerOf(v reflect.Value) int64 {
+	switch v.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(v.Uint())
+	default:
+		return v.Int()
+	}
+}
+
+// setInteger 设置整数字段的值
+func setInteger(v reflect.Value, n int64) {
+	switch v.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v.SetUint(uint64(n))
+	default:
+		v.SetInt(n)
+	}
+}
@@ -0,0 +1,269 @@
+package ORM
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"preseus/ORM/internal/fakedriver"
+)
+
+/****************************************************************
+ * @author: Ihc
+ * @date: 2026/10/19 11:02
+ * @description:
+ ***************************************************************/
+
+var fixedNow = time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+type user struct {
+	ID        int64  `orm:"id,auto"`
+	Name      string `orm:"name"`
+	Email     string
+	Version   int64 `orm:"version,version"`
+	DeletedAt *time.Time
+	calls     []string
+}
+
+func (u *user) BeforeCreate(context.Context) error {
+	u.calls = append(u.calls, "BeforeCreate")
+	return nil
+}
+
+func (u *user) AfterCreate(context.Context) error {
+	u.calls = append(u.calls, "AfterCreate")
+	return nil
+}
+
+func (u *user) BeforeUpdate(context.Context) error {
+	if u.Name == "" {
+		return errors.New("name required")
+	}
+	u.calls = append(u.calls, "BeforeUpdate")
+	return nil
+}
+
+func (u *user) AfterUpdate(context.Context) error {
+	u.calls = append(u.calls, "AfterUpdate")
+	return nil
+}
+
+func (u *user) BeforeDelete(context.Context) error {
+	u.calls = append(u.calls, "BeforeDelete")
+	return nil
+}
+
+func (u *user) AfterDelete(context.Context) error {
+	u.calls = append(u.calls, "AfterDelete")
+	return nil
+}
+
+func (u *user) AfterFind(context.Context) error {
+	u.calls = append(u.calls, "AfterFind")
+	return nil
+}
+
+func newTestDB(t testing.TB, dialect Dialect) (*DB, *fakedriver.Recorder) {
+	sqlDB, recorder, err := fakedriver.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	return NewDB(sqlDB, dialect, WithNowFunc(func() time.Time { return fixedNow })), recorder
+}
+
+func TestToSnakeCase(t *testing.T) {
+	cases := map[string]string{
+		"ID":         "id",
+		"UserID":     "user_id",
+		"HTTPServer": "http_server",
+		"DeletedAt":  "deleted_at",
+		"name":       "name",
+	}
+	for in, want := range cases {
+		if got := SnakeCase(in); got != want {
+			t.Fatalf("SnakeCase(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestParseSchema(t *testing.T) {
+	sch, err := parseSchema(&user{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sch.table != "user" {
+		t.Fatal("table", sch.table)
+	}
+	if !reflect.DeepEqual(sch.columnNames(), []string{"id", "name", "email", "version", "deleted_at"}) {
+		t.Fatal("columns", sch.columnNames())
+	}
+	if sch.primaryKey.column != "id" || sch.version.column != "version" || sch.deletedAt.column != "deleted_at" {
+		t.Fatal("special columns")
+	}
+	if _, err := parseSchema(1); !errors.Is(err, ErrInvalidModel) {
+		t.Fatal("expect ErrInvalidModel", err)
+	}
+}
+
+func TestCreateWithHooks(t *testing.T) {
+	db, recorder := newTestDB(t, MySQL)
+	u := &user{Name: "ihc", Email: "ihc@example.com"}
+	if err := db.Create(context.Background(), u); err != nil {
+		t.Fatal(err)
+	}
+	stmt := recorder.Last()
+	if stmt.Query != "INSERT INTO `user` (`name`, `email`, `version`, `deleted_at`) VALUES (?, ?, ?, ?)" {
+		t.Fatal(stmt.Query)
+	}
+	if u.ID != 1 || u.Version != 1 {
+		t.Fatal("id or version not filled", u.ID, u.Version)
+	}
+	if !reflect.DeepEqual(u.calls, []string{"BeforeCreate", "AfterCreate"}) {
+		t.Fatal(u.calls)
+	}
+}
+
+func TestCreateReturning(t *testing.T) {
+	db, recorder := newTestDB(t, PostgreSQL)
+	recorder.PushRows([]string{"id"}, []interface{}{int64(42)})
+	u := &user{Name: "ihc"}
+	if err := db.Create(context.Background(), u); err != nil {
+		t.Fatal(err)
+	}
+	want := `INSERT INTO "user" ("name", "email", "version", "deleted_at") VALUES ($1, $2, $3, $4) RETURNING "id"`
+	if stmt := recorder.Last(); stmt.Query != want {
+		t.Fatal(stmt.Query)
+	}
+	if u.ID != 42 {
+		t.Fatal("id", u.ID)
+	}
+}
+
+func TestUpdateOptimisticLock(t *testing.T) {
+	db, recorder := newTestDB(t, MySQL)
+	u := &user{ID: 7, Name: "ihc", Version: 3}
+	if err := db.Update(context.Background(), u); err != nil {
+		t.Fatal(err)
+	}
+	stmt := recorder.Last()
+	want := "UPDATE `user` SET `name` = ?, `email` = ?, `version` = `version` + 1 WHERE `id` = ? AND `version` = ? AND `deleted_at` IS NULL"
+	if stmt.Query != want {
+		t.Fatal(stmt.Query)
+	}
+	if !reflect.DeepEqual(stmt.Args, []interface{}{"ihc", "", int64(7), int64(3)}) {
+		t.Fatal(stmt.Args)
+	}
+	if u.Version != 4 {
+		t.Fatal("version", u.Version)
+	}
+
+	recorder.PushAffected(0)
+	err := db.Update(context.Background(), u)
+	var stale *ErrStaleObject
+	if !errors.As(err, &stale) {
+		t.Fatal("expect ErrStaleObject", err)
+	}
+	if stale.Table != "user" || stale.PrimaryKey != int64(7) || stale.Version != 4 {
+		t.Fatal(stale)
+	}
+	if u.Version != 4 {
+		t.Fatal("version must not change on conflict")
+	}
+	if !reflect.DeepEqual(u.calls, []string{"BeforeUpdate", "AfterUpdate", "BeforeUpdate"}) {
+		t.Fatal(u.calls)
+	}
+
+	u.Name = ""
+	if err := db.Update(context.Background(), u); err == nil || err.Error() != "name required" {
+		t.Fatal("BeforeUpdate must abort", err)
+	}
+}
+
+func TestSoftDelete(t *testing.T) {
+	db, recorder := newTestDB(t, MySQL)
+	u := &user{ID: 7, Name: "ihc", Version: 1}
+	if err := db.Delete(context.Background(), u); err != nil {
+		t.Fatal(err)
+	}
+	stmt := recorder.Last()
+	want := "UPDATE `user` SET `deleted_at` = ?, `version` = `version` + 1 WHERE `id` = ? AND `version` = ? AND `deleted_at` IS NULL"
+	if stmt.Query != want {
+		t.Fatal(stmt.Query)
+	}
+	if u.DeletedAt == nil || !u.DeletedAt.Equal(fixedNow) || u.Version != 2 {
+		t.Fatal("deleted_at or version not updated")
+	}
+	if !reflect.DeepEqual(u.calls, []string{"BeforeDelete", "AfterDelete"}) {
+		t.Fatal(u.calls)
+	}
+
+	if err := db.Unscoped().Delete(context.Background(), u); err != nil {
+		t.Fatal(err)
+	}
+	if stmt := recorder.Last(); stmt.Query != "DELETE FROM `user` WHERE `id` = ? AND `version` = ?" {
+		t.Fatal(stmt.Query)
+	}
+
+	if err := db.Delete(context.Background(), &user{}); !errors.Is(err, ErrMissingPrimaryKey) {
+		t.Fatal("expect ErrMissingPrimaryKey", err)
+	}
+}
+
+func TestFindFiltersDeleted(t *testing.T) {
+	db, recorder := newTestDB(t, MySQL)
+	columns := []string{"id", "name", "email", "version", "deleted_at"}
+	recorder.PushRows(columns,
+		[]interface{}{int64(1), "a", "a@example.com", int64(1), nil},
+		[]interface{}{int64(2), "b", "b@example.com", int64(5), nil},
+	)
+	var users []*user
+	if err := db.Find(context.Background(), &users, "name <> ?", "c"); err != nil {
+		t.Fatal(err)
+	}
+	want := "SELECT `id`, `name`, `email`, `version`, `deleted_at` FROM `user` WHERE (name <> ?) AND `deleted_at` IS NULL"
+	if stmt := recorder.Last(); stmt.Query != want {
+		t.Fatal(stmt.Query)
+	}
+	if len(users) != 2 || users[1].Name != "b" || users[1].Version != 5 {
+		t.Fatal("scan error")
+	}
+	if !reflect.DeepEqual(users[0].calls, []string{"AfterFind"}) {
+		t.Fatal(users[0].calls)
+	}
+
+	recorder.PushRows(columns, []interface{}{int64(3), "c", "", int64(1), fixedNow})
+	var u user
+	if err := db.Unscoped().First(context.Background(), &u, ""); err != nil {
+		t.Fatal(err)
+	}
+	if stmt := recorder.Last(); stmt.Query != "SELECT `id`, `name`, `email`, `version`, `deleted_at` FROM `user` ORDER BY `id` LIMIT 1" {
+		t.Fatal(stmt.Query)
+	}
+	if u.DeletedAt == nil || !u.DeletedAt.Equal(fixedNow) {
+		t.Fatal("deleted_at not scanned")
+	}
+	if err := db.First(context.Background(), &u, "id = ?", 100); !errors.Is(err, ErrRecordNotFound) {
+		t.Fatal("expect ErrRecordNotFound", err)
+	}
+}
+
+func TestTransaction(t *testing.T) {
+	db, recorder := newTestDB(t, MySQL)
+	err := db.Transaction(context.Background(), func(tx *Tx) error {
+		return tx.Update(context.Background(), &user{ID: 1, Name: "ihc", Version: 1})
+	})
+	if err != nil || recorder.Commits() != 1 {
+		t.Fatal("commit", err)
+	}
+	recorder.PushAffected(0)
+	err = db.Transaction(context.Background(), func(tx *Tx) error {
+		return tx.Update(context.Background(), &user{ID: 1, Name: "ihc", Version: 1})
+	})
+	var stale *ErrStaleObject
+	if !errors.As(err, &stale) || recorder.Rollbacks() != 1 {
+		t.Fatal("rollback", err)
+	}
+}
@@ -0,0 +1,540 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"preseus/ORM"
+)
+
+/****************************************************************
+ * @author: Ihc
+ * @date: 2026/10/19 13:45
+ * @description: ORM代码生成器
+读取模型结构体的源码,生成:
+	1.表名与列名常量
+	2.ScanRow扫描方法(实现ORM.ModelScanner,Find/First不再反射扫描)
+	3.查询方法: 主键与unique列生成FindXByY返回单条记录,index列返回多条记录,按模型的分片规则路由
+	4.init中用ORM.CheckColumns校验列与ORM包反射解析的结果一致
+用法:
+	//go:generate go run preseus/ORM/ormgen -type User,Order
+规则与ORM包的标签解析一致: 未打标签的匿名结构体(或其指针)被展开,打了标签的匿名字段与time.Time是普通列
+展开的结构体必须定义在同一个包内
+ ***************************************************************/
+
+var (
+	typeNames = flag.String("type", "", "comma-separated list of model type names; must be set")
+	output    = flag.String("output", "", "output file name; default srcdir/<gofile>_orm.go")
+)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("ormgen: ")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: ormgen -type T[,T...] [-output file] [directory]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if *typeNames == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	dir := "."
+	if args := flag.Args(); len(args) > 0 {
+		dir = args[0]
+	}
+	names := strings.Split(*typeNames, ",")
+	src, err := generate(dir, names)
+	if err != nil {
+		log.Fatal(err)
+	}
+	outputName := *output
+	if outputName == "" {
+		base := strings.TrimSuffix(os.Getenv("GOFILE"), ".go")
+		if base == "" {
+			base = strings.ToLower(names[0])
+		}
+		outputName = filepath.Join(dir, base+"_orm.go")
+	}
+	if err := ioutil.WriteFile(outputName, src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// modelField 模型字段
+type modelField struct {
+	Name       string // Name 字段名
+	Path       string // Path 从模型访问字段的路径,如 Model.ID
+	Column     string // Column 列名
+	Type       string // Type 字段类型的源码
+	PrimaryKey bool
+	SoftDelete bool
+	Unique     bool
+	Index      bool
+}
+
+// finder 查询方法
+type finder struct {
+	Name   string      // Name 方法名
+	Cache  string      // Cache 语句缓存变量名
+	Param  string      // Param 参数名
+	Field  *modelField // Field 查询条件的字段
+	Single bool        // Single 是否只返回单条记录
+}
+
+// embedPtr 展开的匿名结构体指针,扫描前为nil时分配
+type embedPtr struct {
+	Path string // Path 从模型访问该指针的路径
+	Type string // Type 指向的结构体类型名
+}
+
+// model 待生成的模型
+type model struct {
+	Name         string
+	Table        string
+	HasTableName bool // HasTableName 模型实现了ORM.Tabler
+	HasAfterFind bool // HasAfterFind 模型实现了ORM.AfterFindHook
+	Fields       []*modelField
+	SoftDelete   *modelField
+	Finders      []*finder
+	EmbedPtrs    []*embedPtr
+}
+
+// typeDecl 包中的类型定义
+type typeDecl struct {
+	st      *ast.StructType   // st 结构体定义,非结构体类型为nil
+	imports map[string]string // imports 定义所在文件的导入,包名 -> 导入路径
+}
+
+// pkg 解析得到的包
+type pkg struct {
+	name    string
+	types   map[string]*typeDecl
+	methods map[string]map[string]bool // methods 类型名 -> 方法名集合
+}
+
+// generate 解析目录中的包,为指定类型生成代码
+func generate(dir string, names []string) ([]byte, error) {
+	p, err := parsePackage(dir)
+	if err != nil {
+		return nil, err
+	}
+	var models []*model
+	for _, name := range names {
+		m, err := p.model(strings.TrimSpace(name))
+		if err != nil {
+			return nil, err
+		}
+		models = append(models, m)
+	}
+	needContext := false
+	for _, m := range models {
+		needContext = needContext || len(m.Finders) > 0
+	}
+	var buf bytes.Buffer
+	err = fileTemplate.Execute(&buf, struct {
+		Package     string
+		NeedContext bool
+		Models      []*model
+	}{p.name, needContext, models})
+	if err != nil {
+		return nil, err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated code: %v\n%s", err, buf.Bytes())
+	}
+	return src, nil
+}
+
+// parsePackage 解析目录中除测试文件外的源码
+func parsePackage(dir string) (*pkg, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(info os.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(pkgs) != 1 {
+		return nil, fmt.Errorf("%s: expected exactly one package, found %d", dir, len(pkgs))
+	}
+	p := &pkg{
+		types:   make(map[string]*typeDecl),
+		methods: make(map[string]map[string]bool),
+	}
+	for name, astPkg := range pkgs {
+		p.name = name
+		for _, file := range astPkg.Files {
+			p.collect(file)
+		}
+	}
+	return p, nil
+}
+
+// collect 收集文件中的类型定义与方法
+func (p *pkg) collect(file *ast.File) {
+	imports := make(map[string]string)
+	for _, spec := range file.Imports {
+		path, _ := strconv.Unquote(spec.Path.Value)
+		name := path[strings.LastIndex(path, "/")+1:]
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		imports[name] = path
+	}
+	for _, decl := range file.Decls {
+		switch decl := decl.(type) {
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				if ts, ok := spec.(*ast.TypeSpec); ok {
+					st, _ := ts.Type.(*ast.StructType)
+					p.types[ts.Name.Name] = &typeDecl{st: st, imports: imports}
+				}
+			}
+		case *ast.FuncDecl:
+			if decl.Recv == nil || len(decl.Recv.List) == 0 {
+				continue
+			}
+			recv := decl.Recv.List[0].Type
+			if star, ok := recv.(*ast.StarExpr); ok {
+				recv = star.X
+			}
+			if ident, ok := recv.(*ast.Ident); ok {
+				if p.methods[ident.Name] == nil {
+					p.methods[ident.Name] = make(map[string]bool)
+				}
+				p.methods[ident.Name][decl.Name.Name] = true
+			}
+		}
+	}
+}
+
+// model 按ORM的规则解析模型
+func (p *pkg) model(name string) (*model, error) {
+	decl, ok := p.types[name]
+	if !ok || decl.st == nil {
+		return nil, fmt.Errorf("struct type %s not found", name)
+	}
+	m := &model{
+		Name:         name,
+		Table:        ORM.SnakeCase(name),
+		HasTableName: p.methods[name]["TableName"],
+		HasAfterFind: p.methods[name]["AfterFind"],
+	}
+	if err := p.fields(m, decl, ""); err != nil {
+		return nil, err
+	}
+	var primaryKey *modelField
+	columns := make(map[string]bool)
+	for _, f := range m.Fields {
+		if columns[f.Column] {
+			return nil, fmt.Errorf("%s has duplicate column %q", name, f.Column)
+		}
+		columns[f.Column] = true
+		if f.PrimaryKey {
+			if primaryKey != nil {
+				return nil, fmt.Errorf("%s has more than one primary key", name)
+			}
+			primaryKey = f
+		}
+	}
+	if primaryKey == nil {
+		for _, f := range m.Fields {
+			if f.Column == "id" {
+				f.PrimaryKey = true
+				primaryKey = f
+			}
+		}
+	}
+	for _, f := range m.Fields {
+		if !f.PrimaryKey && !f.Unique && !f.Index {
+			continue
+		}
+		param := lowerCamel(f.Name)
+		if token.IsKeyword(param) || reservedParams[param] {
+			param += "Value"
+		}
+		m.Finders = append(m.Finders, &finder{
+			Name:   "Find" + name + "By" + f.Name,
+			Cache:  "find" + name + "By" + f.Name + "SQL",
+			Param:  param,
+			Field:  f,
+			Single: f.PrimaryKey || f.Unique,
+		})
+	}
+	return m, nil
+}
+
+// fields 按ORM包parseFields的规则解析结构体字段
+// 未打标签的匿名结构体(或其指针)被展开,time.Time除外;打了标签的匿名字段是普通列
+func (p *pkg) fields(m *model, decl *typeDecl, prefix string) error {
+	for _, astField := range decl.st.Fields.List {
+		var tag string
+		if astField.Tag != nil {
+			raw, err := strconv.Unquote(astField.Tag.Value)
+			if err != nil {
+				return err
+			}
+			tag = reflect.StructTag(raw).Get("orm")
+		}
+		if tag == "-" {
+			continue
+		}
+		typ := types.ExprString(astField.Type)
+		names := make([]string, 0, len(astField.Names))
+		for _, ident := range astField.Names {
+			names = append(names, ident.Name)
+		}
+		if len(astField.Names) == 0 {
+			name, embedded, err := p.embedded(m, decl, astField.Type)
+			if err != nil {
+				return err
+			}
+			if embedded != nil && tag == "" {
+				if _, isPtr := astField.Type.(*ast.StarExpr); isPtr {
+					m.EmbedPtrs = append(m.EmbedPtrs, &embedPtr{Path: prefix + name, Type: name})
+				}
+				if err := p.fields(m, embedded, prefix+name+"."); err != nil {
+					return err
+				}
+				continue
+			}
+			names = append(names, name)
+		}
+		for _, name := range names {
+			if !ast.IsExported(name) {
+				continue
+			}
+			f := &modelField{
+				Name:   name,
+				Path:   prefix + name,
+				Column: ORM.SnakeCase(name),
+				Type:   typ,
+			}
+			parts := strings.Split(tag, ",")
+			if parts[0] != "" {
+				f.Column = parts[0]
+			}
+			for _, opt := range parts[1:] {
+				switch strings.TrimSpace(opt) {
+				case "pk", "auto":
+					f.PrimaryKey = true
+				case "softdelete":
+					f.SoftDelete = true
+				case "unique":
+					f.Unique = true
+				case "index":
+					f.Index = true
+				}
+			}
+			nullTime := isTimePtr(decl, astField.Type) || isSelector(decl, astField.Type, "database/sql", "NullTime")
+			if name == "DeletedAt" && nullTime {
+				f.SoftDelete = true
+			}
+			if f.SoftDelete {
+				if !nullTime {
+					return fmt.Errorf("%s: soft delete column %q must be *time.Time or sql.NullTime", m.Name, f.Column)
+				}
+				m.SoftDelete = f
+			}
+			m.Fields = append(m.Fields, f)
+		}
+	}
+	return nil
+}
+
+// embedded 解析匿名字段的类型,返回字段名
+// 同一个包内的结构体(或其指针)返回其定义,其他类型返回nil,作为普通列;
+// 其他包中除time.Time外的类型无法确定是否为结构体,返回错误
+func (p *pkg) embedded(m *model, decl *typeDecl, expr ast.Expr) (string, *typeDecl, error) {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	switch x := expr.(type) {
+	case *ast.Ident:
+		embedded, ok := p.types[x.Name]
+		if !ok && ast.IsExported(x.Name) {
+			return "", nil, fmt.Errorf("%s: embedded type %s not found in the package", m.Name, x.Name)
+		}
+		if ok && embedded.st != nil {
+			return x.Name, embedded, nil
+		}
+		return x.Name, nil, nil
+	case *ast.SelectorExpr:
+		if isSelector(decl, x, "time", "Time") {
+			return x.Sel.Name, nil, nil
+		}
+	}
+	return "", nil, fmt.Errorf("%s: embedded field %s is not supported, only structs in the same package and time.Time can be embedded", m.Name, types.ExprString(expr))
+}
+
+// isTimePtr 类型是否为*time.Time
+func isTimePtr(decl *typeDecl, expr ast.Expr) bool {
+	star, ok := expr.(*ast.StarExpr)
+	return ok && isSelector(decl, star.X, "time", "Time")
+}
+
+// isSelector 类型是否为导入路径为path的包中名为name的类型,按定义所在文件的导入解析包名
+func isSelector(decl *typeDecl, expr ast.Expr, path, name string) bool {
+	sel, ok := expr.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != name {
+		return false
+	}
+	ident, ok := sel.X.(*ast.Ident)
+	return ok && decl.imports[ident.Name] == path
+}
+
+// reservedParams 生成的查询方法中已使用的标识符
+var reservedParams = map[string]bool{
+	"ctx": true, "q": true, "build": true, "table": true, "rows": true, "err": true, "m": true, "models": true, "d": true,
+	"sql": true, "ORM": true,
+}
+
+// lowerCamel 首个单词转为小写: ID -> id; UserID -> userID; HTTPServer -> httpServer
+func lowerCamel(name string) string {
+	runes := []rune(name)
+	for i := 0; i < len(runes) && unicode.IsUpper(runes[i]); i++ {
+		if i > 0 && i+1 < len(runes) && unicode.IsLower(runes[i+1]) {
+			break
+		}
+		runes[i] = unicode.ToLower(runes[i])
+	}
+	return string(runes)
+}
+
+var fileTemplate = template.Must(template.New("file").Parse(`// Code generated by ormgen. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+{{- if .NeedContext}}
+	"context"
+	"database/sql"
+{{end}}
+	"preseus/ORM"
+)
+{{range $m := .Models}}
+// {{$m.Name}} 的列名
+const (
+{{- range $m.Fields}}
+	{{$m.Name}}Column{{.Name}} = "{{.Column}}"
+{{- end}}
+)
+{{if $m.HasTableName}}
+// {{$m.Name}}Table {{$m.Name}} 的表名
+var {{$m.Name}}Table = (&{{$m.Name}}{}).TableName()
+{{else}}
+// {{$m.Name}}Table {{$m.Name}} 的表名
+const {{$m.Name}}Table = "{{$m.Table}}"
+{{end}}
+// {{$m.Name}}Columns {{$m.Name}} 映射的全部列,顺序与 ScanRow 一致
+var {{$m.Name}}Columns = []string{
+{{- range $m.Fields}}
+	{{$m.Name}}Column{{.Name}},
+{{- end}}
+}
+
+func init() {
+	if err := ORM.CheckColumns(&{{$m.Name}}{}, {{$m.Name}}Columns); err != nil {
+		panic(err)
+	}
+}
+
+// ScanRow 按 {{$m.Name}}Columns 的顺序扫描一行,实现 ORM.ModelScanner
+func (m *{{$m.Name}}) ScanRow(row ORM.RowScanner) error {
+{{- range $m.EmbedPtrs}}
+	if m.{{.Path}} == nil {
+		m.{{.Path}} = new({{.Type}})
+	}
+{{- end}}
+	return row.Scan(
+{{- range $m.Fields}}
+		&m.{{.Path}},
+{{- end}}
+	)
+}
+{{range $f := $m.Finders}}
+var {{$f.Cache}} ORM.SQLCache
+
+// {{$f.Name}} 按{{$f.Field.Column}}查询{{$m.Name}}
+{{- if $f.Single}}
+// 未查询到时返回 ORM.ErrRecordNotFound;分片模型按分片顺序查询,返回第一个查询到的记录
+{{- else}}
+// 分片模型按分片顺序查询,结果按分片顺序合并
+{{- end}}
+func {{$f.Name}}(ctx context.Context, q ORM.Querier, {{$f.Param}} {{$f.Field.Type}}) ({{if $f.Single}}*{{$m.Name}}{{else}}[]*{{$m.Name}}{{end}}, error) {
+	build := func(d ORM.Dialect, table string) string {
+		return {{$f.Cache}}.Get(d, table, func(d ORM.Dialect, table string) string {
+			return "SELECT " + ORM.QuoteColumns(d, {{$m.Name}}Columns) + " FROM " + d.Quote(table) +
+{{- if $m.SoftDelete}}
+				" WHERE " + d.Quote({{$m.Name}}Column{{$f.Field.Name}}) + " = ?" +
+				" AND " + d.Quote({{$m.Name}}Column{{$m.SoftDelete.Name}}) + " IS NULL{{if $f.Single}} LIMIT 1{{end}}"
+{{- else}}
+				" WHERE " + d.Quote({{$m.Name}}Column{{$f.Field.Name}}) + " = ?{{if $f.Single}} LIMIT 1{{end}}"
+{{- end}}
+		})
+	}
+{{- if $f.Single}}
+	var m *{{$m.Name}}
+	err := q.QueryByColumn(ctx, (*{{$m.Name}})(nil), {{$m.Name}}Column{{$f.Field.Name}}, {{$f.Param}}, build, func(rows *sql.Rows) (bool, error) {
+		if !rows.Next() {
+			return true, rows.Err()
+		}
+		m = new({{$m.Name}})
+		return false, m.ScanRow(rows)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if m == nil {
+		return nil, ORM.ErrRecordNotFound
+	}
+{{- if $m.HasAfterFind}}
+	if err := m.AfterFind(ctx); err != nil {
+		return nil, err
+	}
+{{- end}}
+	return m, nil
+{{- else}}
+	var models []*{{$m.Name}}
+	err := q.QueryByColumn(ctx, (*{{$m.Name}})(nil), {{$m.Name}}Column{{$f.Field.Name}}, {{$f.Param}}, build, func(rows *sql.Rows) (bool, error) {
+		for rows.Next() {
+			m := new({{$m.Name}})
+			if err := m.ScanRow(rows); err != nil {
+				return false, err
+			}
+			models = append(models, m)
+		}
+		return true, rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+{{- if $m.HasAfterFind}}
+	for _, m := range models {
+		if err := m.AfterFind(ctx); err != nil {
+			return nil, err
+		}
+	}
+{{- end}}
+	return models, nil
+{{- end}}
+}
+{{end}}
+{{- end}}`))
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+/****************************************************************
+ * @author: Ihc
+ * @date: 2026/10/19 14:50
+ * @description:
+ ***************************************************************/
+
+// TestGenerateUpToDate 示例包中提交的生成代码必须与当前生成器的输出一致
+func TestGenerateUpToDate(t *testing.T) {
+	dir := filepath.Join("..", "internal", "example")
+	src, err := generate(dir, []string{"User", "Event"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := ioutil.ReadFile(filepath.Join(dir, "user_orm.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(src, want) {
+		t.Fatal("user_orm.go is stale, run go generate in ORM/internal/example")
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	cases := map[string]string{
+		"import \"database/sql\"; type User struct { sql.NullString }": "embedded field sql.NullString is not supported",
+		"type User struct { Base }":                                    "embedded type Base not found",
+		"type User struct { ID int64; Key string `orm:\"id\"` }":       "duplicate column",
+		"type User struct { DeletedAt string `orm:\",softdelete\"` }":  "must be *time.Time or sql.NullTime",
+		"type Order struct {}":                                         "struct type User not found",
+	}
+	for src, want := range cases {
+		dir := t.TempDir()
+		if err := ioutil.WriteFile(filepath.Join(dir, "model.go"), []byte("package model\n"+src), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := generate(dir, []string{"User", "Event"}); err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("%s: expect error %q, got %v", src, want, err)
+		}
+	}
+}
+
+func TestLowerCamel(t *testing.T) {
+	cases := map[string]string{
+		"ID":         "id",
+		"Email":      "email",
+		"UserID":     "userID",
+		"HTTPServer": "httpServer",
+	}
+	for in, want := range cases {
+		if got := lowerCamel(in); got != want {
+			t.Fatalf("lowerCamel(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package ORM
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+/****************************************************************
+ * @author: Ihc
+ * @date: 2026/10/19 10:05
+ * @description: 模型结构体解析
+标签格式: `orm:"列名,选项1,选项2"`
+	列名为空时使用字段名的蛇形命名
+	-        忽略该字段
+	pk       主键
+	auto     自增主键(插入时由数据库生成)
+	version  乐观锁版本号列
+	softdelete 软删除列(字段名为DeletedAt时可省略)
+	sensitive 敏感列,慢查询日志与语句追踪中该列绑定的参数会被脱敏
+	index    ormgen为该列生成返回多条记录的查询方法
+	unique   ormgen为该列生成返回单条记录的查询方法
+ ***************************************************************/
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	nullTimeType = reflect.TypeOf(sql.NullTime{})
+	schemaCache  sync.Map // schemaCache reflect.Type -> *schema
+)
+
+// Tabler 模型实现此接口可自定义表名
+type Tabler interface {
+	// TableName 返回模型对应的表名
+	TableName() string
+}
+
+// field 模型字段
+type field struct {
+	name          string       // name 结构体字段名
+	column        string       // column 列名
+	index         []int        // index 字段在结构体中的索引路径
+	typ           reflect.Type // typ 字段类型
+	primaryKey    bool         // primaryKey 是否为主键
+	autoIncrement bool         // autoIncrement 是否为自增主键
+	version       bool         // version 是否为乐观锁版本号
+	softDelete    bool         // softDelete 是否为软删除标记
+	sensitive     bool         // sensitive 是否为敏感列
+}
+
+// schema 模型结构
+type schema struct {
+	typ        reflect.Type      // typ 模型结构体类型
+	table      string            // table 表名
+	fields     []*field          // fields 映射到列的字段,顺序与结构体定义一致
+	columns    map[string]*field // columns 列名到字段的映射
+	primaryKey *field            // primaryKey 主键字段
+	version    *field            // version 乐观锁字段
+	deletedAt  *field            // deletedAt 软删除字段
+}
+
+// columnNames 返回全部列名
+func (s *schema) columnNames() []string {
+	names := make([]string, len(s.fields))
+	for i, f := range s.fields {
+		names[i] = f.column
+	}
+	return names
+}
+
+// parseSchema 解析模型结构,结果按类型缓存
+// model 可以为结构体,结构体指针或结构体切片(指针)
+func parseSchema(model interface{}) (*schema, error) {
+	typ := reflect.TypeOf(model)
+	if typ == nil {
+		return nil, ErrInvalidModel
+	}
+	for typ.Kind() == reflect.Ptr || typ.Kind() == reflect.Slice {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidModel, typ)
+	}
+	if s, ok := schemaCache.Load(typ); ok {
+		return s.(*schema), nil
+	}
+	s := &schema{
+		typ:     typ,
+		table:   SnakeCase(typ.Name()),
+		columns: make(map[string]*field),
+	}
+	if tabler, ok := reflect.New(typ).Interface().(Tabler); ok {
+		s.table = tabler.TableName()
+	}
+	if err := s.parseFields(typ, nil); err != nil {
+		return nil, err
+	}
+	if s.primaryKey == nil {
+		if f, ok := s.columns["id"]; ok {
+			f.primaryKey = true
+			s.primaryKey = f
+		}
+	}
+	actual, _ := schemaCache.LoadOrStore(typ, s)
+	return actual.(*schema), nil
+}
+
+// parseFields 递归解析结构体字段,匿名嵌入的结构体会被展开
+func (s *schema) parseFields(typ reflect.Type, parentIndex []int) error {
+	for i := 0; i < typ.NumField(); i++ {
+		sf := typ.Field(i)
+		tag := sf.Tag.Get("orm")
+		if tag == "-" {
+			continue
+		}
+		index := make([]int, len(parentIndex)+1)
+		copy(index, parentIndex)
+		index[len(parentIndex)] = i
+		if sf.Anonymous && tag == "" {
+			ft := sf.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct && ft != timeType {
+				if err := s.parseFields(ft, index); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if sf.PkgPath != "" {
+			continue
+		}
+		f := &field{
+			name:   sf.Name,
+			column: SnakeCase(sf.Name),
+			index:  index,
+			typ:    sf.Type,
+		}
+		parts := strings.Split(tag, ",")
+		if parts[0] != "" {
+			f.column = parts[0]
+		}
+		for _, opt := range parts[1:] {
+			switch strings.TrimSpace(opt) {
+			case "pk":
+				f.primaryKey = true
+			case "auto":
+				f.primaryKey = true
+				f.autoIncrement = true
+			case "version":
+				f.version = true
+			case "softdelete":
+				f.softDelete = true
+			case "sensitive":
+				f.sensitive = true
+			}
+		}
+		if sf.Name == "DeletedAt" && (f.typ == nullTimeType || f.typ == reflect.PtrTo(timeType)) {
+			f.softDelete = true
+		}
+		if err := s.addField(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addField 登记字段并校验特殊列
+func (s *schema) addField(f *field) error {
+	if _, ok := s.columns[f.column]; ok {
+		return fmt.Errorf("orm: %s has duplicate column %q", s.typ, f.column)
+	}
+	if f.primaryKey {
+		if s.primaryKey != nil {
+			return fmt.Errorf("orm: %s has more than one primary key", s.typ)
+		}
+		s.primaryKey = f
+	}
+	if f.version {
+		switch f.typ.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		default:
+			return fmt.Errorf("orm: version column %q of %s must be an integer", f.column, s.typ)
+		}
+		s.version = f
+	}
+	if f.softDelete {
+		if f.typ != nullTimeType && f.typ != reflect.PtrTo(timeType) {
+			return fmt.Errorf("orm: soft delete column %q of %s must be *time.Time or sql.NullTime", f.column, s.typ)
+		}
+		s.deletedAt = f
+	}
+	s.fields = append(s.fields, f)
+	s.columns[f.column] = f
+	return nil
+}
+
+// SnakeCase 驼峰命名转蛇形命名,连续的大写字母视为一个单词
+// 未指定列名与表名时使用此规则,ormgen生成代码时同样使用
+// UserID -> user_id; HTTPServer -> http_server
+func SnakeCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) ||
+				(i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1]))) {
+				b.WriteByte('_')
+			}
+			b.WriteRune(unicode.ToLower(r))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,222 @@
+package ORM
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"log"
+	"sync/atomic"
+	"time"
+)
+
+/****************************************************************
+ * @author: Ihc
+ * @date: 2026/10/19 16:10
+ * @description: 语句追踪,慢查询日志与连接池统计
+所有语句都经过Session的exec与query执行,执行完成后:
+	1.更新统计计数
+	2.超过慢查询阈值时记录语句与参数
+	3.通知全部 QueryHook
+日志与钩子中敏感参数显示为***: 模型中带sensitive标签的列,以及用 Sensitive 包装的原生语句参数
+ ***************************************************************/
+
+// redacted 脱敏后的参数
+const redacted = "***"
+
+// QueryEvent 一次语句执行的信息
+type QueryEvent struct {
+	Query        string        // Query 实际执行的语句(已改写占位符)
+	Args         []interface{} // Args 绑定的参数,敏感参数已脱敏
+	Duration     time.Duration // Duration 执行耗时,查询语句不包含读取结果集的时间
+	RowsAffected int64         // RowsAffected 影响行数,查询语句或驱动不支持时为-1
+	Err          error         // Err 执行错误
+	Slow         bool          // Slow 是否超过慢查询阈值
+}
+
+// QueryHook 语句执行钩子,用于追踪与监控
+// 钩子在执行语句的goroutine中同步调用,不应阻塞
+type QueryHook interface {
+	AfterQuery(ctx context.Context, event *QueryEvent)
+}
+
+// QueryHookFunc 函数形式的 QueryHook
+type QueryHookFunc func(ctx context.Context, event *QueryEvent)
+
+// AfterQuery 调用f
+func (f QueryHookFunc) AfterQuery(ctx context.Context, event *QueryEvent) {
+	f(ctx, event)
+}
+
+// Printer 慢查询日志的输出,*log.Logger满足此接口
+type Printer interface {
+	Printf(format string, args ...interface{})
+}
+
+// WithQueryHook 添加语句执行钩子
+func WithQueryHook(hooks ...QueryHook) Option {
+	return func(options *Options) {
+		options.queryHooks = append(options.queryHooks, hooks...)
+	}
+}
+
+// WithSlowQuery 设置慢查询阈值与日志输出
+// 耗时不小于threshold的语句会被记录;printer为nil时使用标准库的默认日志
+func WithSlowQuery(threshold time.Duration, printer Printer) Option {
+	return func(options *Options) {
+		if printer == nil {
+			printer = log.Default()
+		}
+		options.slowThreshold = threshold
+		options.slowPrinter = printer
+	}
+}
+
+// sensitiveArg 被标记为敏感的原生语句参数
+type sensitiveArg struct {
+	value interface{}
+}
+
+// Value 实现driver.Valuer,向驱动传递原始值
+func (a sensitiveArg) Value() (driver.Value, error) {
+	return driver.DefaultParameterConverter.ConvertValue(a.value)
+}
+
+// Sensitive 包装原生语句中的敏感参数,慢查询日志与钩子中显示为***
+func Sensitive(value interface{}) interface{} {
+	return sensitiveArg{value: value}
+}
+
+// Stats 数据库统计信息
+type Stats struct {
+	sql.DBStats             // DBStats 全部节点主库与从库连接池状态之和
+	Nodes       []NodeStats // Nodes 按节点编号排列的连接池状态
+	Queries     int64       // Queries 执行的语句总数
+	Errors      int64       // Errors 执行出错的语句数
+	SlowQueries int64       // SlowQueries 慢查询数
+}
+
+// NodeStats 单个节点的连接池状态
+type NodeStats struct {
+	Primary  sql.DBStats   // Primary 主库
+	Replicas []sql.DBStats // Replicas 从库,顺序与添加时一致
+}
+
+// counters 语句计数,原子操作
+type counters struct {
+	queries     int64
+	errors      int64
+	slowQueries int64
+}
+
+// Stats 返回全部节点的连接池状态与全部语句的计数
+// 汇总时同一个*sql.DB只计算一次
+func (db *DB) Stats() Stats {
+	stats := Stats{
+		Nodes:       make([]NodeStats, len(db.nodes)),
+		Queries:     atomic.LoadInt64(&db.counters.queries),
+		Errors:      atomic.LoadInt64(&db.counters.errors),
+		SlowQueries: atomic.LoadInt64(&db.counters.slowQueries),
+	}
+	seen := make(map[*sql.DB]bool)
+	add := func(sqlDB *sql.DB) sql.DBStats {
+		s := sqlDB.Stats()
+		if !seen[sqlDB] {
+			seen[sqlDB] = true
+			addDBStats(&stats.DBStats, s)
+		}
+		return s
+	}
+	for i, n := range db.nodes {
+		stats.Nodes[i].Primary = add(n.primary)
+		for _, replica := range n.replicas {
+			stats.Nodes[i].Replicas = append(stats.Nodes[i].Replicas, add(replica))
+		}
+	}
+	return stats
+}
+
+// addDBStats 将s累加到total
+func addDBStats(total *sql.DBStats, s sql.DBStats) {
+	total.MaxOpenConnections += s.MaxOpenConnections
+	total.OpenConnections += s.OpenConnections
+	total.InUse += s.InUse
+	total.Idle += s.Idle
+	total.WaitCount += s.WaitCount
+	total.WaitDuration += s.WaitDuration
+	total.MaxIdleClosed += s.MaxIdleClosed
+	total.MaxIdleTimeClosed += s.MaxIdleTimeClosed
+	total.MaxLifetimeClosed += s.MaxLifetimeClosed
+}
+
+// traced 是否需要构造 QueryEvent
+func (db *DB) traced() bool {
+	return len(db.options.queryHooks) > 0 || db.options.slowThreshold > 0
+}
+
+// trace 记录一次语句执行
+func (db *DB) trace(ctx context.Context, query string, stmt *statement, duration time.Duration, affected int64, err error) {
+	atomic.AddInt64(&db.counters.queries, 1)
+	if err != nil {
+		atomic.AddInt64(&db.counters.errors, 1)
+	}
+	if !db.traced() {
+		return
+	}
+	event := &QueryEvent{
+		Query:        query,
+		Args:         stmt.redactedArgs(),
+		Duration:     duration,
+		RowsAffected: affected,
+		Err:          err,
+		Slow:         db.options.slowThreshold > 0 && duration >= db.options.slowThreshold,
+	}
+	if event.Slow {
+		atomic.AddInt64(&db.counters.slowQueries, 1)
+		db.options.slowPrinter.Printf("orm: slow query %s: %s %s", duration, query, formatArgs(event.Args))
+	}
+	for _, hook := range db.options.queryHooks {
+		hook.AfterQuery(ctx, event)
+	}
+}
+
+// redactedArgs 返回脱敏后的参数
+func (st *statement) redactedArgs() []interface{} {
+	args := make([]interface{}, len(st.args))
+	for i, arg := range st.args {
+		if _, ok := arg.(sensitiveArg); ok || (st.sensitive != nil && st.sensitive[i]) {
+			args[i] = redacted
+			continue
+		}
+		args[i] = arg
+	}
+	return args
+}
+
+// formatArgs 格式化参数用于日志
+func formatArgs(args []interface{}) string {
+	if len(args) == 0 {
+		return "[]"
+	}
+	s := "["
+	for i, arg := range args {
+		if i > 0 {
+			s += ", "
+		}
+		switch v := arg.(type) {
+		case string:
+			if v == redacted {
+				s += v
+			} else {
+				s += fmt.Sprintf("%q", v)
+			}
+		case []byte:
+			s += fmt.Sprintf("%q", v)
+		case time.Time:
+			s += v.Format(time.RFC3339Nano)
+		default:
+			s += fmt.Sprintf("%v", v)
+		}
+	}
+	return s + "]"
+}
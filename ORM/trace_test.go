@@ -0,0 +1,104 @@
+package ORM
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"preseus/ORM/internal/fakedriver"
+)
+
+/****************************************************************
+ * @author: Ihc
+ * @date: 2026/10/19 16:40
+ * @description:
+ ***************************************************************/
+
+type account struct {
+	ID       int64  `orm:"id,auto"`
+	Name     string `orm:"name"`
+	Password string `orm:"password,sensitive"`
+}
+
+// eventRecorder 收集 QueryEvent
+type eventRecorder struct {
+	mu     sync.Mutex
+	events []*QueryEvent
+}
+
+func (r *eventRecorder) AfterQuery(_ context.Context, event *QueryEvent) {
+	r.mu.Lock()
+	r.events = append(r.events, event)
+	r.mu.Unlock()
+}
+
+func newTracedDB(t *testing.T, opts ...Option) (*DB, *fakedriver.Recorder) {
+	sqlDB, recorder, err := fakedriver.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	return NewDB(sqlDB, MySQL, opts...), recorder
+}
+
+func TestQueryHook(t *testing.T) {
+	hook := &eventRecorder{}
+	db, recorder := newTracedDB(t, WithQueryHook(hook))
+	ctx := context.Background()
+	if err := db.Create(ctx, &account{Name: "ihc", Password: "secret"}); err != nil {
+		t.Fatal(err)
+	}
+	recorder.PushAffected(3)
+	if _, err := db.Exec(ctx, "UPDATE account SET name = ? WHERE password = ?", "x", Sensitive("secret")); err != nil {
+		t.Fatal(err)
+	}
+	var accounts []account
+	if err := db.Find(ctx, &accounts, ""); err != nil {
+		t.Fatal(err)
+	}
+	if len(hook.events) != 3 {
+		t.Fatal("events", len(hook.events))
+	}
+	insert, update, find := hook.events[0], hook.events[1], hook.events[2]
+	if insert.Query != "INSERT INTO `account` (`name`, `password`) VALUES (?, ?)" ||
+		insert.Args[0] != "ihc" || insert.Args[1] != redacted || insert.RowsAffected != 1 {
+		t.Fatal("insert event", insert)
+	}
+	if update.Args[0] != "x" || update.Args[1] != redacted || update.RowsAffected != 3 {
+		t.Fatal("update event", update)
+	}
+	if stmt := recorder.All()[1]; stmt.Args[1] != "secret" {
+		t.Fatal("sensitive arg must reach the driver unchanged", stmt.Args)
+	}
+	if find.RowsAffected != -1 || find.Err != nil || find.Slow {
+		t.Fatal("find event", find)
+	}
+	stats := db.Stats()
+	if stats.Queries != 3 || stats.Errors != 0 || stats.SlowQueries != 0 {
+		t.Fatal("stats", stats)
+	}
+}
+
+func TestSlowQueryLog(t *testing.T) {
+	var buf bytes.Buffer
+	db, _ := newTracedDB(t, WithSlowQuery(time.Nanosecond, log.New(&buf, "", 0)))
+	a := &account{ID: 1, Name: "ihc", Password: "secret"}
+	if err := db.Update(context.Background(), a); err != nil {
+		t.Fatal(err)
+	}
+	line := buf.String()
+	if !strings.HasPrefix(line, "orm: slow query ") ||
+		!strings.HasSuffix(line, "UPDATE `account` SET `name` = ?, `password` = ? WHERE `id` = ? [\"ihc\", ***, 1]\n") {
+		t.Fatal(line)
+	}
+	if strings.Contains(line, "secret") {
+		t.Fatal("password leaked into slow query log")
+	}
+	if stats := db.Stats(); stats.SlowQueries != 1 || stats.Queries != 1 {
+		t.Fatal("stats", stats)
+	}
+}
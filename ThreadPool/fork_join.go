@@ -0,0 +1,323 @@
+package ThreadPool
+
+import (
+	"context"
+	"math/rand"
+	"runtime"
+	"runtime/debug"
+	"sync"
+)
+
+/****************************************************************
+ * @author: Ihc
+ * @date: 2026/10/20 05:40
+ * @description: 工作窃取线程池
+每个worker有自己的双端队列,Fork把子任务压入当前worker的队列底部,
+worker从底部取任务(后进先出,局部性好),空闲的worker从随机选择的其他worker的队列顶部窃取(先进先出,窃取到的任务更大)
+Join等待的任务未完成时,worker继续执行自己队列中的任务或窃取任务,而不是阻塞,
+因此递归的分治任务不会占满worker导致死锁:
+	pool.Invoke(func(w *Worker) interface{} {
+		left := w.Fork(sortLeft)
+		sortRight(w)
+		w.Join(left)
+		...
+	})
+ ***************************************************************/
+
+// ForkJoinFunc 在worker中执行的任务,通过w拆分子任务
+type ForkJoinFunc func(w *Worker) interface{}
+
+// ForkJoinTask Fork返回的子任务
+type ForkJoinTask struct {
+	fn     ForkJoinFunc
+	done   chan struct{}
+	result interface{}
+	err    *PanicError
+	future *Future // future 从外部提交的任务完成时设置
+}
+
+// isDone 任务是否已完成
+func (t *ForkJoinTask) isDone() bool {
+	select {
+	case <-t.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// deque worker的双端队列,所有者在底部存取,其他worker从顶部窃取
+type deque struct {
+	mu    sync.Mutex
+	items []*ForkJoinTask
+	head  int // head 顶部的位置
+}
+
+func (d *deque) push(t *ForkJoinTask) {
+	d.mu.Lock()
+	d.items = append(d.items, t)
+	d.mu.Unlock()
+}
+
+// pop 从底部取出任务
+func (d *deque) pop() *ForkJoinTask {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	n := len(d.items)
+	if n == d.head {
+		return nil
+	}
+	t := d.items[n-1]
+	d.items[n-1] = nil
+	d.items = d.items[:n-1]
+	d.reset()
+	return t
+}
+
+// steal 从顶部取出任务
+func (d *deque) steal() *ForkJoinTask {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(d.items) == d.head {
+		return nil
+	}
+	t := d.items[d.head]
+	d.items[d.head] = nil
+	d.head++
+	d.reset()
+	return t
+}
+
+// reset 队列为空时复用底层数组,调用方持有锁
+func (d *deque) reset() {
+	if d.head == len(d.items) {
+		d.items = d.items[:0]
+		d.head = 0
+	}
+}
+
+// Worker 工作窃取线程池的worker,只能在执行任务的goroutine中使用
+type Worker struct {
+	pool  *ForkJoinPool
+	index int
+	deque deque
+	rand  *rand.Rand // rand 选择窃取对象,只被所属goroutine使用
+}
+
+// ForkJoinPool 工作窃取线程池
+type ForkJoinPool struct {
+	workers    []*Worker
+	mu         sync.Mutex      // mu 保护submitted与shutdown
+	submitted  []*ForkJoinTask // submitted 从外部提交的任务
+	shutdown   bool
+	wake       chan struct{} // wake 有新任务时唤醒空闲的worker
+	quit       chan struct{} // quit 关闭后空闲的worker退出
+	wg         sync.WaitGroup
+	terminated chan struct{}
+}
+
+// NewForkJoinPool 创建工作窃取线程池
+// worker数由 WithCoreWorkers 设置,默认为GOMAXPROCS,其他选项被忽略
+func NewForkJoinPool(opts ...Option) *ForkJoinPool {
+	options := Options{coreWorkers: runtime.GOMAXPROCS(0)}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.coreWorkers < 1 {
+		panic("threadpool: coreWorkers is not > 0")
+	}
+	p := &ForkJoinPool{
+		workers:    make([]*Worker, options.coreWorkers),
+		wake:       make(chan struct{}, options.coreWorkers),
+		quit:       make(chan struct{}),
+		terminated: make(chan struct{}),
+	}
+	for i := range p.workers {
+		p.workers[i] = &Worker{pool: p, index: i, rand: rand.New(rand.NewSource(int64(i) + 1))}
+	}
+	p.wg.Add(len(p.workers))
+	for _, w := range p.workers {
+		go w.loop()
+	}
+	go func() {
+		p.wg.Wait()
+		close(p.terminated)
+	}()
+	return p
+}
+
+// Parallelism worker数
+func (p *ForkJoinPool) Parallelism() int {
+	return len(p.workers)
+}
+
+// Submit 从外部提交任务,通过 Future 获取结果,任务panic时 Future 返回 *PanicError
+func (p *ForkJoinPool) Submit(fn ForkJoinFunc) (*Future, error) {
+	t := &ForkJoinTask{fn: fn, done: make(chan struct{}), future: newFuture()}
+	p.mu.Lock()
+	if p.shutdown {
+		p.mu.Unlock()
+		return nil, ErrShutdown
+	}
+	p.submitted = append(p.submitted, t)
+	p.mu.Unlock()
+	p.signal()
+	return t.future, nil
+}
+
+// Invoke 提交任务并等待结果
+func (p *ForkJoinPool) Invoke(fn ForkJoinFunc) (interface{}, error) {
+	f, err := p.Submit(fn)
+	if err != nil {
+		return nil, err
+	}
+	return f.Get(context.Background())
+}
+
+// Shutdown 不再接收外部提交的任务,已提交的任务及其子任务继续执行
+func (p *ForkJoinPool) Shutdown() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.shutdown {
+		p.shutdown = true
+		close(p.quit)
+	}
+}
+
+// AwaitTermination 等待关闭后全部worker退出,ctx结束时返回ctx.Err()
+func (p *ForkJoinPool) AwaitTermination(ctx context.Context) error {
+	select {
+	case <-p.terminated:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// signal 唤醒一个空闲的worker,已有足够的唤醒信号时忽略
+func (p *ForkJoinPool) signal() {
+	select {
+	case p.wake <- struct{}{}:
+	default:
+	}
+}
+
+// pollSubmitted 取出最早从外部提交的任务
+func (p *ForkJoinPool) pollSubmitted() *ForkJoinTask {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.submitted) == 0 {
+		return nil
+	}
+	t := p.submitted[0]
+	p.submitted[0] = nil
+	p.submitted = p.submitted[1:]
+	return t
+}
+
+// Fork 在当前worker的队列中加入子任务,返回的任务需要用 Join 等待
+func (w *Worker) Fork(fn ForkJoinFunc) *ForkJoinTask {
+	t := &ForkJoinTask{fn: fn, done: make(chan struct{})}
+	w.deque.push(t)
+	w.pool.signal()
+	return t
+}
+
+// Join 等待任务完成并返回结果,子任务panic时在当前任务中重新panic
+// 等待期间当前worker继续执行其他任务
+func (w *Worker) Join(t *ForkJoinTask) interface{} {
+	for !t.isDone() {
+		if next := w.next(false); next != nil {
+			w.run(next)
+			continue
+		}
+		// 没有可以执行的任务,说明t正在被其他worker执行
+		select {
+		case <-t.done:
+		case <-w.pool.wake:
+			// 取走了给空闲worker的信号,重新检查时会处理新任务
+		}
+	}
+	if t.err != nil {
+		panic(t.err)
+	}
+	return t.result
+}
+
+// next 依次从自己的队列,其他worker的队列与外部提交的任务中取任务
+// Join中不取外部提交的任务,避免等待时开始执行无关的大任务
+func (w *Worker) next(external bool) *ForkJoinTask {
+	if t := w.deque.pop(); t != nil {
+		return t
+	}
+	if t := w.steal(); t != nil {
+		return t
+	}
+	if external {
+		return w.pool.pollSubmitted()
+	}
+	return nil
+}
+
+// steal 从随机选择的worker开始依次尝试窃取
+func (w *Worker) steal() *ForkJoinTask {
+	workers := w.pool.workers
+	n := len(workers)
+	if n == 1 {
+		return nil
+	}
+	start := w.rand.Intn(n)
+	for i := 0; i < n; i++ {
+		victim := workers[(start+i)%n]
+		if victim == w {
+			continue
+		}
+		if t := victim.deque.steal(); t != nil {
+			return t
+		}
+	}
+	return nil
+}
+
+// loop worker的主循环,关闭后没有任务时退出
+func (w *Worker) loop() {
+	defer w.pool.wg.Done()
+	for {
+		if t := w.next(true); t != nil {
+			w.run(t)
+			continue
+		}
+		select {
+		case <-w.pool.wake:
+		case <-w.pool.quit:
+			// 关闭后仍可能有子任务在其他worker的队列中,取完再退出
+			if t := w.next(true); t != nil {
+				w.run(t)
+				continue
+			}
+			return
+		}
+	}
+}
+
+// run 执行任务,记录结果或panic
+func (w *Worker) run(t *ForkJoinTask) {
+	defer func() {
+		if r := recover(); r != nil {
+			pe, ok := r.(*PanicError)
+			if !ok {
+				pe = &PanicError{Recovered: r, Stack: debug.Stack()}
+			}
+			t.err = pe
+		}
+		close(t.done)
+		if t.future != nil {
+			if t.err != nil {
+				t.future.complete(nil, t.err)
+			} else {
+				t.future.complete(t.result, nil)
+			}
+		}
+	}()
+	t.result = t.fn(w)
+}
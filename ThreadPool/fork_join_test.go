@@ -0,0 +1,213 @@
+package ThreadPool
+
+import (
+	"context"
+	"math/rand"
+	"runtime"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+/****************************************************************
+ * @author: Ihc
+ * @date: 2026/10/20 05:50
+ * @description:
+ ***************************************************************/
+
+// sortThreshold 小于此长度的区间直接排序
+const sortThreshold = 1024
+
+func randomInts(n int) []int {
+	r := rand.New(rand.NewSource(1))
+	data := make([]int, n)
+	for i := range data {
+		data[i] = r.Int()
+	}
+	return data
+}
+
+// merge 合并data[:mid]与data[mid:]两个有序区间
+func merge(data, tmp []int, mid int) {
+	copy(tmp, data)
+	i, j, k := 0, mid, 0
+	for i < mid && j < len(data) {
+		if tmp[i] <= tmp[j] {
+			data[k] = tmp[i]
+			i++
+		} else {
+			data[k] = tmp[j]
+			j++
+		}
+		k++
+	}
+	k += copy(data[k:], tmp[i:mid])
+	copy(data[k:], tmp[j:len(data)])
+}
+
+// parallelSort 拆分为两半,Fork左半部分,当前worker排序右半部分,Join后合并
+func parallelSort(w *Worker, data, tmp []int) {
+	if len(data) < sortThreshold {
+		sort.Ints(data)
+		return
+	}
+	mid := len(data) / 2
+	left := w.Fork(func(w *Worker) interface{} {
+		parallelSort(w, data[:mid], tmp[:mid])
+		return nil
+	})
+	parallelSort(w, data[mid:], tmp[mid:])
+	w.Join(left)
+	merge(data, tmp, mid)
+}
+
+func TestForkJoinParallelSort(t *testing.T) {
+	// worker数远小于同时等待的任务数,Join不能阻塞worker
+	for _, parallelism := range []int{1, 2, 4} {
+		p := NewForkJoinPool(WithCoreWorkers(parallelism))
+		data := randomInts(100000)
+		if _, err := p.Invoke(func(w *Worker) interface{} {
+			parallelSort(w, data, make([]int, len(data)))
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if !sort.IntsAreSorted(data) {
+			t.Fatalf("parallelism %d: not sorted", parallelism)
+		}
+		p.Shutdown()
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		if err := p.AwaitTermination(ctx); err != nil {
+			t.Fatal(err)
+		}
+		cancel()
+	}
+}
+
+func fib(w *Worker, n int) int {
+	if n < 2 {
+		return n
+	}
+	left := w.Fork(func(w *Worker) interface{} { return fib(w, n-1) })
+	right := fib(w, n-2)
+	return w.Join(left).(int) + right
+}
+
+func TestForkJoinResultsAndConcurrentSubmit(t *testing.T) {
+	p := NewForkJoinPool(WithCoreWorkers(3))
+	defer p.Shutdown()
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := p.Invoke(func(w *Worker) interface{} { return fib(w, 18) })
+			if err != nil || v.(int) != 2584 {
+				t.Error("fib", v, err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestForkJoinPanic(t *testing.T) {
+	p := NewForkJoinPool(WithCoreWorkers(2))
+	_, err := p.Invoke(func(w *Worker) interface{} {
+		child := w.Fork(func(w *Worker) interface{} { panic("boom") })
+		return w.Join(child)
+	})
+	pe, ok := err.(*PanicError)
+	if !ok || pe.Recovered != "boom" {
+		t.Fatal("panic in subtask must propagate through Join", err)
+	}
+	// worker在panic后继续工作
+	v, err := p.Invoke(func(w *Worker) interface{} { return fib(w, 10) })
+	if err != nil || v.(int) != 55 {
+		t.Fatal(v, err)
+	}
+	p.Shutdown()
+	if _, err := p.Submit(func(w *Worker) interface{} { return nil }); err != ErrShutdown {
+		t.Fatal("submit after shutdown", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := p.AwaitTermination(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDequeOrder(t *testing.T) {
+	var d deque
+	tasks := make([]*ForkJoinTask, 3)
+	for i := range tasks {
+		tasks[i] = &ForkJoinTask{}
+		d.push(tasks[i])
+	}
+	if d.pop() != tasks[2] || d.steal() != tasks[0] || d.steal() != tasks[1] {
+		t.Fatal("owner pops LIFO, thieves steal FIFO")
+	}
+	if d.pop() != nil || d.steal() != nil || len(d.items) != 0 || d.head != 0 {
+		t.Fatal("empty deque")
+	}
+}
+
+const benchmarkSortSize = 1 << 18
+
+// BenchmarkSortForkJoin 递归拆分,子任务在worker的队列中,空闲worker窃取
+func BenchmarkSortForkJoin(b *testing.B) {
+	p := NewForkJoinPool()
+	defer p.Shutdown()
+	source := randomInts(benchmarkSortSize)
+	data, tmp := make([]int, len(source)), make([]int, len(source))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		copy(data, source)
+		_, _ = p.Invoke(func(w *Worker) interface{} {
+			parallelSort(w, data, tmp)
+			return nil
+		})
+	}
+}
+
+// BenchmarkSortSharedQueue 共享队列的线程池不能在任务中等待子任务,
+// 按层提交:先并行排序所有小区间,再逐层并行合并,每层之间等待全部完成
+func BenchmarkSortSharedQueue(b *testing.B) {
+	p := NewThreadPool(WithCoreWorkers(runtime.GOMAXPROCS(0)), WithQueueSize(benchmarkSortSize/sortThreshold+1))
+	defer p.Shutdown()
+	source := randomInts(benchmarkSortSize)
+	data, tmp := make([]int, len(source)), make([]int, len(source))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		copy(data, source)
+		var wg sync.WaitGroup
+		for lo := 0; lo < len(data); lo += sortThreshold {
+			chunk := data[lo:minInt(lo+sortThreshold, len(data))]
+			wg.Add(1)
+			_ = p.Submit(func() {
+				defer wg.Done()
+				sort.Ints(chunk)
+			})
+		}
+		wg.Wait()
+		for width := sortThreshold; width < len(data); width *= 2 {
+			for lo := 0; lo+width < len(data); lo += 2 * width {
+				hi := minInt(lo+2*width, len(data))
+				d, m := data[lo:hi], tmp[lo:hi]
+				wg.Add(1)
+				_ = p.Submit(func() {
+					defer wg.Done()
+					merge(d, m, width)
+				})
+			}
+			wg.Wait()
+		}
+	}
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
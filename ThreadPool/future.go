@@ -0,0 +1,57 @@
+package ThreadPool
+
+import (
+	"context"
+)
+
+/****************************************************************
+ * @author: Ihc
+ * @date: 2026/10/20 03:20
+ * @description: 异步任务的结果
+ ***************************************************************/
+
+// Callable 有返回值的任务
+type Callable func() (interface{}, error)
+
+// Future 异步任务的结果,并发安全
+type Future struct {
+	done  chan struct{}
+	value interface{}
+	err   error
+}
+
+func newFuture() *Future {
+	return &Future{done: make(chan struct{})}
+}
+
+// complete 设置结果,只能调用一次
+func (f *Future) complete(value interface{}, err error) {
+	f.value = value
+	f.err = err
+	close(f.done)
+}
+
+// Done 任务完成时关闭的channel
+func (f *Future) Done() <-chan struct{} {
+	return f.done
+}
+
+// IsDone 任务是否已完成
+func (f *Future) IsDone() bool {
+	select {
+	case <-f.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Get 等待任务完成并返回结果,ctx结束时返回ctx.Err()
+func (f *Future) Get(ctx context.Context) (interface{}, error) {
+	select {
+	case <-f.done:
+		return f.value, f.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
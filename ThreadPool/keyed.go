@@ -0,0 +1,114 @@
+package ThreadPool
+
+import (
+	"sync"
+)
+
+/****************************************************************
+ * @author: Ihc
+ * @date: 2026/10/20 07:10
+ * @description: 按键串行的执行器
+同一个键的任务按提交顺序依次执行,不同键的任务在线程池中并行执行
+每个有未完成任务的键只占用线程池中的一个任务,任务执行完后键被删除,不为键创建goroutine
+ ***************************************************************/
+
+// keyedBatch 一次连续执行同一个键的任务数,超过后重新排队,避免繁忙的键长期占用worker
+const keyedBatch = 64
+
+// keyQueue 一个键的待执行任务
+type keyQueue struct {
+	key   string
+	tasks []func()
+}
+
+// KeyedExecutor 按键串行的执行器,并发安全
+type KeyedExecutor struct {
+	pool   *ThreadPool
+	mu     sync.Mutex
+	queues map[string]*keyQueue // queues 有未完成任务的键
+}
+
+// NewKeyedExecutor 在pool上创建按键串行的执行器
+// 丢弃任务的 DiscardPolicy 与 DiscardOldestPolicy 会破坏键的执行顺序,pool使用它们时panic
+func NewKeyedExecutor(pool *ThreadPool) *KeyedExecutor {
+	if policy := pool.options.rejectPolicy; policy == DiscardPolicy || policy == DiscardOldestPolicy {
+		panic("threadpool: keyed executor requires AbortPolicy or CallerRunsPolicy")
+	}
+	return &KeyedExecutor{pool: pool, queues: make(map[string]*keyQueue)}
+}
+
+// Submit 提交键为key的任务,在同一个键之前提交的任务全部执行完后执行
+// 键已有未完成的任务时只加入该键的队列,总是成功;
+// 否则向线程池提交,返回 ErrShutdown 或 ErrRejected,CallerRunsPolicy 下在当前goroutine中执行该键的任务
+// 线程池关闭后总是返回 ErrShutdown
+func (e *KeyedExecutor) Submit(key string, fn func()) error {
+	e.mu.Lock()
+	if e.pool.IsShutdown() {
+		e.prune()
+		e.mu.Unlock()
+		return ErrShutdown
+	}
+	if q, ok := e.queues[key]; ok {
+		q.tasks = append(q.tasks, fn)
+		e.mu.Unlock()
+		return nil
+	}
+	q := &keyQueue{key: key, tasks: []func(){fn}}
+	// 持有锁提交,失败时其他提交者不会看到该键
+	err := e.pool.enqueue(e.drainTask(q))
+	callerRuns := err == ErrRejected && e.pool.options.rejectPolicy == CallerRunsPolicy
+	if err == nil || callerRuns {
+		e.queues[key] = q
+	}
+	e.mu.Unlock()
+	if callerRuns {
+		e.drain(q)
+		return nil
+	}
+	return err
+}
+
+// ActiveKeys 有未完成任务的键数,ShutdownNow 后为0
+func (e *KeyedExecutor) ActiveKeys() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.prune()
+	return len(e.queues)
+}
+
+// prune ShutdownNow 取出的键任务不会再由线程池执行,清空全部键,调用方持有锁
+func (e *KeyedExecutor) prune() {
+	if len(e.queues) > 0 && e.pool.isStopped() {
+		e.queues = make(map[string]*keyQueue)
+	}
+}
+
+// drainTask 执行q中任务的线程池任务
+func (e *KeyedExecutor) drainTask(q *keyQueue) *task {
+	return &task{fn: func() { e.drain(q) }, submitted: e.pool.options.clock.Now()}
+}
+
+// drain 依次执行q中的任务,队列为空时删除键
+// 连续执行 keyedBatch 个任务后重新向线程池排队,排队失败时继续在当前worker中执行,已接收的任务不会丢失
+func (e *KeyedExecutor) drain(q *keyQueue) {
+	for n := 0; ; n++ {
+		e.mu.Lock()
+		if len(q.tasks) == 0 {
+			delete(e.queues, q.key)
+			e.mu.Unlock()
+			return
+		}
+		if n == keyedBatch {
+			n = 0
+			if e.pool.enqueue(e.drainTask(q)) == nil {
+				e.mu.Unlock()
+				return
+			}
+		}
+		fn := q.tasks[0]
+		q.tasks[0] = nil
+		q.tasks = q.tasks[1:]
+		e.mu.Unlock()
+		e.pool.safeRun(&task{fn: fn})
+	}
+}
@@ -0,0 +1,180 @@
+package ThreadPool
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+/****************************************************************
+ * @author: Ihc
+ * @date: 2026/10/20 07:30
+ * @description:
+ ***************************************************************/
+
+func TestKeyedExecutorOrder(t *testing.T) {
+	p := NewThreadPool(WithCoreWorkers(4), WithQueueSize(1000))
+	e := NewKeyedExecutor(p)
+	const keys, perKey = 8, 500
+	var (
+		mu      sync.Mutex
+		got     = make(map[string][]int)
+		running = make([]int32, keys)
+		wg      sync.WaitGroup
+	)
+	for k := 0; k < keys; k++ {
+		wg.Add(1)
+		go func(k int) {
+			defer wg.Done()
+			key := fmt.Sprintf("user-%d", k)
+			for i := 0; i < perKey; i++ {
+				i := i
+				if err := e.Submit(key, func() {
+					if atomic.AddInt32(&running[k], 1) != 1 {
+						t.Error("tasks of the same key overlap")
+					}
+					mu.Lock()
+					got[key] = append(got[key], i)
+					mu.Unlock()
+					atomic.AddInt32(&running[k], -1)
+				}); err != nil {
+					t.Error(err)
+				}
+			}
+		}(k)
+	}
+	wg.Wait()
+	p.Shutdown()
+	awaitTermination(t, p)
+	for k := 0; k < keys; k++ {
+		seq := got[fmt.Sprintf("user-%d", k)]
+		if len(seq) != perKey {
+			t.Fatalf("key %d ran %d tasks", k, len(seq))
+		}
+		for i, v := range seq {
+			if v != i {
+				t.Fatalf("key %d out of order at %d: %d", k, i, v)
+			}
+		}
+	}
+	if e.ActiveKeys() != 0 {
+		t.Fatal("keys must be removed when drained", e.ActiveKeys())
+	}
+}
+
+func TestKeyedExecutorParallelKeys(t *testing.T) {
+	p := NewThreadPool(WithCoreWorkers(2))
+	defer p.Shutdown()
+	e := NewKeyedExecutor(p)
+	started := make(chan string, 3)
+	release := make(chan struct{})
+	for _, key := range []string{"a", "a", "b"} {
+		key := key
+		_ = e.Submit(key, func() {
+			started <- key
+			<-release
+		})
+	}
+	// 两个键同时执行,键a的第二个任务等待第一个完成
+	first, second := <-started, <-started
+	if first == second || e.ActiveKeys() != 2 {
+		t.Fatal("different keys must run in parallel", first, second)
+	}
+	select {
+	case key := <-started:
+		t.Fatal("second task of a started early", key)
+	default:
+	}
+	close(release)
+	if <-started != "a" {
+		t.Fatal("third task")
+	}
+	waitFor(t, "keys drained", func() bool { return e.ActiveKeys() == 0 })
+}
+
+func TestKeyedExecutorPanicAndRejection(t *testing.T) {
+	var panics int32
+	p := NewThreadPool(WithCoreWorkers(1), WithQueueSize(1), WithPanicHandler(func(interface{}, []byte) { atomic.AddInt32(&panics, 1) }))
+	e := NewKeyedExecutor(p)
+	started := make(chan struct{})
+	release := make(chan struct{})
+	_ = e.Submit("a", func() {
+		close(started)
+		<-release
+	})
+	<-started
+	_ = e.Submit("a", func() { panic("boom") })
+	done := make(chan struct{})
+	_ = e.Submit("a", func() { close(done) })
+	// 键b占满队列,键c被拒绝
+	if err := e.Submit("b", func() {}); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Submit("c", func() {}); err != ErrRejected {
+		t.Fatal("got", err)
+	}
+	if e.ActiveKeys() != 2 {
+		t.Fatal("rejected key must not be kept", e.ActiveKeys())
+	}
+	close(release)
+	<-done
+	if atomic.LoadInt32(&panics) != 1 {
+		t.Fatal("panic must be reported", panics)
+	}
+	p.Shutdown()
+	awaitTermination(t, p)
+	if err := e.Submit("d", func() {}); err != ErrShutdown {
+		t.Fatal("got", err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("discard policies must be refused")
+		}
+	}()
+	NewKeyedExecutor(NewThreadPool(WithRejectPolicy(DiscardOldestPolicy)))
+}
+
+func TestKeyedExecutorShutdownNow(t *testing.T) {
+	p := NewThreadPool(WithCoreWorkers(1), WithQueueSize(1))
+	e := NewKeyedExecutor(p)
+	started := make(chan struct{})
+	release := make(chan struct{})
+	_ = e.Submit("a", func() {
+		close(started)
+		<-release
+	})
+	<-started
+	// 键b的任务在队列中,ShutdownNow 后不会再执行
+	ran := int32(0)
+	_ = e.Submit("b", func() { atomic.AddInt32(&ran, 1) })
+	if fns := p.ShutdownNow(); len(fns) != 1 {
+		t.Fatal("drained", len(fns))
+	}
+	for _, key := range []string{"a", "b", "c"} {
+		if err := e.Submit(key, func() { atomic.AddInt32(&ran, 1) }); err != ErrShutdown {
+			t.Fatalf("key %s: got %v", key, err)
+		}
+	}
+	if e.ActiveKeys() != 0 {
+		t.Fatal("keys must be cleared", e.ActiveKeys())
+	}
+	close(release)
+	awaitTermination(t, p)
+	if atomic.LoadInt32(&ran) != 0 {
+		t.Fatal("tasks ran after ShutdownNow", ran)
+	}
+}
+
+func TestKeyedExecutorCallerRuns(t *testing.T) {
+	p, release := saturate(t, CallerRunsPolicy, func() {})
+	e := NewKeyedExecutor(p)
+	ran := false
+	if err := e.Submit("a", func() { ran = true }); err != nil || !ran {
+		t.Fatal("caller must run the key's tasks", err, ran)
+	}
+	close(release)
+	p.Shutdown()
+	awaitTermination(t, p)
+}
@@ -0,0 +1,190 @@
+package ThreadPool
+
+import (
+	"time"
+)
+
+/****************************************************************
+ * @author: Ihc
+ * @date: 2026/10/20 06:20
+ * @description: 线程池指标与自动扩缩容
+Stats 返回当前worker数,正在执行的worker数,队列长度,完成与拒绝的任务数,等待与执行时间的直方图
+开启自动扩缩容后,每个检查周期内队列长度或任务的平均等待时间超过阈值时增加worker(不超过maxWorkers),
+增加的worker空闲keepAlive后退出,线程池缩回coreWorkers
+ ***************************************************************/
+
+// DefaultBuckets 直方图默认的桶上界
+var DefaultBuckets = []time.Duration{
+	100 * time.Microsecond,
+	500 * time.Microsecond,
+	time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+// Histogram 耗时直方图
+// Counts[i]为不大于Bounds[i]且大于Bounds[i-1]的样本数,最后一个元素为大于全部上界的样本数
+type Histogram struct {
+	Bounds []time.Duration // Bounds 递增的桶上界
+	Counts []uint64        // Counts 每个桶的样本数,比Bounds多一个
+	Count  uint64          // Count 样本总数
+	Sum    time.Duration   // Sum 样本总和
+	Max    time.Duration   // Max 最大样本
+}
+
+func newHistogram(bounds []time.Duration) Histogram {
+	return Histogram{Bounds: bounds, Counts: make([]uint64, len(bounds)+1)}
+}
+
+// observe 记录一个样本
+func (h *Histogram) observe(d time.Duration) {
+	i := 0
+	for i < len(h.Bounds) && d > h.Bounds[i] {
+		i++
+	}
+	h.Counts[i]++
+	h.Count++
+	h.Sum += d
+	if d > h.Max {
+		h.Max = d
+	}
+}
+
+// clone 复制计数,Bounds只读可以共享
+func (h *Histogram) clone() Histogram {
+	c := *h
+	c.Counts = make([]uint64, len(h.Counts))
+	copy(c.Counts, h.Counts)
+	return c
+}
+
+// Mean 样本的平均值,没有样本时返回0
+func (h Histogram) Mean() time.Duration {
+	if h.Count == 0 {
+		return 0
+	}
+	return h.Sum / time.Duration(h.Count)
+}
+
+// Quantile 估计q(0到1)分位数,返回所在桶的上界,落在最后一个桶时返回 Max
+func (h Histogram) Quantile(q float64) time.Duration {
+	if h.Count == 0 {
+		return 0
+	}
+	rank := uint64(q * float64(h.Count))
+	if rank >= h.Count {
+		rank = h.Count - 1
+	}
+	var seen uint64
+	for i, count := range h.Counts {
+		seen += count
+		if seen > rank {
+			if i < len(h.Bounds) {
+				return h.Bounds[i]
+			}
+			break
+		}
+	}
+	return h.Max
+}
+
+// Stats 线程池的指标快照
+type Stats struct {
+	PoolSize      int       // PoolSize 当前worker数
+	ActiveWorkers int       // ActiveWorkers 正在执行任务的worker数
+	QueuedTasks   int       // QueuedTasks 队列中等待执行的任务数
+	Completed     uint64    // Completed 已完成的任务数
+	Rejected      uint64    // Rejected 触发 RejectPolicy 的次数,DiscardOldestPolicy 丢弃的任务也计入
+	WaitTime      Histogram // WaitTime 任务从提交到开始执行的时间
+	RunTime       Histogram // RunTime 任务的执行时间
+}
+
+// latencyWindow 一个自动扩缩容检查周期内开始执行的任务的等待时间
+type latencyWindow struct {
+	sum   time.Duration
+	count int64
+}
+
+// autoscaleOptions 自动扩缩容的参数
+type autoscaleOptions struct {
+	interval         time.Duration // interval 检查周期
+	queueThreshold   int           // queueThreshold 队列长度阈值,0表示不按队列长度扩容
+	latencyThreshold time.Duration // latencyThreshold 平均等待时间阈值,0表示不按等待时间扩容
+}
+
+// Stats 返回线程池的指标快照
+func (p *ThreadPool) Stats() Stats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return Stats{
+		PoolSize:      p.workers,
+		ActiveWorkers: p.active,
+		QueuedTasks:   p.queue.len(),
+		Completed:     p.completed,
+		Rejected:      p.rejected,
+		WaitTime:      p.waitTime.clone(),
+		RunTime:       p.runTime.clone(),
+	}
+}
+
+// startMonitors 启动自动扩缩容与指标回调的goroutine
+func (p *ThreadPool) startMonitors() {
+	if a := p.options.autoscale; a.interval > 0 {
+		go p.monitor(a.interval, p.autoscale)
+	}
+	if p.options.statsInterval > 0 {
+		go p.monitor(p.options.statsInterval, func() {
+			p.options.statsCallback(p.Stats())
+		})
+	}
+}
+
+// monitor 每隔interval调用一次fn,线程池关闭后退出
+func (p *ThreadPool) monitor(interval time.Duration, fn func()) {
+	for {
+		timer := p.options.clock.NewTimer(interval)
+		select {
+		case <-timer.C():
+			fn()
+		case <-p.monitorStop:
+			timer.Stop()
+			return
+		}
+	}
+}
+
+// autoscale 队列长度或平均等待时间超过阈值时增加worker
+// 队列长度超过阈值几倍就增加几个worker,只有等待时间超过阈值时增加一个
+func (p *ThreadPool) autoscale() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	window := p.window
+	p.window = latencyWindow{}
+	if p.state != running {
+		return
+	}
+	a := p.options.autoscale
+	queued := p.queue.len()
+	if queued == 0 {
+		return
+	}
+	add := 0
+	if a.queueThreshold > 0 && queued > a.queueThreshold {
+		add = queued / a.queueThreshold
+	}
+	if add == 0 && a.latencyThreshold > 0 && window.count > 0 && window.sum/time.Duration(window.count) > a.latencyThreshold {
+		add = 1
+	}
+	if add > queued {
+		add = queued
+	}
+	for ; add > 0 && p.workers < p.options.maxWorkers; add-- {
+		p.startWorker(nil)
+	}
+}
@@ -0,0 +1,142 @@
+package ThreadPool
+
+import (
+	"testing"
+	"time"
+)
+
+/****************************************************************
+ * @author: Ihc
+ * @date: 2026/10/20 06:40
+ * @description:
+ ***************************************************************/
+
+func TestHistogram(t *testing.T) {
+	h := newHistogram([]time.Duration{time.Millisecond, 10 * time.Millisecond})
+	for _, d := range []time.Duration{0, time.Millisecond, 5 * time.Millisecond, 20 * time.Millisecond} {
+		h.observe(d)
+	}
+	if h.Counts[0] != 2 || h.Counts[1] != 1 || h.Counts[2] != 1 || h.Count != 4 || h.Max != 20*time.Millisecond {
+		t.Fatal(h)
+	}
+	if h.Mean() != 6500*time.Microsecond {
+		t.Fatal("mean", h.Mean())
+	}
+	if h.Quantile(0.5) != 10*time.Millisecond || h.Quantile(0.1) != time.Millisecond || h.Quantile(1) != 20*time.Millisecond {
+		t.Fatal("quantile", h.Quantile(0.5), h.Quantile(0.1), h.Quantile(1))
+	}
+	if (Histogram{}).Quantile(0.5) != 0 || (Histogram{}).Mean() != 0 {
+		t.Fatal("empty histogram")
+	}
+}
+
+func TestStats(t *testing.T) {
+	clock := newFakeClock()
+	p := NewThreadPool(WithCoreWorkers(1), WithQueueSize(1), WithClock(clock))
+	started := make(chan struct{})
+	release := make(chan struct{})
+	_ = p.Submit(func() {
+		close(started)
+		<-release
+		clock.Advance(30 * time.Millisecond)
+	})
+	<-started
+	_ = p.Submit(func() {})
+	if err := p.Submit(func() {}); err != ErrRejected {
+		t.Fatal(err)
+	}
+	stats := p.Stats()
+	if stats.PoolSize != 1 || stats.ActiveWorkers != 1 || stats.QueuedTasks != 1 || stats.Rejected != 1 || stats.Completed != 0 {
+		t.Fatalf("%+v", stats)
+	}
+	close(release)
+	p.Shutdown()
+	awaitTermination(t, p)
+
+	stats = p.Stats()
+	if stats.Completed != 2 || stats.QueuedTasks != 0 || stats.ActiveWorkers != 0 {
+		t.Fatalf("%+v", stats)
+	}
+	// 第一个任务执行30ms,第二个任务在队列中等待30ms
+	if stats.RunTime.Count != 2 || stats.RunTime.Max != 30*time.Millisecond || stats.RunTime.Counts[0] != 1 {
+		t.Fatalf("run time %+v", stats.RunTime)
+	}
+	if stats.WaitTime.Count != 2 || stats.WaitTime.Max != 30*time.Millisecond || stats.WaitTime.Sum != 30*time.Millisecond {
+		t.Fatalf("wait time %+v", stats.WaitTime)
+	}
+	// 快照不随线程池变化
+	stats.RunTime.Counts[0] = 100
+	if p.Stats().RunTime.Counts[0] != 1 {
+		t.Fatal("snapshot shares counts")
+	}
+}
+
+func TestStatsCallback(t *testing.T) {
+	clock := newFakeClock()
+	received := make(chan Stats, 1)
+	p := NewThreadPool(WithClock(clock), WithStatsCallback(time.Second, func(stats Stats) { received <- stats }))
+	_ = p.Submit(func() {})
+	waitFor(t, "completed", func() bool { return p.CompletedCount() == 1 })
+	clock.waitTimer(t, epoch.Add(time.Second))
+	clock.Advance(time.Second)
+	if stats := <-received; stats.Completed != 1 || stats.PoolSize != 1 {
+		t.Fatalf("%+v", stats)
+	}
+	clock.waitTimer(t, epoch.Add(2*time.Second))
+	p.Shutdown()
+	waitFor(t, "callback stopped", func() bool { return len(clock.Timers()) == 0 })
+}
+
+func TestAutoscaleByQueueDepth(t *testing.T) {
+	clock := newFakeClock()
+	p := NewThreadPool(WithCoreWorkers(1), WithMaxWorkers(4), WithQueueSize(100),
+		WithKeepAlive(10*time.Millisecond), WithClock(clock), WithAutoscale(time.Second, 2, 0))
+	release := make(chan struct{})
+	for i := 0; i < 10; i++ {
+		_ = p.Submit(func() { <-release })
+	}
+	if p.PoolSize() != 1 {
+		t.Fatal("queue is not full, must not grow yet", p.PoolSize())
+	}
+	clock.waitTimer(t, epoch.Add(time.Second))
+	clock.Advance(time.Second)
+	waitFor(t, "grow to max workers", func() bool { return p.PoolSize() == 4 })
+	close(release)
+	// 增加的worker空闲keepAlive后退出
+	waitFor(t, "shrink to core workers", func() bool { return p.PoolSize() == 1 })
+	if p.CompletedCount() != 10 {
+		t.Fatal(p.CompletedCount())
+	}
+	p.Shutdown()
+	awaitTermination(t, p)
+}
+
+func TestAutoscaleByLatency(t *testing.T) {
+	clock := newFakeClock()
+	p := NewThreadPool(WithCoreWorkers(1), WithMaxWorkers(2), WithClock(clock), WithAutoscale(time.Second, 0, 100*time.Millisecond))
+	defer p.Shutdown()
+	// block 提交一个阻塞worker的任务,返回释放函数
+	block := func() func() {
+		started := make(chan struct{})
+		release := make(chan struct{})
+		_ = p.Submit(func() {
+			close(started)
+			<-release
+		})
+		<-started
+		return func() { close(release) }
+	}
+	clock.waitTimer(t, epoch.Add(time.Second))
+	release := block()
+	done := make(chan struct{})
+	_ = p.Submit(func() { close(done) })
+	clock.Advance(500 * time.Millisecond)
+	release()
+	<-done
+	// 第二个任务等待了500ms,下一次检查时队列中还有任务
+	release = block()
+	defer release()
+	_ = p.Submit(func() {})
+	clock.Advance(500 * time.Millisecond)
+	waitFor(t, "grow by latency", func() bool { return p.PoolSize() == 2 })
+}
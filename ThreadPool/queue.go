@@ -0,0 +1,234 @@
+package ThreadPool
+
+import (
+	"sync"
+	"time"
+)
+
+/****************************************************************
+ * @author: Ihc
+ * @date: 2026/10/20 03:10
+ * @description: 有界任务队列
+支持先入先出与按优先级出队两种方式
+队列中的每个任务对应tokens中的一个令牌,取任务前先取令牌,
+因此等待任务可以与超时,关闭一起select,不需要条件变量
+ ***************************************************************/
+
+// task 提交到线程池的任务
+type task struct {
+	fn       func()
+	priority int    // priority 优先级,数值越大越先执行,只在优先级队列中有效
+	seq      uint64 // seq 入队序号,同优先级的任务先入先出
+
+	submitted time.Time // submitted 提交时间,用于统计等待时间
+	discarded func()    // discarded 任务被 DiscardOldestPolicy 丢弃时调用,调用方持有线程池的锁
+}
+
+// pollResult 取任务的结果
+type pollResult int
+
+const (
+	// polled 取到任务
+	polled pollResult = iota
+	// pollTimeout 等待超时
+	pollTimeout
+	// pollClosed 队列已关闭且为空
+	pollClosed
+)
+
+// taskStore 任务的存储方式,决定出队顺序
+type taskStore interface {
+	push(t *task)
+	pop() *task
+	len() int
+}
+
+// fifoStore 先入先出的环形缓冲区
+type fifoStore struct {
+	items []*task
+	head  int
+	count int
+}
+
+func (s *fifoStore) push(t *task) {
+	s.items[(s.head+s.count)%len(s.items)] = t
+	s.count++
+}
+
+func (s *fifoStore) pop() *task {
+	t := s.items[s.head]
+	s.items[s.head] = nil
+	s.head = (s.head + 1) % len(s.items)
+	s.count--
+	return t
+}
+
+func (s *fifoStore) len() int {
+	return s.count
+}
+
+// priorityStore 按优先级出队的二叉堆
+type priorityStore struct {
+	items []*task
+}
+
+// less 优先级高的在前,优先级相同时先入队的在前
+func (s *priorityStore) less(i, j int) bool {
+	a, b := s.items[i], s.items[j]
+	if a.priority != b.priority {
+		return a.priority > b.priority
+	}
+	return a.seq < b.seq
+}
+
+func (s *priorityStore) push(t *task) {
+	s.items = append(s.items, t)
+	for i := len(s.items) - 1; i > 0; {
+		parent := (i - 1) / 2
+		if !s.less(i, parent) {
+			break
+		}
+		s.items[i], s.items[parent] = s.items[parent], s.items[i]
+		i = parent
+	}
+}
+
+func (s *priorityStore) pop() *task {
+	n := len(s.items) - 1
+	t := s.items[0]
+	s.items[0] = s.items[n]
+	s.items[n] = nil
+	s.items = s.items[:n]
+	for i := 0; ; {
+		smallest := i
+		if l := 2*i + 1; l < n && s.less(l, smallest) {
+			smallest = l
+		}
+		if r := 2*i + 2; r < n && s.less(r, smallest) {
+			smallest = r
+		}
+		if smallest == i {
+			break
+		}
+		s.items[i], s.items[smallest] = s.items[smallest], s.items[i]
+		i = smallest
+	}
+	return t
+}
+
+func (s *priorityStore) len() int {
+	return len(s.items)
+}
+
+// taskQueue 有界任务队列,并发安全
+type taskQueue struct {
+	mu     sync.Mutex
+	store  taskStore
+	size   int           // size 队列容量
+	seq    uint64        // seq 下一个入队序号
+	tokens chan struct{} // tokens 令牌数不超过任务数
+	closed chan struct{} // closed 关闭后等待的poll立即返回
+	once   sync.Once
+}
+
+// newTaskQueue 创建队列,priority为true时按优先级出队,否则先入先出
+func newTaskQueue(size int, priority bool) *taskQueue {
+	var store taskStore = &fifoStore{items: make([]*task, size)}
+	if priority {
+		store = &priorityStore{items: make([]*task, 0, size)}
+	}
+	return &taskQueue{
+		store:  store,
+		size:   size,
+		tokens: make(chan struct{}, size),
+		closed: make(chan struct{}),
+	}
+}
+
+// offer 放入任务,队列满时返回false
+func (q *taskQueue) offer(t *task) bool {
+	q.mu.Lock()
+	if q.store.len() == q.size {
+		q.mu.Unlock()
+		return false
+	}
+	t.seq = q.seq
+	q.seq++
+	q.store.push(t)
+	q.mu.Unlock()
+	q.tokens <- struct{}{}
+	return true
+}
+
+// pop 取出下一个任务,调用方已取得令牌
+func (q *taskQueue) pop() *task {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.store.pop()
+}
+
+// poll 等待并取出任务,timeout不大于0时一直等待
+// 关闭后先取完剩余任务再返回 pollClosed
+func (q *taskQueue) poll(timeout time.Duration) (*task, pollResult) {
+	select {
+	case <-q.tokens:
+		return q.pop(), polled
+	default:
+	}
+	var timer <-chan time.Time
+	if timeout > 0 {
+		t := time.NewTimer(timeout)
+		defer t.Stop()
+		timer = t.C
+	}
+	select {
+	case <-q.tokens:
+		return q.pop(), polled
+	case <-timer:
+		return nil, pollTimeout
+	case <-q.closed:
+		select {
+		case <-q.tokens:
+			return q.pop(), polled
+		default:
+			return nil, pollClosed
+		}
+	}
+}
+
+// discardOldest 丢弃下一个将要执行的任务(先入先出队列中为最旧的任务),队列为空时返回nil
+func (q *taskQueue) discardOldest() *task {
+	select {
+	case <-q.tokens:
+		return q.pop()
+	default:
+		return nil
+	}
+}
+
+// drain 取出全部任务
+func (q *taskQueue) drain() []*task {
+	var tasks []*task
+	for {
+		select {
+		case <-q.tokens:
+			tasks = append(tasks, q.pop())
+		default:
+			return tasks
+		}
+	}
+}
+
+// len 队列中的任务数
+func (q *taskQueue) len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.store.len()
+}
+
+// close 关闭队列,唤醒全部等待的poll
+func (q *taskQueue) close() {
+	q.once.Do(func() {
+		close(q.closed)
+	})
+}
@@ -0,0 +1,144 @@
+package ThreadPool
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+/****************************************************************
+ * @author: Ihc
+ * @date: 2026/10/20 04:10
+ * @description:
+ ***************************************************************/
+
+// saturate 创建一个worker与容量为1的队列的线程池,worker阻塞在release上,队列中放入first
+func saturate(t *testing.T, policy RejectPolicy, first func()) (*ThreadPool, chan struct{}) {
+	p := NewThreadPool(WithCoreWorkers(1), WithQueueSize(1), WithRejectPolicy(policy))
+	started := make(chan struct{})
+	release := make(chan struct{})
+	if err := p.Submit(func() { close(started); <-release }); err != nil {
+		t.Fatal(err)
+	}
+	<-started
+	if err := p.Submit(first); err != nil {
+		t.Fatal(err)
+	}
+	return p, release
+}
+
+func TestAbortPolicy(t *testing.T) {
+	p, release := saturate(t, AbortPolicy, func() {})
+	if err := p.Submit(func() {}); err != ErrRejected {
+		t.Fatalf("got %v", err)
+	}
+	if _, err := p.SubmitWithResult(func() (interface{}, error) { return nil, nil }); err != ErrRejected {
+		t.Fatalf("got %v", err)
+	}
+	close(release)
+	p.Shutdown()
+	awaitTermination(t, p)
+}
+
+func TestCallerRunsPolicy(t *testing.T) {
+	p, release := saturate(t, CallerRunsPolicy, func() {})
+	ran := false
+	if err := p.Submit(func() { ran = true }); err != nil || !ran {
+		t.Fatalf("caller did not run task: %v", err)
+	}
+	close(release)
+	p.Shutdown()
+	awaitTermination(t, p)
+}
+
+func TestDiscardPolicy(t *testing.T) {
+	var ran int64
+	p, release := saturate(t, DiscardPolicy, func() { atomic.AddInt64(&ran, 1) })
+	if err := p.Submit(func() { atomic.AddInt64(&ran, 10) }); err != nil {
+		t.Fatal(err)
+	}
+	close(release)
+	p.Shutdown()
+	awaitTermination(t, p)
+	if ran != 1 {
+		t.Fatalf("ran %d", ran)
+	}
+}
+
+func TestDiscardOldestPolicy(t *testing.T) {
+	var order []int
+	var mu sync.Mutex
+	record := func(i int) func() {
+		return func() {
+			mu.Lock()
+			order = append(order, i)
+			mu.Unlock()
+		}
+	}
+	p, release := saturate(t, DiscardOldestPolicy, record(1))
+	for i := 2; i <= 4; i++ {
+		if err := p.Submit(record(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	close(release)
+	p.Shutdown()
+	awaitTermination(t, p)
+	if len(order) != 1 || order[0] != 4 {
+		t.Fatalf("order %v", order)
+	}
+}
+
+func TestPanicIsolation(t *testing.T) {
+	var (
+		mu        sync.Mutex
+		recovered []interface{}
+	)
+	p := NewThreadPool(WithCoreWorkers(2), WithPanicHandler(func(r interface{}, stack []byte) {
+		mu.Lock()
+		recovered = append(recovered, r)
+		mu.Unlock()
+		if len(stack) == 0 {
+			t.Error("empty stack")
+		}
+	}))
+	var ran int64
+	for i := 0; i < 10; i++ {
+		_ = p.Submit(func() { panic("boom") })
+		_ = p.Submit(func() { atomic.AddInt64(&ran, 1) })
+	}
+	future, err := p.SubmitWithResult(func() (interface{}, error) { panic("future boom") })
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = future.Get(context.Background())
+	if pe, ok := err.(*PanicError); !ok || pe.Recovered != "future boom" {
+		t.Fatalf("got %v", err)
+	}
+	if p.PoolSize() != 2 {
+		t.Fatalf("pool size %d", p.PoolSize())
+	}
+	p.Shutdown()
+	awaitTermination(t, p)
+	if ran != 10 || len(recovered) != 11 || p.CompletedCount() != 21 {
+		t.Fatalf("ran %d, recovered %d, completed %d", ran, len(recovered), p.CompletedCount())
+	}
+}
+
+func TestCallerRunsPanic(t *testing.T) {
+	var recovered interface{}
+	p := NewThreadPool(WithCoreWorkers(1), WithQueueSize(1), WithRejectPolicy(CallerRunsPolicy),
+		WithPanicHandler(func(r interface{}, _ []byte) { recovered = r }))
+	started := make(chan struct{})
+	release := make(chan struct{})
+	_ = p.Submit(func() { close(started); <-release })
+	<-started
+	_ = p.Submit(func() {})
+	if err := p.Submit(func() { panic("in caller") }); err != nil || recovered != "in caller" {
+		t.Fatalf("err %v, recovered %v", err, recovered)
+	}
+	close(release)
+	p.Shutdown()
+	awaitTermination(t, p)
+}
@@ -0,0 +1,343 @@
+package ThreadPool
+
+import (
+	"errors"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	clock "preseus/Clock"
+)
+
+/****************************************************************
+ * @author: Ihc
+ * @date: 2026/10/20 04:40
+ * @description: 延迟与周期任务
+一个调度goroutine按执行时间维护最小堆,到期后把任务提交到线程池执行;
+周期任务在本次执行结束后才安排下一次,下一次的时间为上一次的计划时间加周期,
+执行时间超过周期时下一次立即执行,同一个周期任务不会并发执行
+到期的任务直接放入线程池,不经过 RejectPolicy:被拒绝或被 DiscardOldestPolicy 丢弃时以 ErrRejected 结束,
+不会被静默丢弃,也不会在调度goroutine中执行而阻塞其他任务
+线程池关闭时取消全部尚未执行的延迟与周期任务
+ ***************************************************************/
+
+// ErrCancelled 延迟或周期任务已被取消
+var ErrCancelled = errors.New("threadpool: scheduled task cancelled")
+
+// Clock 调度使用的时钟,见 Clock.Clock
+type Clock = clock.Clock
+
+// Timer 定时器,见 Clock.Timer
+type Timer = clock.Timer
+
+// realClock 系统时钟
+type realClock = clock.RealClock
+
+// ScheduledTask 延迟或周期任务
+type ScheduledTask struct {
+	pool   *ThreadPool
+	fn     func()
+	when   time.Time     // when 下一次执行的计划时间
+	period time.Duration // period 执行周期,一次性任务为0
+	index  int           // index 在堆中的位置,不在堆中时为-1
+
+	mu        sync.Mutex
+	cancelled bool
+	done      chan struct{}
+	err       error
+	once      sync.Once
+}
+
+// finish 结束任务,之后不会再执行
+func (st *ScheduledTask) finish(err error) {
+	st.once.Do(func() {
+		st.mu.Lock()
+		st.err = err
+		st.mu.Unlock()
+		close(st.done)
+	})
+}
+
+// Cancel 取消任务,正在执行的那一次不受影响;任务已结束时返回false
+func (st *ScheduledTask) Cancel() bool {
+	s := st.pool.scheduler()
+	s.mu.Lock()
+	if st.index >= 0 {
+		s.remove(st.index)
+	}
+	s.mu.Unlock()
+	st.mu.Lock()
+	finished := st.isDone()
+	st.cancelled = true
+	st.mu.Unlock()
+	st.finish(ErrCancelled)
+	return !finished
+}
+
+// isCancelled 是否已取消
+func (st *ScheduledTask) isCancelled() bool {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return st.cancelled
+}
+
+func (st *ScheduledTask) isDone() bool {
+	select {
+	case <-st.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Done 任务结束时关闭: 一次性任务执行完,周期任务panic,任务被取消,被线程池拒绝,或线程池关闭
+func (st *ScheduledTask) Done() <-chan struct{} {
+	return st.done
+}
+
+// Err 任务结束的原因,一次性任务正常执行完时为nil
+func (st *ScheduledTask) Err() error {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return st.err
+}
+
+// scheduler 调度goroutine的状态
+type scheduler struct {
+	pool    *ThreadPool
+	clock   Clock
+	mu      sync.Mutex
+	heap    []*ScheduledTask // heap 按when排序的最小堆
+	stopped bool
+	wake    chan struct{} // wake 堆顶变化时唤醒调度goroutine
+	done    chan struct{}
+}
+
+// scheduler 返回调度器,第一次调用时启动调度goroutine
+func (p *ThreadPool) scheduler() *scheduler {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.sched == nil {
+		p.sched = &scheduler{
+			pool:  p,
+			clock: p.options.clock,
+			wake:  make(chan struct{}, 1),
+			done:  make(chan struct{}),
+		}
+		if p.state != running {
+			p.sched.stopped = true
+			close(p.sched.done)
+		} else {
+			go p.sched.loop()
+		}
+	}
+	return p.sched
+}
+
+// Schedule 在delay后执行一次fn
+func (p *ThreadPool) Schedule(fn func(), delay time.Duration) (*ScheduledTask, error) {
+	return p.schedule(fn, delay, 0)
+}
+
+// ScheduleAtFixedRate 在initialDelay后第一次执行fn,之后每period执行一次
+// 直到取消,fn发生panic,或线程池关闭
+func (p *ThreadPool) ScheduleAtFixedRate(fn func(), initialDelay, period time.Duration) (*ScheduledTask, error) {
+	if period <= 0 {
+		return nil, errors.New("threadpool: period is not > 0")
+	}
+	return p.schedule(fn, initialDelay, period)
+}
+
+func (p *ThreadPool) schedule(fn func(), delay, period time.Duration) (*ScheduledTask, error) {
+	s := p.scheduler()
+	st := &ScheduledTask{
+		pool:   p,
+		fn:     fn,
+		when:   s.clock.Now().Add(delay),
+		period: period,
+		index:  -1,
+		done:   make(chan struct{}),
+	}
+	if err := s.add(st); err != nil {
+		return nil, err
+	}
+	return st, nil
+}
+
+// add 加入堆,调度器已停止时返回 ErrShutdown
+func (s *scheduler) add(st *ScheduledTask) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopped {
+		return ErrShutdown
+	}
+	st.index = len(s.heap)
+	s.heap = append(s.heap, st)
+	s.up(st.index)
+	if st.index == 0 {
+		select {
+		case s.wake <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// loop 等待堆顶到期后提交到线程池
+func (s *scheduler) loop() {
+	for {
+		s.mu.Lock()
+		now := s.clock.Now()
+		var due []*ScheduledTask
+		for len(s.heap) > 0 && !s.heap[0].when.After(now) {
+			due = append(due, s.heap[0])
+			s.remove(0)
+		}
+		wait := time.Duration(-1)
+		if len(s.heap) > 0 {
+			wait = s.heap[0].when.Sub(now)
+		}
+		s.mu.Unlock()
+
+		for _, st := range due {
+			s.fire(st)
+		}
+		if len(due) > 0 {
+			continue
+		}
+
+		var (
+			timer  Timer
+			timerC <-chan time.Time
+		)
+		if wait >= 0 {
+			timer = s.clock.NewTimer(wait)
+			timerC = timer.C()
+		}
+		select {
+		case <-timerC:
+		case <-s.wake:
+		case <-s.done:
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		select {
+		case <-s.done:
+			return
+		default:
+		}
+	}
+}
+
+// fire 把到期的任务放入线程池,被拒绝或排队后被丢弃时结束任务
+func (s *scheduler) fire(st *ScheduledTask) {
+	if st.isCancelled() {
+		return
+	}
+	t := &task{
+		fn:        func() { s.run(st) },
+		submitted: s.clock.Now(),
+		discarded: func() { st.finish(ErrRejected) },
+	}
+	if err := s.pool.enqueue(t); err != nil {
+		st.finish(err)
+	}
+}
+
+// run 在worker中执行任务,周期任务执行完后安排下一次
+func (s *scheduler) run(st *ScheduledTask) {
+	if st.isCancelled() {
+		return
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			stack := debug.Stack()
+			st.finish(&PanicError{Recovered: r, Stack: stack})
+			s.pool.options.panicHandler(r, stack)
+		}
+	}()
+	st.fn()
+	if st.period <= 0 {
+		st.finish(nil)
+		return
+	}
+	st.when = st.when.Add(st.period)
+	if st.isCancelled() {
+		return
+	}
+	if err := s.add(st); err != nil {
+		st.finish(err)
+	}
+}
+
+// stop 停止调度,取消全部尚未执行的任务
+func (s *scheduler) stop() {
+	s.mu.Lock()
+	if s.stopped {
+		s.mu.Unlock()
+		return
+	}
+	s.stopped = true
+	pending := s.heap
+	for _, st := range pending {
+		st.index = -1
+	}
+	s.heap = nil
+	close(s.done)
+	s.mu.Unlock()
+	for _, st := range pending {
+		st.finish(ErrShutdown)
+	}
+}
+
+// remove 删除堆中位置i的任务,调用方持有锁
+func (s *scheduler) remove(i int) {
+	n := len(s.heap) - 1
+	removed := s.heap[i]
+	if i != n {
+		s.swap(i, n)
+	}
+	s.heap[n] = nil
+	s.heap = s.heap[:n]
+	removed.index = -1
+	if i != n {
+		s.down(i)
+		s.up(i)
+	}
+}
+
+func (s *scheduler) swap(i, j int) {
+	s.heap[i], s.heap[j] = s.heap[j], s.heap[i]
+	s.heap[i].index = i
+	s.heap[j].index = j
+}
+
+func (s *scheduler) up(i int) {
+	for i > 0 {
+		parent := (i - 1) / 2
+		if !s.heap[i].when.Before(s.heap[parent].when) {
+			break
+		}
+		s.swap(i, parent)
+		i = parent
+	}
+}
+
+func (s *scheduler) down(i int) {
+	n := len(s.heap)
+	for {
+		smallest := i
+		if l := 2*i + 1; l < n && s.heap[l].when.Before(s.heap[smallest].when) {
+			smallest = l
+		}
+		if r := 2*i + 2; r < n && s.heap[r].when.Before(s.heap[smallest].when) {
+			smallest = r
+		}
+		if smallest == i {
+			return
+		}
+		s.swap(i, smallest)
+		i = smallest
+	}
+}
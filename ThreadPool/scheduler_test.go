@@ -0,0 +1,248 @@
+package ThreadPool
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"preseus/internal/fakeclock"
+)
+
+/****************************************************************
+ * @author: Ihc
+ * @date: 2026/10/20 05:10
+ * @description:
+ ***************************************************************/
+
+var epoch = time.Date(2026, 10, 20, 5, 0, 0, 0, time.UTC)
+
+// fakeClock 手动推进的时钟
+type fakeClock struct {
+	*fakeclock.FakeClock
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{fakeclock.New(epoch)}
+}
+
+// waitTimer 等待出现在when触发的定时器,即调度goroutine已开始等待该时间
+func (c *fakeClock) waitTimer(t *testing.T, when time.Time) {
+	t.Helper()
+	waitFor(t, "timer at "+when.Format(time.RFC3339Nano), func() bool {
+		for _, timer := range c.Timers() {
+			if timer.Equal(when) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+func TestSchedule(t *testing.T) {
+	clock := newFakeClock()
+	p := NewThreadPool(WithCoreWorkers(2), WithClock(clock))
+	defer p.Shutdown()
+	ran := make(chan string, 2)
+	a, err := p.Schedule(func() { ran <- "a" }, 30*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := p.Schedule(func() { ran <- "b" }, 10*time.Millisecond)
+
+	clock.waitTimer(t, epoch.Add(10*time.Millisecond))
+	clock.Advance(10 * time.Millisecond)
+	if got := <-ran; got != "b" {
+		t.Fatalf("got %s", got)
+	}
+	<-b.Done()
+	if b.Err() != nil || a.isDone() {
+		t.Fatalf("b err %v, a done %v", b.Err(), a.isDone())
+	}
+	clock.waitTimer(t, epoch.Add(30*time.Millisecond))
+	clock.Advance(20 * time.Millisecond)
+	if got := <-ran; got != "a" {
+		t.Fatalf("got %s", got)
+	}
+	<-a.Done()
+	if a.Cancel() {
+		t.Fatal("cancel after done")
+	}
+}
+
+func TestScheduleAtFixedRate(t *testing.T) {
+	clock := newFakeClock()
+	p := NewThreadPool(WithCoreWorkers(1), WithClock(clock))
+	defer p.Shutdown()
+	ran := make(chan time.Time, 10)
+	st, err := p.ScheduleAtFixedRate(func() { ran <- clock.Now() }, 5*time.Millisecond, 10*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		when := epoch.Add(5*time.Millisecond + time.Duration(i)*10*time.Millisecond)
+		clock.waitTimer(t, when)
+		clock.Advance(when.Sub(clock.Now()))
+		if got := <-ran; !got.Equal(when) {
+			t.Fatalf("run %d at %v, want %v", i, got, when)
+		}
+	}
+	clock.waitTimer(t, epoch.Add(35*time.Millisecond))
+	if !st.Cancel() {
+		t.Fatal("cancel failed")
+	}
+	<-st.Done()
+	clock.Advance(time.Second)
+	if st.Err() != ErrCancelled || len(ran) != 0 {
+		t.Fatalf("err %v, extra runs %d", st.Err(), len(ran))
+	}
+}
+
+func TestFixedRateCatchUp(t *testing.T) {
+	clock := newFakeClock()
+	p := NewThreadPool(WithCoreWorkers(2), WithClock(clock))
+	defer p.Shutdown()
+	var (
+		mu   sync.Mutex
+		runs []time.Duration
+	)
+	ran := make(chan struct{}, 10)
+	_, _ = p.ScheduleAtFixedRate(func() {
+		mu.Lock()
+		runs = append(runs, clock.Now().Sub(epoch))
+		first := len(runs) == 1
+		mu.Unlock()
+		// 第一次执行耗时25ms,错过的两次立即补上,之后回到原来的节奏
+		if first {
+			clock.Advance(25 * time.Millisecond)
+		}
+		ran <- struct{}{}
+	}, 10*time.Millisecond, 10*time.Millisecond)
+	clock.waitTimer(t, epoch.Add(10*time.Millisecond))
+	clock.Advance(10 * time.Millisecond)
+	for i := 0; i < 3; i++ {
+		<-ran
+	}
+	clock.waitTimer(t, epoch.Add(40*time.Millisecond))
+	clock.Advance(5 * time.Millisecond)
+	<-ran
+	mu.Lock()
+	defer mu.Unlock()
+	want := []time.Duration{10, 35, 35, 40}
+	for i := range want {
+		if runs[i] != want[i]*time.Millisecond {
+			t.Fatalf("runs %v", runs)
+		}
+	}
+}
+
+func TestScheduleCancelAndShutdown(t *testing.T) {
+	clock := newFakeClock()
+	p := NewThreadPool(WithClock(clock))
+	ran := false
+	cancelled, _ := p.Schedule(func() { ran = true }, time.Second)
+	pending, _ := p.ScheduleAtFixedRate(func() { ran = true }, time.Second, time.Second)
+	if !cancelled.Cancel() || cancelled.Err() != ErrCancelled {
+		t.Fatalf("cancel: %v", cancelled.Err())
+	}
+	p.Shutdown()
+	<-pending.Done()
+	if pending.Err() != ErrShutdown {
+		t.Fatalf("pending err %v", pending.Err())
+	}
+	if _, err := p.Schedule(func() {}, 0); err != ErrShutdown {
+		t.Fatalf("schedule after shutdown: %v", err)
+	}
+	clock.Advance(time.Hour)
+	awaitTermination(t, p)
+	if ran {
+		t.Fatal("cancelled task ran")
+	}
+	if _, err := NewThreadPool().ScheduleAtFixedRate(func() {}, 0, 0); err == nil {
+		t.Fatal("expect error for zero period")
+	}
+}
+
+func TestFixedRatePanicStops(t *testing.T) {
+	clock := newFakeClock()
+	handled := make(chan interface{}, 1)
+	p := NewThreadPool(WithClock(clock), WithPanicHandler(func(r interface{}, _ []byte) { handled <- r }))
+	defer p.Shutdown()
+	st, _ := p.ScheduleAtFixedRate(func() { panic("tick") }, 0, time.Second)
+	<-st.Done()
+	if pe, ok := st.Err().(*PanicError); !ok || pe.Recovered != "tick" || <-handled != "tick" {
+		t.Fatalf("err %v", st.Err())
+	}
+}
+
+func TestScheduledRunRejected(t *testing.T) {
+	// 到期的任务被拒绝时以 ErrRejected 结束,不执行也不被静默丢弃
+	for _, policy := range []RejectPolicy{AbortPolicy, CallerRunsPolicy, DiscardPolicy} {
+		p, release := saturate(t, policy, func() {})
+		ran := make(chan struct{}, 1)
+		st, err := p.ScheduleAtFixedRate(func() { ran <- struct{}{} }, 0, time.Hour)
+		if err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case <-st.Done():
+		case <-time.After(5 * time.Second):
+			t.Fatalf("policy %d: scheduled task not finished", policy)
+		}
+		if st.Err() != ErrRejected || len(ran) != 0 {
+			t.Fatalf("policy %d: err %v, ran %d", policy, st.Err(), len(ran))
+		}
+		close(release)
+		p.Shutdown()
+		awaitTermination(t, p)
+	}
+
+	// 已排队的任务被 DiscardOldestPolicy 丢弃
+	p := NewThreadPool(WithCoreWorkers(1), WithQueueSize(1), WithRejectPolicy(DiscardOldestPolicy))
+	started := make(chan struct{})
+	release := make(chan struct{})
+	_ = p.Submit(func() { close(started); <-release })
+	<-started
+	st, _ := p.ScheduleAtFixedRate(func() {}, 0, time.Hour)
+	waitFor(t, "scheduled run queued", func() bool { return p.QueueLen() == 1 })
+	if err := p.Submit(func() {}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-st.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("discarded scheduled task not finished")
+	}
+	if st.Err() != ErrRejected {
+		t.Fatalf("err %v", st.Err())
+	}
+	close(release)
+	p.Shutdown()
+	awaitTermination(t, p)
+}
+
+func TestPriorityQueue(t *testing.T) {
+	p := NewThreadPool(WithCoreWorkers(1), WithPriorityQueue(true))
+	started := make(chan struct{})
+	release := make(chan struct{})
+	_ = p.Submit(func() { close(started); <-release })
+	<-started
+	var order []int
+	record := func(i int) func() {
+		return func() { order = append(order, i) }
+	}
+	_ = p.SubmitWithPriority(record(1), 1)
+	_ = p.SubmitWithPriority(record(5), 5)
+	_ = p.Submit(record(0))
+	_ = p.SubmitWithPriority(record(3), 3)
+	_ = p.SubmitWithPriority(record(50), 5)
+	_ = p.SubmitWithPriority(record(-1), -1)
+	close(release)
+	p.Shutdown()
+	awaitTermination(t, p)
+	want := []int{5, 50, 3, 1, 0, -1}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatalf("order %v, want %v", order, want)
+		}
+	}
+}
@@ -1,7 +1,532 @@
 package ThreadPool
 
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
 /****************************************************************
  * @author: Ihc
  * @date: 2022/4/19 22:47
  * @description: goroutine池(线程池模型)
+提交任务时的处理与Java的ThreadPoolExecutor一致:
+	1.worker数小于coreWorkers时,创建新worker执行该任务
+	2.否则放入有界队列
+	3.队列已满且worker数小于maxWorkers时,创建新worker执行该任务
+	4.否则按 RejectPolicy 处理
+超过coreWorkers的worker空闲keepAlive后退出
+Shutdown后不再接收任务,已提交的任务继续执行;ShutdownNow还会取出队列中尚未执行的任务
+任务panic时恢复并交给 PanicHandler,worker继续执行后续任务,线程池大小不变
+队列可以按优先级出队;Schedule与ScheduleAtFixedRate提交延迟与周期任务
+递归拆分的任务使用工作窃取的 ForkJoinPool
+可以按队列长度与等待时间自动扩容;Stats 返回指标快照
+KeyedExecutor 在线程池上按键串行执行任务
  ***************************************************************/
+
+var (
+	// ErrShutdown 线程池已关闭
+	ErrShutdown = errors.New("threadpool: pool is shut down")
+	// ErrRejected 队列已满且worker数已达上限
+	ErrRejected = errors.New("threadpool: task rejected, queue is full")
+)
+
+// RejectPolicy 队列已满且worker数已达上限时的处理方式
+type RejectPolicy int
+
+const (
+	// AbortPolicy 返回 ErrRejected
+	AbortPolicy RejectPolicy = iota
+	// CallerRunsPolicy 在提交任务的goroutine中直接执行,同时减缓提交速度
+	CallerRunsPolicy
+	// DiscardPolicy 丢弃任务,不返回错误
+	DiscardPolicy
+	// DiscardOldestPolicy 丢弃队列中最旧的任务后重新提交
+	DiscardOldestPolicy
+)
+
+// PanicHandler 处理任务中的panic,recovered为recover()的返回值,stack为panic时的调用栈
+type PanicHandler func(recovered interface{}, stack []byte)
+
+// PanicError SubmitWithResult 提交的任务panic时 Future 返回的错误
+type PanicError struct {
+	Recovered interface{}
+	Stack     []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("threadpool: task panicked: %v", e.Recovered)
+}
+
+// defaultPanicHandler 将panic与调用栈输出到标准错误
+func defaultPanicHandler(recovered interface{}, stack []byte) {
+	fmt.Fprintf(os.Stderr, "threadpool: task panicked: %v\n%s", recovered, stack)
+}
+
+// state 线程池状态,只能递增
+type state int
+
+const (
+	// running 接收并执行任务
+	running state = iota
+	// shutdown 不接收任务,执行完队列中的任务
+	shutdown
+	// stop 不接收任务,不再执行队列中的任务
+	stop
+)
+
+// Option 用于设置线程池的初始化选项
+type Option func(options *Options)
+
+// Options 线程池初始化选项
+type Options struct {
+	coreWorkers int           // coreWorkers 常驻的worker数
+	maxWorkers  int           // maxWorkers 最大worker数
+	queueSize   int           // queueSize 任务队列的容量
+	keepAlive   time.Duration // keepAlive 超过coreWorkers的worker的最长空闲时间
+
+	rejectPolicy RejectPolicy // rejectPolicy 任务被拒绝时的处理方式
+	panicHandler PanicHandler // panicHandler 处理任务中的panic
+	priority     bool         // priority 队列是否按优先级出队
+	clock        Clock        // clock 延迟与周期任务使用的时钟
+
+	autoscale     autoscaleOptions // autoscale 自动扩缩容的参数
+	buckets       []time.Duration  // buckets 等待与执行时间直方图的桶上界
+	statsInterval time.Duration    // statsInterval 指标回调的周期,0表示不回调
+	statsCallback func(Stats)      // statsCallback 指标回调
+}
+
+// WithCoreWorkers 设置常驻的worker数,默认为1
+func WithCoreWorkers(coreWorkers int) Option {
+	return func(options *Options) {
+		options.coreWorkers = coreWorkers
+	}
+}
+
+// WithMaxWorkers 设置最大worker数,默认与coreWorkers相同
+func WithMaxWorkers(maxWorkers int) Option {
+	return func(options *Options) {
+		options.maxWorkers = maxWorkers
+	}
+}
+
+// WithQueueSize 设置任务队列的容量,默认为1024
+func WithQueueSize(queueSize int) Option {
+	return func(options *Options) {
+		options.queueSize = queueSize
+	}
+}
+
+// WithKeepAlive 设置超过coreWorkers的worker的最长空闲时间,默认为1分钟
+func WithKeepAlive(keepAlive time.Duration) Option {
+	return func(options *Options) {
+		options.keepAlive = keepAlive
+	}
+}
+
+// WithRejectPolicy 设置任务被拒绝时的处理方式,默认为 AbortPolicy
+func WithRejectPolicy(policy RejectPolicy) Option {
+	return func(options *Options) {
+		options.rejectPolicy = policy
+	}
+}
+
+// WithPanicHandler 设置任务panic时的处理函数,默认输出到标准错误
+func WithPanicHandler(handler PanicHandler) Option {
+	return func(options *Options) {
+		options.panicHandler = handler
+	}
+}
+
+// WithPriorityQueue 设置队列是否按优先级出队,默认先入先出
+// 只有进入队列的任务按优先级排序,直接交给新worker的任务立即执行
+func WithPriorityQueue(priority bool) Option {
+	return func(options *Options) {
+		options.priority = priority
+	}
+}
+
+// WithClock 设置延迟与周期任务使用的时钟,默认为系统时钟
+func WithClock(clock Clock) Option {
+	return func(options *Options) {
+		options.clock = clock
+	}
+}
+
+// WithAutoscale 开启自动扩缩容,每隔interval检查一次
+// 队列长度超过queueThreshold,或周期内开始执行的任务平均等待时间超过latencyThreshold时增加worker,
+// 阈值为0表示不按该条件扩容;增加的worker空闲keepAlive后退出
+func WithAutoscale(interval time.Duration, queueThreshold int, latencyThreshold time.Duration) Option {
+	return func(options *Options) {
+		options.autoscale = autoscaleOptions{
+			interval:         interval,
+			queueThreshold:   queueThreshold,
+			latencyThreshold: latencyThreshold,
+		}
+	}
+}
+
+// WithHistogramBuckets 设置等待与执行时间直方图的桶上界,默认为 DefaultBuckets
+func WithHistogramBuckets(bounds []time.Duration) Option {
+	return func(options *Options) {
+		options.buckets = bounds
+	}
+}
+
+// WithStatsCallback 每隔interval以指标快照调用一次callback,线程池关闭后停止
+func WithStatsCallback(interval time.Duration, callback func(Stats)) Option {
+	return func(options *Options) {
+		options.statsInterval = interval
+		options.statsCallback = callback
+	}
+}
+
+// ThreadPool goroutine池
+type ThreadPool struct {
+	options    Options
+	queue      *taskQueue
+	mu         sync.Mutex    // mu 保护state与workers
+	state      state         // state 线程池状态
+	workers    int           // workers 当前worker数
+	active     int           // active 正在执行任务的worker数
+	completed  uint64        // completed 已完成的任务数
+	terminated chan struct{} // terminated 关闭且全部worker退出后关闭
+	sched      *scheduler    // sched 延迟与周期任务的调度器,第一次使用时创建
+
+	rejected    uint64        // rejected 触发 RejectPolicy 的次数
+	waitTime    Histogram     // waitTime 任务从提交到开始执行的时间
+	runTime     Histogram     // runTime 任务的执行时间
+	window      latencyWindow // window 当前自动扩缩容周期内的等待时间
+	monitorStop chan struct{} // monitorStop 关闭时自动扩缩容与指标回调停止
+}
+
+// NewThreadPool 创建线程池,参数不合法时panic
+func NewThreadPool(opts ...Option) *ThreadPool {
+	options := Options{
+		coreWorkers: 1,
+		maxWorkers:  -1,
+		queueSize:   1024,
+		keepAlive:   time.Minute,
+
+		panicHandler: defaultPanicHandler,
+		clock:        realClock{},
+		buckets:      DefaultBuckets,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.maxWorkers < 0 {
+		options.maxWorkers = options.coreWorkers
+	}
+	if options.coreWorkers < 0 || options.maxWorkers < 1 || options.maxWorkers < options.coreWorkers {
+		panic("threadpool: need 0 <= coreWorkers <= maxWorkers and maxWorkers > 0")
+	}
+	if options.queueSize < 1 {
+		panic("threadpool: queueSize is not > 0")
+	}
+	if options.autoscale.interval < 0 || options.statsInterval < 0 || (options.statsInterval > 0 && options.statsCallback == nil) {
+		panic("threadpool: invalid autoscale or stats callback options")
+	}
+	p := &ThreadPool{
+		options:     options,
+		queue:       newTaskQueue(options.queueSize, options.priority),
+		terminated:  make(chan struct{}),
+		waitTime:    newHistogram(options.buckets),
+		runTime:     newHistogram(options.buckets),
+		monitorStop: make(chan struct{}),
+	}
+	p.startMonitors()
+	return p
+}
+
+// Submit 提交任务
+// 线程池已关闭时返回 ErrShutdown;任务被拒绝时按 RejectPolicy 处理,AbortPolicy 返回 ErrRejected
+func (p *ThreadPool) Submit(fn func()) error {
+	return p.execute(&task{fn: fn})
+}
+
+// SubmitWithPriority 提交带优先级的任务,数值越大越先执行,同优先级先入先出
+// 未开启 WithPriorityQueue 时忽略优先级
+func (p *ThreadPool) SubmitWithPriority(fn func(), priority int) error {
+	return p.execute(&task{fn: fn, priority: priority})
+}
+
+// SubmitWithResult 提交有返回值的任务,通过 Future 获取结果
+// 任务panic时 Future 返回 *PanicError;任务被 DiscardPolicy 或 DiscardOldestPolicy 丢弃时 Future 不会完成
+func (p *ThreadPool) SubmitWithResult(fn Callable) (*Future, error) {
+	f := newFuture()
+	err := p.execute(&task{fn: func() {
+		defer func() {
+			if r := recover(); r != nil {
+				stack := debug.Stack()
+				f.complete(nil, &PanicError{Recovered: r, Stack: stack})
+				p.options.panicHandler(r, stack)
+			}
+		}()
+		value, err := fn()
+		f.complete(value, err)
+	}})
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// execute 安排任务,被拒绝时按 RejectPolicy 处理
+func (p *ThreadPool) execute(t *task) error {
+	t.submitted = p.options.clock.Now()
+	err := p.enqueue(t)
+	if err != ErrRejected {
+		return err
+	}
+	switch p.options.rejectPolicy {
+	case CallerRunsPolicy:
+		p.safeRun(t)
+		return nil
+	case DiscardPolicy:
+		return nil
+	}
+	return err
+}
+
+// enqueue 按核心worker,队列,最大worker的顺序安排任务
+func (p *ThreadPool) enqueue(t *task) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.state != running {
+		return ErrShutdown
+	}
+	if p.workers < p.options.coreWorkers {
+		p.startWorker(t)
+		return nil
+	}
+	if p.queue.offer(t) {
+		// coreWorkers为0时保证至少有一个worker处理队列
+		if p.workers == 0 {
+			p.startWorker(nil)
+		}
+		return nil
+	}
+	if p.workers < p.options.maxWorkers {
+		p.startWorker(t)
+		return nil
+	}
+	p.rejected++
+	// 持有锁时其他提交者无法放入任务,丢弃最旧的任务后一定有空位
+	if p.options.rejectPolicy == DiscardOldestPolicy {
+		if old := p.queue.discardOldest(); old != nil && old.discarded != nil {
+			old.discarded()
+		}
+		if p.queue.offer(t) {
+			return nil
+		}
+	}
+	return ErrRejected
+}
+
+// startWorker 创建worker,first为worker执行的第一个任务,调用方持有锁
+func (p *ThreadPool) startWorker(first *task) {
+	p.workers++
+	go p.worker(first)
+}
+
+// worker 执行first后不断从队列取任务执行,直到getTask返回nil
+func (p *ThreadPool) worker(first *task) {
+	t := first
+	for {
+		if t == nil {
+			if t = p.getTask(); t == nil {
+				return
+			}
+		}
+		p.runTask(t)
+		t = nil
+	}
+}
+
+// runTask 执行任务,记录计数与耗时
+func (p *ThreadPool) runTask(t *task) {
+	start := p.options.clock.Now()
+	wait := start.Sub(t.submitted)
+	p.mu.Lock()
+	p.active++
+	p.waitTime.observe(wait)
+	p.window.sum += wait
+	p.window.count++
+	p.mu.Unlock()
+	p.safeRun(t)
+	run := p.options.clock.Now().Sub(start)
+	p.mu.Lock()
+	p.active--
+	p.completed++
+	p.runTime.observe(run)
+	p.mu.Unlock()
+}
+
+// safeRun 执行任务,panic时交给 PanicHandler
+func (p *ThreadPool) safeRun(t *task) {
+	defer func() {
+		if r := recover(); r != nil {
+			p.options.panicHandler(r, debug.Stack())
+		}
+	}()
+	t.fn()
+}
+
+// getTask 从队列取任务,返回nil时worker已被注销,应当退出
+// 超过coreWorkers的worker等待keepAlive仍没有任务时退出,但队列非空时保留最后一个worker
+func (p *ThreadPool) getTask() *task {
+	for {
+		p.mu.Lock()
+		if p.state >= stop {
+			p.exitWorker()
+			p.mu.Unlock()
+			return nil
+		}
+		timed := p.workers > p.options.coreWorkers
+		p.mu.Unlock()
+
+		var timeout time.Duration
+		if timed {
+			timeout = p.options.keepAlive
+		}
+		t, result := p.queue.poll(timeout)
+		if result == polled {
+			return t
+		}
+
+		p.mu.Lock()
+		// enqueue持有锁时放入队列,此处看到的队列长度不会漏掉已放入但未启动worker的任务
+		if result == pollClosed || (result == pollTimeout && p.workers > p.options.coreWorkers &&
+			(p.workers > 1 || p.queue.len() == 0)) {
+			p.exitWorker()
+			p.mu.Unlock()
+			return nil
+		}
+		p.mu.Unlock()
+	}
+}
+
+// exitWorker 注销worker,关闭后最后一个worker退出时线程池终止,调用方持有锁
+func (p *ThreadPool) exitWorker() {
+	p.workers--
+	p.tryTerminate()
+}
+
+// tryTerminate 已关闭且没有worker时标记终止,调用方持有锁
+func (p *ThreadPool) tryTerminate() {
+	if p.state == running || p.workers > 0 {
+		return
+	}
+	select {
+	case <-p.terminated:
+	default:
+		close(p.terminated)
+	}
+}
+
+// Shutdown 不再接收新任务,已提交的任务继续执行,不等待执行完成
+// 尚未到期的延迟与周期任务被取消,Err返回 ErrShutdown
+func (p *ThreadPool) Shutdown() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.state < shutdown {
+		p.state = shutdown
+	}
+	p.queue.close()
+	p.stopScheduler()
+	p.tryTerminate()
+}
+
+// stopScheduler 取消全部尚未执行的延迟与周期任务,停止自动扩缩容与指标回调,调用方持有锁
+func (p *ThreadPool) stopScheduler() {
+	if p.sched != nil {
+		p.sched.stop()
+	}
+	select {
+	case <-p.monitorStop:
+	default:
+		close(p.monitorStop)
+	}
+}
+
+// ShutdownNow 不再接收新任务,取出并返回队列中尚未执行的任务
+// 正在执行的任务无法被中断,会继续执行完;返回的任务中 SubmitWithResult 提交的任务执行后仍会设置结果
+func (p *ThreadPool) ShutdownNow() []func() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.state = stop
+	p.queue.close()
+	p.stopScheduler()
+	tasks := p.queue.drain()
+	p.tryTerminate()
+	fns := make([]func(), len(tasks))
+	for i, t := range tasks {
+		fns[i] = t.fn
+	}
+	return fns
+}
+
+// AwaitTermination 等待关闭后全部worker退出,ctx结束时返回ctx.Err()
+func (p *ThreadPool) AwaitTermination(ctx context.Context) error {
+	select {
+	case <-p.terminated:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// IsShutdown 是否已调用 Shutdown 或 ShutdownNow
+func (p *ThreadPool) IsShutdown() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.state != running
+}
+
+// isStopped 是否已调用 ShutdownNow
+func (p *ThreadPool) isStopped() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.state >= stop
+}
+
+// IsTerminated 是否已关闭且全部worker已退出
+func (p *ThreadPool) IsTerminated() bool {
+	select {
+	case <-p.terminated:
+		return true
+	default:
+		return false
+	}
+}
+
+// PoolSize 当前worker数
+func (p *ThreadPool) PoolSize() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.workers
+}
+
+// ActiveCount 正在执行任务的worker数
+func (p *ThreadPool) ActiveCount() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.active
+}
+
+// CompletedCount 已完成的任务数
+func (p *ThreadPool) CompletedCount() uint64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.completed
+}
+
+// QueueLen 队列中等待执行的任务数
+func (p *ThreadPool) QueueLen() int {
+	return p.queue.len()
+}
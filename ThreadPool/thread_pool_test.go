@@ -0,0 +1,261 @@
+package ThreadPool
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+/****************************************************************
+ * @author: Ihc
+ * @date: 2026/10/20 03:40
+ * @description:
+ ***************************************************************/
+
+// waitFor 轮询等待条件成立
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timeout waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func awaitTermination(t *testing.T, p *ThreadPool) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := p.AwaitTermination(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSubmitAndShutdown(t *testing.T) {
+	p := NewThreadPool(WithCoreWorkers(4), WithQueueSize(1000))
+	var count int64
+	for i := 0; i < 1000; i++ {
+		if err := p.Submit(func() { atomic.AddInt64(&count, 1) }); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if p.PoolSize() != 4 {
+		t.Fatalf("pool size %d", p.PoolSize())
+	}
+	p.Shutdown()
+	if err := p.Submit(func() {}); err != ErrShutdown {
+		t.Fatalf("submit after shutdown: %v", err)
+	}
+	awaitTermination(t, p)
+	if count != 1000 || p.CompletedCount() != 1000 || p.PoolSize() != 0 || !p.IsTerminated() {
+		t.Fatalf("count %d, completed %d, size %d", count, p.CompletedCount(), p.PoolSize())
+	}
+}
+
+func TestMaxWorkersAndRejection(t *testing.T) {
+	p := NewThreadPool(WithCoreWorkers(1), WithMaxWorkers(2), WithQueueSize(1))
+	release := make(chan struct{})
+	block := func() { <-release }
+	// 第一个任务创建核心worker,第二个进入队列,第三个创建额外worker,第四个被拒绝
+	for i := 0; i < 3; i++ {
+		if err := p.Submit(block); err != nil {
+			t.Fatalf("submit %d: %v", i, err)
+		}
+	}
+	if err := p.Submit(block); err != ErrRejected {
+		t.Fatalf("expect rejection, got %v", err)
+	}
+	if p.PoolSize() != 2 || p.QueueLen() != 1 {
+		t.Fatalf("size %d, queue %d", p.PoolSize(), p.QueueLen())
+	}
+	waitFor(t, "two active workers", func() bool { return p.ActiveCount() == 2 })
+	close(release)
+	p.Shutdown()
+	awaitTermination(t, p)
+	if p.CompletedCount() != 3 {
+		t.Fatalf("completed %d", p.CompletedCount())
+	}
+}
+
+func TestIdleReaping(t *testing.T) {
+	p := NewThreadPool(WithCoreWorkers(1), WithMaxWorkers(4), WithQueueSize(1), WithKeepAlive(10*time.Millisecond))
+	release := make(chan struct{})
+	for i := 0; i < 5; i++ {
+		if err := p.Submit(func() { <-release }); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if p.PoolSize() != 4 {
+		t.Fatalf("pool size %d", p.PoolSize())
+	}
+	close(release)
+	waitFor(t, "idle workers reaped", func() bool { return p.PoolSize() == 1 })
+	time.Sleep(30 * time.Millisecond)
+	if p.PoolSize() != 1 {
+		t.Fatalf("core worker reaped, size %d", p.PoolSize())
+	}
+	p.Shutdown()
+	awaitTermination(t, p)
+}
+
+func TestZeroCoreWorkers(t *testing.T) {
+	p := NewThreadPool(WithCoreWorkers(0), WithMaxWorkers(1), WithKeepAlive(time.Millisecond))
+	done := make(chan struct{})
+	if err := p.Submit(func() { close(done) }); err != nil {
+		t.Fatal(err)
+	}
+	<-done
+	waitFor(t, "worker exit", func() bool { return p.PoolSize() == 0 })
+	p.Shutdown()
+	awaitTermination(t, p)
+}
+
+func TestZeroCoreWorkersTimeoutRace(t *testing.T) {
+	// 最后一个worker超时退出时提交的任务仍要被执行
+	keepAlive := 50 * time.Microsecond
+	p := NewThreadPool(WithCoreWorkers(0), WithMaxWorkers(1), WithKeepAlive(keepAlive))
+	for i := 0; i < 2000; i++ {
+		// 提交时间在keepAlive附近错开,命中worker超时与退出之间的窗口
+		time.Sleep(keepAlive * time.Duration(i%5) / 2)
+		done := make(chan struct{})
+		if err := p.Submit(func() { close(done) }); err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("task %d stranded in queue, size %d, queue %d", i, p.PoolSize(), p.QueueLen())
+		}
+	}
+	p.Shutdown()
+	awaitTermination(t, p)
+}
+
+func TestFuture(t *testing.T) {
+	p := NewThreadPool(WithCoreWorkers(2))
+	defer p.Shutdown()
+	errBoom := errors.New("boom")
+	ok, err := p.SubmitWithResult(func() (interface{}, error) { return 42, nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	failed, _ := p.SubmitWithResult(func() (interface{}, error) { return nil, errBoom })
+	if v, err := ok.Get(context.Background()); v != 42 || err != nil {
+		t.Fatalf("got %v, %v", v, err)
+	}
+	if _, err := failed.Get(context.Background()); err != errBoom {
+		t.Fatalf("got %v", err)
+	}
+	if !ok.IsDone() {
+		t.Fatal("IsDone error")
+	}
+
+	release := make(chan struct{})
+	slow, _ := p.SubmitWithResult(func() (interface{}, error) { <-release; return "late", nil })
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := slow.Get(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("got %v", err)
+	}
+	close(release)
+	<-slow.Done()
+	if v, _ := slow.Get(context.Background()); v != "late" {
+		t.Fatalf("got %v", v)
+	}
+}
+
+func TestShutdownNow(t *testing.T) {
+	p := NewThreadPool(WithCoreWorkers(1), WithQueueSize(10))
+	started := make(chan struct{})
+	release := make(chan struct{})
+	_ = p.Submit(func() { close(started); <-release })
+	<-started
+	var ran int64
+	for i := 0; i < 5; i++ {
+		_ = p.Submit(func() { atomic.AddInt64(&ran, 1) })
+	}
+	future, _ := p.SubmitWithResult(func() (interface{}, error) { return "drained", nil })
+	pending := p.ShutdownNow()
+	if len(pending) != 6 || !p.IsShutdown() {
+		t.Fatalf("pending %d", len(pending))
+	}
+	if p.IsTerminated() {
+		t.Fatal("terminated while a task is running")
+	}
+	close(release)
+	awaitTermination(t, p)
+	if ran != 0 {
+		t.Fatalf("drained tasks ran %d times", ran)
+	}
+	// 返回的任务由调用方决定如何处理
+	for _, fn := range pending {
+		fn()
+	}
+	if v, _ := future.Get(context.Background()); ran != 5 || v != "drained" {
+		t.Fatalf("ran %d, future %v", ran, v)
+	}
+}
+
+func TestAwaitTerminationTimeout(t *testing.T) {
+	p := NewThreadPool()
+	release := make(chan struct{})
+	_ = p.Submit(func() { <-release })
+	p.Shutdown()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := p.AwaitTermination(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("got %v", err)
+	}
+	close(release)
+	awaitTermination(t, p)
+}
+
+func TestConcurrentSubmit(t *testing.T) {
+	p := NewThreadPool(WithCoreWorkers(4), WithMaxWorkers(8), WithQueueSize(16), WithKeepAlive(time.Millisecond))
+	var (
+		wg       sync.WaitGroup
+		ran      int64
+		accepted int64
+	)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 500; j++ {
+				if err := p.Submit(func() { atomic.AddInt64(&ran, 1) }); err == nil {
+					atomic.AddInt64(&accepted, 1)
+				} else if err != ErrRejected {
+					t.Error(err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	p.Shutdown()
+	awaitTermination(t, p)
+	if ran != accepted || accepted == 0 {
+		t.Fatalf("ran %d, accepted %d", ran, accepted)
+	}
+}
+
+func TestInvalidOptions(t *testing.T) {
+	for _, opts := range [][]Option{
+		{WithCoreWorkers(2), WithMaxWorkers(1)},
+		{WithCoreWorkers(0)},
+		{WithQueueSize(0)},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatal("expect panic")
+				}
+			}()
+			NewThreadPool(opts...)
+		}()
+	}
+}
@@ -0,0 +1,106 @@
+package TimingWheels
+
+import (
+	"container/heap"
+)
+
+/****************************************************************
+ * @author: Ihc
+ * @date: 2026/10/20 08:00
+ * @description: 时间轮的桶与延迟队列
+桶是定时器的双向链表,桶的过期时间在第一次放入定时器时设置,随后桶进入延迟队列
+延迟队列是按过期时间排序的最小堆,只保存有定时器的桶,推进时间轮的goroutine只在桶过期时被唤醒
+ ***************************************************************/
+
+// bucket 时间轮的一个格子
+type bucket struct {
+	root       Timer // root 链表的哨兵
+	expiration int64 // expiration 过期时间(纳秒),-1表示不在延迟队列中
+	index      int   // index 在延迟队列中的位置
+}
+
+func newBucket() *bucket {
+	b := &bucket{expiration: -1, index: -1}
+	b.root.next = &b.root
+	b.root.prev = &b.root
+	return b
+}
+
+// add 加入定时器
+func (b *bucket) add(t *Timer) {
+	t.prev = b.root.prev
+	t.next = &b.root
+	t.prev.next = t
+	b.root.prev = t
+	t.bucket = b
+}
+
+// remove 移除定时器
+func (b *bucket) remove(t *Timer) {
+	t.prev.next = t.next
+	t.next.prev = t.prev
+	t.prev = nil
+	t.next = nil
+	t.bucket = nil
+}
+
+// flush 取出全部定时器依次交给fn,并重置过期时间
+func (b *bucket) flush(fn func(t *Timer)) {
+	for t := b.root.next; t != &b.root; t = b.root.next {
+		b.remove(t)
+		fn(t)
+	}
+	b.expiration = -1
+}
+
+// delayQueue 按过期时间排序的桶
+type delayQueue []*bucket
+
+func (q delayQueue) Len() int {
+	return len(q)
+}
+
+func (q delayQueue) Less(i, j int) bool {
+	return q[i].expiration < q[j].expiration
+}
+
+func (q delayQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *delayQueue) Push(x interface{}) {
+	b := x.(*bucket)
+	b.index = len(*q)
+	*q = append(*q, b)
+}
+
+func (q *delayQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	b := old[n-1]
+	old[n-1] = nil
+	b.index = -1
+	*q = old[:n-1]
+	return b
+}
+
+// offer 加入桶,返回该桶是否成为最早过期的桶
+func (q *delayQueue) offer(b *bucket) bool {
+	heap.Push(q, b)
+	return b.index == 0
+}
+
+// peek 最早过期的桶,队列为空时返回nil
+func (q delayQueue) peek() *bucket {
+	if len(q) == 0 {
+		return nil
+	}
+	return q[0]
+}
+
+// poll 取出最早过期的桶
+func (q *delayQueue) poll() *bucket {
+	return heap.Pop(q).(*bucket)
+}
@@ -0,0 +1,20 @@
+package TimingWheels
+
+import (
+	clock "preseus/Clock"
+)
+
+/****************************************************************
+ * @author: Ihc
+ * @date: 2026/10/20 07:50
+ * @description: 时钟接口,测试时可以替换为手动推进的时钟
+ ***************************************************************/
+
+// Clock 时钟接口,见 Clock.Clock
+type Clock = clock.Clock
+
+// ClockTimer Clock 创建的定时器,见 Clock.Timer
+type ClockTimer = clock.Timer
+
+// realClock 系统时钟
+type realClock = clock.RealClock
@@ -0,0 +1,419 @@
+package TimingWheels
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+/****************************************************************
+ * @author: Ihc
+ * @date: 2026/10/20 10:10
+ * @description: 持久化的延迟队列
+任务加入,执行完成与取消都追加写入日志文件,重启后重放日志恢复尚未完成的任务
+重启时已逾期的任务按 CatchUpPolicy 立即执行或丢弃
+处理函数返回后才写入完成记录,处理期间崩溃的任务在重启后会再次执行(至少一次)
+写入失败时截断到最后一条完整记录,截断也失败时拒绝之后的写入,避免有效记录跟在损坏的记录之后
+无效记录超过阈值且多于有效任务时,将有效任务写入新文件后替换日志,压缩失败不影响已写入的操作
+ ***************************************************************/
+
+// ErrClosed 延迟队列已关闭
+var ErrClosed = errors.New("timingwheels: delay queue is closed")
+
+// Job 延迟任务
+type Job struct {
+	ID      string    // ID 任务的唯一标识,相同ID的任务会被替换
+	FireAt  time.Time // FireAt 触发时间
+	Payload []byte    // Payload 任务数据
+}
+
+// CatchUpPolicy 重启时已逾期任务的处理方式
+type CatchUpPolicy int
+
+const (
+	// FireOverdue 立即执行全部逾期任务
+	FireOverdue CatchUpPolicy = iota
+	// SkipOverdue 丢弃全部逾期任务
+	SkipOverdue
+	// FireRecentOverdue 立即执行逾期不超过maxLateness的任务,丢弃其他逾期任务
+	FireRecentOverdue
+)
+
+// WithCatchUpPolicy 设置延迟队列重启时逾期任务的处理方式,默认为 FireOverdue
+// maxLateness只在 FireRecentOverdue 时有效
+func WithCatchUpPolicy(policy CatchUpPolicy, maxLateness time.Duration) Option {
+	return func(options *Options) {
+		options.catchUp = policy
+		options.maxLateness = maxLateness
+	}
+}
+
+// WithCompactThreshold 设置延迟队列压缩日志的无效记录数阈值,默认为1024
+func WithCompactThreshold(threshold int) Option {
+	return func(options *Options) {
+		options.compactThreshold = threshold
+	}
+}
+
+// WithSyncWrites 设置延迟队列每次写入日志后是否同步到磁盘,默认为true
+// 关闭后进程崩溃不会丢失记录,但操作系统崩溃可能丢失最近的记录
+func WithSyncWrites(sync bool) Option {
+	return func(options *Options) {
+		options.noSync = !sync
+	}
+}
+
+// durableJob 队列中的任务
+type durableJob struct {
+	job    Job
+	seq    uint64 // seq 加入时的序号
+	timer  *Timer // timer 尚未到期时的定时器,逾期任务为nil
+	firing bool   // firing 处理函数正在执行
+}
+
+// DelayQueue 持久化的延迟队列,并发安全
+type DelayQueue struct {
+	tw      *TimingWheel
+	handler func(job Job)
+	options Options
+	path    string
+
+	mu      sync.Mutex
+	file    *os.File
+	size    int64                  // size 日志中完整记录的总长度,新记录写在此处
+	broken  error                  // broken 日志无法恢复到完整记录时的错误,之后拒绝写入
+	buf     []byte                 // buf 编码记录的缓冲区
+	seq     uint64                 // seq 最大的任务序号
+	jobs    map[string]*durableJob // jobs 尚未完成的任务
+	records int                    // records 日志中的记录数
+	closed  bool
+}
+
+// OpenDelayQueue 打开path处的日志,恢复尚未完成的任务,在tw上等待触发,触发时调用handler
+// 日志末尾不完整的记录被截断;选项中只有延迟队列的选项有效,时钟与执行器使用tw的
+func OpenDelayQueue(path string, tw *TimingWheel, handler func(job Job), opts ...Option) (*DelayQueue, error) {
+	options := Options{compactThreshold: 1024}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	q := &DelayQueue{
+		tw:      tw,
+		handler: handler,
+		options: options,
+		path:    path,
+		file:    file,
+		jobs:    make(map[string]*durableJob),
+	}
+	valid, err := readRecords(file, q.replay)
+	if err == nil {
+		err = file.Truncate(valid)
+	}
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	q.size = valid
+
+	q.mu.Lock()
+	due, err := q.recover()
+	if err == nil {
+		err = q.maybeCompact()
+	}
+	q.mu.Unlock()
+	if err != nil {
+		q.Close()
+		return nil, err
+	}
+	q.fireAll(due)
+	return q, nil
+}
+
+// replay 重放一条记录
+func (q *DelayQueue) replay(r *record) {
+	q.records++
+	if r.seq > q.seq {
+		q.seq = r.seq
+	}
+	switch r.kind {
+	case recordSchedule:
+		q.jobs[r.job.ID] = &durableJob{job: r.job, seq: r.seq}
+	case recordDone:
+		if j, ok := q.jobs[r.job.ID]; ok && j.seq == r.seq {
+			delete(q.jobs, r.job.ID)
+		}
+	}
+}
+
+// recover 按触发时间顺序安排重放的任务,返回需要立即执行的逾期任务,调用方持有锁
+func (q *DelayQueue) recover() ([]*durableJob, error) {
+	jobs := make([]*durableJob, 0, len(q.jobs))
+	for _, j := range q.jobs {
+		jobs = append(jobs, j)
+	}
+	sort.Slice(jobs, func(a, b int) bool {
+		if !jobs[a].job.FireAt.Equal(jobs[b].job.FireAt) {
+			return jobs[a].job.FireAt.Before(jobs[b].job.FireAt)
+		}
+		return jobs[a].job.ID < jobs[b].job.ID
+	})
+	now := q.tw.options.clock.Now()
+	var due []*durableJob
+	for _, j := range jobs {
+		if j.job.FireAt.After(now) {
+			q.arm(j, now)
+			continue
+		}
+		lateness := now.Sub(j.job.FireAt)
+		switch q.options.catchUp {
+		case SkipOverdue:
+		case FireRecentOverdue:
+			if lateness <= q.options.maxLateness {
+				due = append(due, j)
+				continue
+			}
+		default:
+			due = append(due, j)
+			continue
+		}
+		delete(q.jobs, j.job.ID)
+		if err := q.write(&record{kind: recordDone, seq: j.seq, job: Job{ID: j.job.ID}}); err != nil {
+			return nil, err
+		}
+	}
+	return due, nil
+}
+
+// Schedule 加入任务,写入日志后返回;已有相同ID的任务时替换
+// 触发时间不晚于当前时间的任务立即执行
+func (q *DelayQueue) Schedule(job Job) error {
+	job.Payload = append([]byte(nil), job.Payload...)
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		return ErrClosed
+	}
+	q.seq++
+	j := &durableJob{job: job, seq: q.seq}
+	if err := q.write(&record{kind: recordSchedule, seq: j.seq, job: job}); err != nil {
+		q.mu.Unlock()
+		return err
+	}
+	if old, ok := q.jobs[job.ID]; ok && old.timer != nil {
+		old.timer.Stop()
+	}
+	q.jobs[job.ID] = j
+	now := q.tw.options.clock.Now()
+	due := job.FireAt.After(now)
+	if due {
+		q.arm(j, now)
+	}
+	q.compactOrLog()
+	q.mu.Unlock()
+	if !due {
+		q.fireAll([]*durableJob{j})
+	}
+	return nil
+}
+
+// Cancel 取消尚未触发的任务,返回是否取消成功;处理函数已开始执行的任务无法取消
+func (q *DelayQueue) Cancel(id string) (bool, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return false, ErrClosed
+	}
+	j, ok := q.jobs[id]
+	if !ok || j.firing {
+		return false, nil
+	}
+	if err := q.write(&record{kind: recordDone, seq: j.seq, job: Job{ID: id}}); err != nil {
+		return false, err
+	}
+	if j.timer != nil {
+		j.timer.Stop()
+	}
+	delete(q.jobs, id)
+	q.compactOrLog()
+	return true, nil
+}
+
+// Len 尚未完成的任务数
+func (q *DelayQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.jobs)
+}
+
+// Compact 将尚未完成的任务写入新的日志文件并替换旧文件
+func (q *DelayQueue) Compact() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return ErrClosed
+	}
+	return q.compact()
+}
+
+// Close 停止全部定时器并关闭日志文件,尚未完成的任务在下次打开时恢复
+// 正在执行的处理函数不会被中断,其完成记录不再写入
+func (q *DelayQueue) Close() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return nil
+	}
+	q.closed = true
+	for _, j := range q.jobs {
+		if j.timer != nil {
+			j.timer.Stop()
+		}
+	}
+	if q.file == nil {
+		return nil
+	}
+	return q.file.Close()
+}
+
+// arm 为任务创建定时器,调用方持有锁
+// 触发时间晚于now时定时器一定由时间轮的goroutine触发,不会在持有锁时同步执行
+func (q *DelayQueue) arm(j *durableJob, now time.Time) {
+	j.timer = q.tw.AfterFunc(j.job.FireAt.Sub(now), func() { q.fire(j) })
+}
+
+// fireAll 通过时间轮的执行器立即执行任务,调用方不能持有锁
+func (q *DelayQueue) fireAll(jobs []*durableJob) {
+	for _, j := range jobs {
+		j := j
+		q.tw.options.executor(func() { q.fire(j) })
+	}
+}
+
+// fire 执行任务,处理函数返回后写入完成记录
+func (q *DelayQueue) fire(j *durableJob) {
+	q.mu.Lock()
+	// 任务已被取消或替换
+	if q.closed || q.jobs[j.job.ID] != j {
+		q.mu.Unlock()
+		return
+	}
+	j.firing = true
+	q.mu.Unlock()
+
+	q.handler(j.job)
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return
+	}
+	if q.jobs[j.job.ID] == j {
+		delete(q.jobs, j.job.ID)
+	}
+	if err := q.write(&record{kind: recordDone, seq: j.seq, job: Job{ID: j.job.ID}}); err != nil {
+		fmt.Fprintf(os.Stderr, "timingwheels: journal write failed: %v\n", err)
+		return
+	}
+	q.compactOrLog()
+}
+
+// write 在最后一条完整记录之后追加一条记录,调用方持有锁
+// 写入或同步失败时截断掉写入的部分,截断失败时日志进入损坏状态,之后的写入都返回错误
+func (q *DelayQueue) write(r *record) error {
+	if q.broken != nil {
+		return q.broken
+	}
+	q.buf = appendRecord(q.buf[:0], r)
+	_, err := q.file.WriteAt(q.buf, q.size)
+	if err == nil && !q.options.noSync {
+		err = q.file.Sync()
+	}
+	if err != nil {
+		if terr := q.file.Truncate(q.size); terr != nil {
+			q.broken = fmt.Errorf("timingwheels: journal is broken after failed write: %v", terr)
+		}
+		return err
+	}
+	q.size += int64(len(q.buf))
+	q.records++
+	return nil
+}
+
+// compactOrLog 按需压缩日志,失败时只输出错误,调用方持有锁
+// 调用时记录已经写入,压缩失败不能作为本次操作的错误返回,否则调用方重试会重复加入任务
+func (q *DelayQueue) compactOrLog() {
+	if err := q.maybeCompact(); err != nil {
+		fmt.Fprintf(os.Stderr, "timingwheels: journal compaction failed: %v\n", err)
+	}
+}
+
+// maybeCompact 无效记录超过阈值且多于有效任务时压缩日志,调用方持有锁
+func (q *DelayQueue) maybeCompact() error {
+	dead := q.records - len(q.jobs)
+	if dead < q.options.compactThreshold || dead <= len(q.jobs) {
+		return nil
+	}
+	return q.compact()
+}
+
+// compact 将有效任务写入临时文件后替换日志,调用方持有锁
+// 替换前关闭日志文件(windows不能重命名覆盖打开的文件),替换后重新打开;重新打开失败时日志进入损坏状态
+func (q *DelayQueue) compact() error {
+	if q.broken != nil {
+		return q.broken
+	}
+	tmp := q.path + ".compact"
+	file, err := os.OpenFile(tmp, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	var buf []byte
+	for _, j := range q.jobs {
+		buf = appendRecord(buf, &record{kind: recordSchedule, seq: j.seq, job: j.job})
+	}
+	_, err = file.Write(buf)
+	if err == nil {
+		err = file.Sync()
+	}
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	q.file.Close()
+	renameErr := os.Rename(tmp, q.path)
+	if renameErr != nil {
+		os.Remove(tmp)
+	} else {
+		syncDir(filepath.Dir(q.path))
+	}
+	// 重命名失败时重新打开旧日志
+	q.file, err = os.OpenFile(q.path, os.O_RDWR, 0644)
+	if err != nil {
+		q.broken = fmt.Errorf("timingwheels: journal cannot be reopened: %v", err)
+		return q.broken
+	}
+	if renameErr != nil {
+		return renameErr
+	}
+	q.size = int64(len(buf))
+	q.records = len(q.jobs)
+	return nil
+}
+
+// syncDir 同步目录,使重命名持久化,失败时忽略
+func syncDir(dir string) {
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+}
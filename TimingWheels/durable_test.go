@@ -0,0 +1,266 @@
+package TimingWheels
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+/****************************************************************
+ * @author: Ihc
+ * @date: 2026/10/20 10:30
+ * @description:
+ ***************************************************************/
+
+// openTestQueue 在clock上创建时间轮并打开延迟队列,触发的任务发送到返回的channel
+func openTestQueue(t *testing.T, path string, clock *fakeClock, opts ...Option) (*DelayQueue, chan Job) {
+	t.Helper()
+	tw := NewTimingWheel(time.Millisecond, 16, WithClock(clock), WithExecutor(func(f func()) { f() }))
+	t.Cleanup(tw.Stop)
+	fired := make(chan Job, 100)
+	q, err := OpenDelayQueue(path, tw, func(job Job) { fired <- job }, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { q.Close() })
+	return q, fired
+}
+
+// drained 取出已触发的任务ID并排序
+func drained(fired chan Job) []string {
+	var ids []string
+	for {
+		select {
+		case job := <-fired:
+			ids = append(ids, job.ID)
+		default:
+			sort.Strings(ids)
+			return ids
+		}
+	}
+}
+
+func TestDelayQueueRecovery(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jobs.journal")
+	clock := newFakeClock()
+	q, fired := openTestQueue(t, path, clock)
+	for i, id := range []string{"a", "b", "c"} {
+		job := Job{ID: id, FireAt: epoch.Add(time.Duration(i+1) * 10 * time.Millisecond), Payload: []byte("payload-" + id)}
+		if err := q.Schedule(job); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if ok, err := q.Cancel("c"); !ok || err != nil {
+		t.Fatal("cancel", ok, err)
+	}
+	clock.next(t)
+	clock.AdvanceTo(epoch.Add(10 * time.Millisecond))
+	if job := <-fired; job.ID != "a" || string(job.Payload) != "payload-a" {
+		t.Fatal(job)
+	}
+	waitFor(t, "done record", func() bool { return q.Len() == 1 })
+	if err := q.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Schedule(Job{ID: "x"}); err != ErrClosed {
+		t.Fatal("schedule after close", err)
+	}
+
+	// 重启后只恢复b
+	q, fired = openTestQueue(t, path, clock)
+	if q.Len() != 1 {
+		t.Fatal("recovered", q.Len())
+	}
+	clock.next(t)
+	clock.AdvanceTo(epoch.Add(20 * time.Millisecond))
+	job := <-fired
+	if job.ID != "b" || string(job.Payload) != "payload-b" || !job.FireAt.Equal(epoch.Add(20*time.Millisecond)) {
+		t.Fatal(job)
+	}
+}
+
+func TestDelayQueueCatchUpPolicies(t *testing.T) {
+	cases := []struct {
+		policy      CatchUpPolicy
+		maxLateness time.Duration
+		want        []string
+	}{
+		{FireOverdue, 0, []string{"a", "b"}},
+		{SkipOverdue, 0, nil},
+		{FireRecentOverdue, 15 * time.Millisecond, []string{"b"}},
+	}
+	for _, c := range cases {
+		path := filepath.Join(t.TempDir(), "jobs.journal")
+		clock := newFakeClock()
+		q, _ := openTestQueue(t, path, clock)
+		_ = q.Schedule(Job{ID: "a", FireAt: epoch.Add(10 * time.Millisecond)})
+		_ = q.Schedule(Job{ID: "b", FireAt: epoch.Add(20 * time.Millisecond)})
+		_ = q.Schedule(Job{ID: "later", FireAt: epoch.Add(time.Hour)})
+		q.Close()
+
+		// 停机30ms后重启
+		clock.Advance(30 * time.Millisecond)
+		q, fired := openTestQueue(t, path, clock, WithCatchUpPolicy(c.policy, c.maxLateness))
+		if got := drained(fired); !reflect.DeepEqual(got, c.want) {
+			t.Fatalf("policy %d: fired %v, want %v", c.policy, got, c.want)
+		}
+		if q.Len() != 1 {
+			t.Fatalf("policy %d: %d jobs left", c.policy, q.Len())
+		}
+		q.Close()
+		// 丢弃与执行都已写入日志,再次重启不会重复
+		q, fired = openTestQueue(t, path, clock, WithCatchUpPolicy(c.policy, c.maxLateness))
+		if got := drained(fired); len(got) != 0 || q.Len() != 1 {
+			t.Fatalf("policy %d: second restart fired %v", c.policy, got)
+		}
+	}
+}
+
+func TestDelayQueueTornWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jobs.journal")
+	clock := newFakeClock()
+	q, _ := openTestQueue(t, path, clock)
+	_ = q.Schedule(Job{ID: "a", FireAt: epoch.Add(time.Hour)})
+	q.Close()
+	info, _ := os.Stat(path)
+
+	// 模拟写入一半时崩溃
+	f, _ := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644)
+	f.Write(appendRecord(nil, &record{kind: recordSchedule, seq: 9, job: Job{ID: "torn", FireAt: epoch}})[:12])
+	f.Close()
+
+	q, fired := openTestQueue(t, path, clock)
+	if q.Len() != 1 || len(drained(fired)) != 0 {
+		t.Fatal("torn record must be ignored", q.Len())
+	}
+	if now, _ := os.Stat(path); now.Size() != info.Size() {
+		t.Fatal("torn record must be truncated", now.Size(), info.Size())
+	}
+	if err := q.Schedule(Job{ID: "b", FireAt: epoch.Add(time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+	q.Close()
+	q, _ = openTestQueue(t, path, clock)
+	if q.Len() != 2 {
+		t.Fatal("records after truncation", q.Len())
+	}
+}
+
+func TestDelayQueueReplaceAndCompact(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jobs.journal")
+	clock := newFakeClock()
+	q, fired := openTestQueue(t, path, clock, WithCompactThreshold(10), WithSyncWrites(false))
+	_ = q.Schedule(Job{ID: "keep", FireAt: epoch.Add(time.Hour), Payload: []byte("v1")})
+	_ = q.Schedule(Job{ID: "keep", FireAt: epoch.Add(5 * time.Millisecond), Payload: []byte("v2")})
+	for i := 0; i < 100; i++ {
+		_ = q.Schedule(Job{ID: "tmp", FireAt: epoch.Add(time.Hour)})
+		if ok, _ := q.Cancel("tmp"); !ok {
+			t.Fatal("cancel")
+		}
+	}
+	if q.records > 20 {
+		t.Fatal("journal not compacted", q.records)
+	}
+	if _, err := os.Stat(path + ".compact"); !os.IsNotExist(err) {
+		t.Fatal("temporary file left", err)
+	}
+	if err := q.Compact(); err != nil || q.records != 1 {
+		t.Fatal("compact", q.records, err)
+	}
+	q.Close()
+
+	// 替换后的任务保留新的触发时间与负载
+	q, fired = openTestQueue(t, path, clock)
+	clock.next(t)
+	clock.AdvanceTo(epoch.Add(5 * time.Millisecond))
+	if job := <-fired; job.ID != "keep" || string(job.Payload) != "v2" {
+		t.Fatal(job)
+	}
+	if ok, _ := q.Cancel("keep"); ok {
+		t.Fatal("fired job cannot be cancelled")
+	}
+}
+
+func TestDelayQueueFailedWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jobs.journal")
+	clock := newFakeClock()
+	q, _ := openTestQueue(t, path, clock)
+	if err := q.Schedule(Job{ID: "a", FireAt: epoch.Add(time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+	// 失败的写入留下的残余内容被之后的记录覆盖,不会挡住之后的记录
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	garbage := make([]byte, 100)
+	for i := range garbage {
+		garbage[i] = 0xff
+	}
+	f.Write(garbage)
+	f.Close()
+	if err := q.Schedule(Job{ID: "b", FireAt: epoch.Add(time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+
+	// 写入失败且无法截断时拒绝之后的写入
+	readOnly, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q.mu.Lock()
+	q.file.Close()
+	q.file = readOnly
+	q.mu.Unlock()
+	if err := q.Schedule(Job{ID: "c", FireAt: epoch.Add(time.Hour)}); err == nil {
+		t.Fatal("write to a read-only journal must fail")
+	}
+	if err := q.Schedule(Job{ID: "d", FireAt: epoch.Add(time.Hour)}); err == nil {
+		t.Fatal("journal must refuse appends after an unrecoverable failure")
+	}
+	q.Close()
+
+	q, _ = openTestQueue(t, path, clock)
+	q.mu.Lock()
+	_, a := q.jobs["a"]
+	_, b := q.jobs["b"]
+	q.mu.Unlock()
+	if !a || !b || q.Len() != 2 {
+		t.Fatal("records after the torn bytes were lost", q.Len())
+	}
+}
+
+func TestDelayQueueCompactionFailure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jobs.journal")
+	clock := newFakeClock()
+	q, _ := openTestQueue(t, path, clock, WithCompactThreshold(1))
+	// 临时文件的位置被目录占用,压缩失败
+	if err := os.Mkdir(path+".compact", 0755); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := q.Schedule(Job{ID: "a", FireAt: epoch.Add(time.Hour)}); err != nil {
+			t.Fatal("compaction failure must not fail Schedule", err)
+		}
+	}
+	if ok, err := q.Cancel("a"); !ok || err != nil {
+		t.Fatal("compaction failure must not fail Cancel", ok, err)
+	}
+	if err := q.Compact(); err == nil {
+		t.Fatal("explicit Compact must report the failure")
+	}
+	if err := os.Remove(path + ".compact"); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Schedule(Job{ID: "b", FireAt: epoch.Add(time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+	q.Close()
+	q, _ = openTestQueue(t, path, clock)
+	if q.Len() != 1 {
+		t.Fatal("recovered", q.Len())
+	}
+}
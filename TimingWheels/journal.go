@@ -0,0 +1,153 @@
+package TimingWheels
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"time"
+)
+
+/****************************************************************
+ * @author: Ihc
+ * @date: 2026/10/20 09:50
+ * @description: 延迟队列的日志格式
+每条记录为: 长度(4字节) + CRC32(4字节) + 内容
+内容为: 类型(1字节) + 序号 + 触发时间 + ID + 负载,整数使用varint编码
+进程崩溃时最后一条记录可能不完整,读取时在第一条不完整或校验失败的记录处停止
+ ***************************************************************/
+
+const (
+	// recordSchedule 加入或替换任务
+	recordSchedule byte = 1
+	// recordDone 任务已执行或已取消
+	recordDone byte = 2
+)
+
+// recordHeaderSize 长度与校验和
+const recordHeaderSize = 8
+
+// maxRecordSize 单条记录的上限,超过时视为损坏
+const maxRecordSize = 64 << 20
+
+var errCorruptRecord = errors.New("timingwheels: corrupt journal record")
+
+// record 日志记录
+type record struct {
+	kind byte
+	seq  uint64 // seq 任务的序号,完成记录只删除序号相同的任务
+	job  Job    // job 完成记录只有ID
+}
+
+// appendRecord 编码记录追加到buf
+func appendRecord(buf []byte, r *record) []byte {
+	start := len(buf)
+	buf = append(buf, make([]byte, recordHeaderSize)...)
+	buf = append(buf, r.kind)
+	buf = appendUvarint(buf, r.seq)
+	var fireAt int64
+	if r.kind == recordSchedule {
+		fireAt = r.job.FireAt.UnixNano()
+	}
+	buf = appendVarint(buf, fireAt)
+	buf = appendUvarint(buf, uint64(len(r.job.ID)))
+	buf = append(buf, r.job.ID...)
+	buf = appendUvarint(buf, uint64(len(r.job.Payload)))
+	buf = append(buf, r.job.Payload...)
+	body := buf[start+recordHeaderSize:]
+	binary.LittleEndian.PutUint32(buf[start:], uint32(len(body)))
+	binary.LittleEndian.PutUint32(buf[start+4:], crc32.ChecksumIEEE(body))
+	return buf
+}
+
+func appendUvarint(buf []byte, v uint64) []byte {
+	var tmp [binary.MaxVarintLen64]byte
+	return append(buf, tmp[:binary.PutUvarint(tmp[:], v)]...)
+}
+
+func appendVarint(buf []byte, v int64) []byte {
+	var tmp [binary.MaxVarintLen64]byte
+	return append(buf, tmp[:binary.PutVarint(tmp[:], v)]...)
+}
+
+// decodeRecord 解码记录内容
+func decodeRecord(body []byte, r *record) error {
+	if len(body) == 0 {
+		return errCorruptRecord
+	}
+	r.kind = body[0]
+	body = body[1:]
+	seq, n := binary.Uvarint(body)
+	if n <= 0 {
+		return errCorruptRecord
+	}
+	body = body[n:]
+	fireAt, n := binary.Varint(body)
+	if n <= 0 {
+		return errCorruptRecord
+	}
+	body = body[n:]
+	id, body, err := decodeBytes(body)
+	if err != nil {
+		return err
+	}
+	payload, body, err := decodeBytes(body)
+	if err != nil || len(body) != 0 {
+		return errCorruptRecord
+	}
+	r.seq = seq
+	r.job = Job{ID: string(id)}
+	if r.kind == recordSchedule {
+		r.job.FireAt = time.Unix(0, fireAt)
+		if len(payload) > 0 {
+			r.job.Payload = payload
+		}
+	}
+	return nil
+}
+
+// decodeBytes 解码长度前缀的字节串
+func decodeBytes(body []byte) ([]byte, []byte, error) {
+	size, n := binary.Uvarint(body)
+	if n <= 0 || size > uint64(len(body)-n) {
+		return nil, nil, errCorruptRecord
+	}
+	body = body[n:]
+	return body[:size], body[size:], nil
+}
+
+// readRecords 依次读取记录交给fn,返回完整记录的总长度
+// 遇到不完整或损坏的记录时停止,之后的内容应当被截断
+func readRecords(r io.Reader, fn func(r *record)) (int64, error) {
+	br := bufio.NewReader(r)
+	var (
+		valid  int64
+		header [recordHeaderSize]byte
+		rec    record
+	)
+	for {
+		if _, err := io.ReadFull(br, header[:]); err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				return valid, nil
+			}
+			return valid, err
+		}
+		size := binary.LittleEndian.Uint32(header[:])
+		if size > maxRecordSize {
+			return valid, nil
+		}
+		body := make([]byte, size)
+		if _, err := io.ReadFull(br, body); err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				return valid, nil
+			}
+			return valid, err
+		}
+		if crc32.ChecksumIEEE(body) != binary.LittleEndian.Uint32(header[4:]) || decodeRecord(body, &rec) != nil {
+			return valid, nil
+		}
+		fn(&rec)
+		valid += int64(recordHeaderSize + size)
+	}
+}
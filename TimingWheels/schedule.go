@@ -0,0 +1,83 @@
+package TimingWheels
+
+import (
+	"time"
+)
+
+/****************************************************************
+ * @author: Ihc
+ * @date: 2026/10/20 08:50
+ * @description: 周期定时器
+下一次触发时间由 Scheduler 根据上一次的计划触发时间计算,而不是实际执行的时间,因此不会累积误差
+推进时间轮的goroutine落后超过一个周期时,错过的触发合并为一次,之后回到原来的时间点
+ ***************************************************************/
+
+// Scheduler 决定周期定时器的触发时间,cron表达式,退避等调度都可以实现该接口
+type Scheduler interface {
+	// Next 返回prev之后的下一次触发时间,返回零值表示不再触发
+	// 返回不晚于prev的时间时同样停止,避免重新计算时无限循环
+	Next(prev time.Time) time.Time
+}
+
+// SchedulerFunc 函数形式的 Scheduler
+type SchedulerFunc func(prev time.Time) time.Time
+
+func (f SchedulerFunc) Next(prev time.Time) time.Time {
+	return f(prev)
+}
+
+// every 固定间隔的调度
+type every time.Duration
+
+func (e every) Next(prev time.Time) time.Time {
+	return prev.Add(time.Duration(e))
+}
+
+// Every 每隔interval执行一次f,第一次在interval之后,interval不大于0时panic
+// 默认的执行器中f的多次执行可能重叠
+func (tw *TimingWheel) Every(interval time.Duration, f func()) *Timer {
+	if interval <= 0 {
+		panic("timingwheels: interval is not > 0")
+	}
+	return tw.ScheduleFunc(every(interval), f)
+}
+
+// ScheduleFunc 按scheduler的时间执行f,第一次为scheduler.Next(当前时间)
+// scheduler返回零值后定时器停止;Stop阻止之后的触发,但不影响已经交给执行器的f
+func (tw *TimingWheel) ScheduleFunc(scheduler Scheduler, f func()) *Timer {
+	t := &Timer{tw: tw, f: f, scheduler: scheduler}
+	tw.mu.Lock()
+	now := tw.options.clock.Now()
+	next := scheduler.Next(now)
+	var added bool
+	if !next.IsZero() {
+		if added = tw.scheduleAt(t, next); !added {
+			tw.reschedule(t, now.UnixNano())
+		}
+	}
+	tw.mu.Unlock()
+	if !next.IsZero() && !added {
+		tw.options.executor(f)
+	}
+	return t
+}
+
+// reschedule 到期的周期定时器按计划时间计算下一次触发并重新加入时间轮,调用方持有锁
+// 跳过不晚于now的触发时间,错过的触发只执行一次;Next没有向后推进时停止
+func (tw *TimingWheel) reschedule(t *Timer, now int64) {
+	if t.scheduler == nil {
+		return
+	}
+	next := t.when
+	for {
+		prev := next
+		next = t.scheduler.Next(prev)
+		if next.IsZero() || !next.After(prev) {
+			return
+		}
+		if next.UnixNano() > now {
+			break
+		}
+	}
+	tw.scheduleAt(t, next)
+}
@@ -0,0 +1,130 @@
+package TimingWheels
+
+import (
+	"testing"
+	"time"
+)
+
+/****************************************************************
+ * @author: Ihc
+ * @date: 2026/10/20 09:00
+ * @description:
+ ***************************************************************/
+
+func TestEveryIsDriftFree(t *testing.T) {
+	tw, clock := newTestWheel(time.Millisecond, 8)
+	defer tw.Stop()
+	fired := make(chan time.Time, 10)
+	timer := tw.Every(10*time.Millisecond, func() { fired <- clock.Now() })
+	// 每次都晚3ms推进时钟,触发时间仍然在10ms的整数倍之后
+	for i := 1; i <= 5; i++ {
+		clock.next(t)
+		clock.AdvanceTo(epoch.Add(time.Duration(i)*10*time.Millisecond + 3*time.Millisecond))
+		got := <-fired
+		if want := epoch.Add(time.Duration(i)*10*time.Millisecond + 3*time.Millisecond); !got.Equal(want) {
+			t.Fatalf("run %d at %v", i, got.Sub(epoch))
+		}
+		if want := epoch.Add(time.Duration(i+1) * 10 * time.Millisecond); !timer.when.Equal(want) {
+			t.Fatalf("run %d: next at %v, want %v", i, timer.when.Sub(epoch), want.Sub(epoch))
+		}
+	}
+	// 落后多个周期时只补一次
+	clock.next(t)
+	clock.AdvanceTo(epoch.Add(95 * time.Millisecond))
+	<-fired
+	select {
+	case <-fired:
+		t.Fatal("missed runs must be coalesced")
+	case <-time.After(5 * time.Millisecond):
+	}
+	if !timer.Stop() {
+		t.Fatal("periodic timer must be pending")
+	}
+	clock.AdvanceTo(epoch.Add(time.Second))
+	select {
+	case <-fired:
+		t.Fatal("stopped timer fired")
+	case <-time.After(5 * time.Millisecond):
+	}
+}
+
+// backoff 指数退避,达到上限后停止
+type backoff struct {
+	delay, max time.Duration
+}
+
+func (b *backoff) Next(prev time.Time) time.Time {
+	if b.delay > b.max {
+		return time.Time{}
+	}
+	next := prev.Add(b.delay)
+	b.delay *= 2
+	return next
+}
+
+func TestScheduleFuncCustomScheduler(t *testing.T) {
+	tw, clock := newTestWheel(time.Millisecond, 8)
+	defer tw.Stop()
+	fired := make(chan time.Duration, 10)
+	timer := tw.ScheduleFunc(&backoff{delay: 5 * time.Millisecond, max: 40 * time.Millisecond}, func() {
+		fired <- clock.Now().Sub(epoch)
+	})
+	// 5,5+10,15+20,35+40
+	for _, want := range []time.Duration{5, 15, 35, 75} {
+		want *= time.Millisecond
+		clock.next(t)
+		clock.AdvanceTo(epoch.Add(want))
+		if got := <-fired; got != want {
+			t.Fatalf("fired at %v, want %v", got, want)
+		}
+	}
+	if timer.Stop() {
+		t.Fatal("scheduler returned zero, timer must be inactive")
+	}
+
+	// 周期定时器Reset后从新的时间开始
+	times := SchedulerFunc(func(prev time.Time) time.Time { return prev.Add(7 * time.Millisecond) })
+	periodic := tw.ScheduleFunc(times, func() { fired <- clock.Now().Sub(epoch) })
+	periodic.Reset(0)
+	if got := <-fired; got != 75*time.Millisecond {
+		t.Fatal("reset fired at", got)
+	}
+	clock.next(t)
+	clock.AdvanceTo(epoch.Add(82 * time.Millisecond))
+	if got := <-fired; got != 82*time.Millisecond {
+		t.Fatal("next run at", got)
+	}
+	periodic.Stop()
+}
+
+func TestScheduleFuncStalledScheduler(t *testing.T) {
+	tw, clock := newTestWheel(time.Millisecond, 8)
+	defer tw.Stop()
+	fired := make(chan time.Duration, 10)
+	// 第一次之后总是返回同一个时间,定时器触发一次后停止,不能卡住时间轮
+	at := epoch.Add(5 * time.Millisecond)
+	stalled := SchedulerFunc(func(prev time.Time) time.Time { return at })
+	timer := tw.ScheduleFunc(stalled, func() { fired <- clock.Now().Sub(epoch) })
+	clock.next(t)
+	clock.AdvanceTo(epoch.Add(10 * time.Millisecond))
+	if got := <-fired; got != 10*time.Millisecond {
+		t.Fatalf("fired at %v", got)
+	}
+	if timer.Stop() {
+		t.Fatal("stalled scheduler must stop the timer")
+	}
+
+	// 返回早于prev的时间同样停止,包括已经到期的第一次
+	past := SchedulerFunc(func(prev time.Time) time.Time { return epoch })
+	tw.ScheduleFunc(past, func() { fired <- clock.Now().Sub(epoch) })
+	if got := <-fired; got != 10*time.Millisecond {
+		t.Fatalf("fired at %v", got)
+	}
+	after := tw.AfterFunc(time.Millisecond, func() { fired <- clock.Now().Sub(epoch) })
+	clock.next(t)
+	clock.AdvanceTo(epoch.Add(11 * time.Millisecond))
+	if got := <-fired; got != 11*time.Millisecond {
+		t.Fatalf("wheel stalled, fired at %v", got)
+	}
+	after.Stop()
+}
@@ -0,0 +1,65 @@
+package TimingWheels
+
+import (
+	"runtime"
+	"sync/atomic"
+	"time"
+)
+
+/****************************************************************
+ * @author: Ihc
+ * @date: 2026/10/20 09:20
+ * @description: 分片时间轮
+由多个独立的时间轮组成,每个分片有自己的锁与推进goroutine,新定时器轮流放入各分片
+定时器的停止与重新设置只锁定其所在的分片,并发创建与停止定时器时的锁竞争降为约1/分片数
+ ***************************************************************/
+
+// ShardedTimingWheel 分片时间轮,并发安全
+type ShardedTimingWheel struct {
+	shards []*TimingWheel
+	next   uint32 // next 下一个定时器放入的分片
+}
+
+// NewShardedTimingWheel 创建分片时间轮,每个分片与 NewTimingWheel 的参数相同
+// 分片数由 WithShards 设置,默认为GOMAXPROCS
+func NewShardedTimingWheel(tick time.Duration, wheelSize int, opts ...Option) *ShardedTimingWheel {
+	options := Options{shards: runtime.GOMAXPROCS(0)}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.shards <= 0 {
+		panic("timingwheels: shards is not > 0")
+	}
+	s := &ShardedTimingWheel{shards: make([]*TimingWheel, options.shards)}
+	for i := range s.shards {
+		s.shards[i] = NewTimingWheel(tick, wheelSize, opts...)
+	}
+	return s
+}
+
+// shard 轮流选择分片
+func (s *ShardedTimingWheel) shard() *TimingWheel {
+	return s.shards[atomic.AddUint32(&s.next, 1)%uint32(len(s.shards))]
+}
+
+// AfterFunc 与 TimingWheel.AfterFunc 相同
+func (s *ShardedTimingWheel) AfterFunc(d time.Duration, f func()) *Timer {
+	return s.shard().AfterFunc(d, f)
+}
+
+// Every 与 TimingWheel.Every 相同
+func (s *ShardedTimingWheel) Every(interval time.Duration, f func()) *Timer {
+	return s.shard().Every(interval, f)
+}
+
+// ScheduleFunc 与 TimingWheel.ScheduleFunc 相同
+func (s *ShardedTimingWheel) ScheduleFunc(scheduler Scheduler, f func()) *Timer {
+	return s.shard().ScheduleFunc(scheduler, f)
+}
+
+// Stop 停止全部分片
+func (s *ShardedTimingWheel) Stop() {
+	for _, tw := range s.shards {
+		tw.Stop()
+	}
+}
@@ -0,0 +1,88 @@
+package TimingWheels
+
+import (
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+/****************************************************************
+ * @author: Ihc
+ * @date: 2026/10/20 09:30
+ * @description:
+ ***************************************************************/
+
+func TestShardedTimingWheel(t *testing.T) {
+	clock := newFakeClock()
+	s := NewShardedTimingWheel(time.Millisecond, 16, WithShards(4), WithClock(clock), WithExecutor(func(f func()) { f() }))
+	defer s.Stop()
+	var fired, stopped int32
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				timer := s.AfterFunc(10*time.Millisecond, func() { atomic.AddInt32(&fired, 1) })
+				if i%2 == 0 && timer.Stop() {
+					atomic.AddInt32(&stopped, 1)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	for _, tw := range s.shards {
+		if tw.queue.Len() == 0 {
+			t.Fatal("timers must be spread across shards")
+		}
+	}
+	// 每个分片的推进goroutine都在等待10ms的桶
+	waitFor(t, "shards waiting", func() bool { return len(clock.Timers()) == len(s.shards) })
+	clock.Advance(10 * time.Millisecond)
+	waitFor(t, "timers fired", func() bool { return atomic.LoadInt32(&fired) == 400 })
+	if stopped != 400 {
+		t.Fatal("stopped", stopped)
+	}
+}
+
+// outstanding 基准测试中保持未触发的定时器数
+const outstanding = 1000000
+
+// benchmarkChurn 在outstanding个未触发的定时器之上并发创建并停止定时器
+func benchmarkChurn(b *testing.B, afterFunc func(d time.Duration, f func()) func() bool) {
+	stops := make([]func() bool, outstanding)
+	for i := range stops {
+		stops[i] = afterFunc(time.Hour+time.Duration(i)*time.Millisecond, func() {})
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		r := rand.New(rand.NewSource(time.Now().UnixNano()))
+		for pb.Next() {
+			stop := afterFunc(time.Duration(r.Intn(60000))*time.Millisecond+time.Minute, func() {})
+			stop()
+		}
+	})
+	b.StopTimer()
+	for _, stop := range stops {
+		stop()
+	}
+}
+
+func BenchmarkChurnTimingWheel(b *testing.B) {
+	tw := NewTimingWheel(time.Millisecond, 512)
+	defer tw.Stop()
+	benchmarkChurn(b, func(d time.Duration, f func()) func() bool { return tw.AfterFunc(d, f).Stop })
+}
+
+func BenchmarkChurnShardedTimingWheel(b *testing.B) {
+	s := NewShardedTimingWheel(time.Millisecond, 512)
+	defer s.Stop()
+	benchmarkChurn(b, func(d time.Duration, f func()) func() bool { return s.AfterFunc(d, f).Stop })
+}
+
+func BenchmarkChurnStdlib(b *testing.B) {
+	benchmarkChurn(b, func(d time.Duration, f func()) func() bool { return time.AfterFunc(d, f).Stop })
+}
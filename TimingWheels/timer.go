@@ -0,0 +1,54 @@
+package TimingWheels
+
+import (
+	"time"
+)
+
+/****************************************************************
+ * @author: Ihc
+ * @date: 2026/10/20 08:10
+ * @description: 时间轮中的定时器
+ ***************************************************************/
+
+// Timer AfterFunc 返回的定时器,并发安全
+type Timer struct {
+	tw         *TimingWheel
+	f          func()
+	expiration int64     // expiration 过期时间(纳秒),tick的整数倍
+	when       time.Time // when 未取整的触发时间,周期定时器据此计算下一次触发时间
+	scheduler  Scheduler // scheduler 周期定时器的调度,一次性定时器为nil
+	bucket     *bucket   // bucket 所在的桶,不在时间轮中时为nil
+	prev       *Timer
+	next       *Timer
+}
+
+// Stop 停止定时器,返回定时器是否尚未触发
+// 返回false时f已经开始执行或已经交给执行器
+func (t *Timer) Stop() bool {
+	t.tw.mu.Lock()
+	defer t.tw.mu.Unlock()
+	if t.bucket == nil {
+		return false
+	}
+	t.bucket.remove(t)
+	return true
+}
+
+// Reset 重新设置定时器在d之后触发,返回定时器在此之前是否尚未触发
+// 已触发或已停止的定时器可以再次触发;周期定时器从这次触发开始按 Scheduler 继续
+func (t *Timer) Reset(d time.Duration) bool {
+	t.tw.mu.Lock()
+	active := t.bucket != nil
+	if active {
+		t.bucket.remove(t)
+	}
+	added := t.tw.schedule(t, d)
+	if !added {
+		t.tw.reschedule(t, t.tw.options.clock.Now().UnixNano())
+	}
+	t.tw.mu.Unlock()
+	if !added {
+		t.tw.options.executor(t.f)
+	}
+	return active
+}
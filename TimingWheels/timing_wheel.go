@@ -1,7 +1,278 @@
 package TimingWheels
 
+import (
+	"sync"
+	"time"
+)
+
 /****************************************************************
  * @author: Ihc
  * @date: 2022/4/19 22:29
  * @description: 时间轮
+与Kafka的分层时间轮一致:
+	1.每层有wheelSize个桶,每个桶覆盖tick长的时间,一层覆盖tick*wheelSize
+	2.超出本层范围的定时器放入上一层,上一层的tick为本层的范围,按需创建
+	3.有定时器的桶按过期时间进入延迟队列,推进时间轮的goroutine只在最早的桶过期时醒来
+	4.桶过期后推进时间轮,桶中的定时器重新放入下层,到期的定时器被执行
+加入与停止定时器为O(1),大量定时器只占用一个goroutine
+定时器的过期时间向上取整到tick,不会提前触发,最多推迟一个tick
+Every 与 ScheduleFunc 创建周期定时器,下一次触发时间由 Scheduler 决定
+定时器频繁创建与停止时使用 ShardedTimingWheel 分散锁竞争
+需要在重启后恢复的任务使用持久化的 DelayQueue
  ***************************************************************/
+
+// maxDelay 定时器的最长延迟,避免过期时间溢出
+const maxDelay = time.Duration(1 << 62)
+
+// Option 用于设置时间轮的初始化选项
+type Option func(options *Options)
+
+// Options 时间轮初始化选项
+type Options struct {
+	clock    Clock        // clock 时钟
+	executor func(func()) // executor 执行到期的定时器函数
+	shards   int          // shards 分片时间轮的分片数
+
+	catchUp          CatchUpPolicy // catchUp 延迟队列重启时逾期任务的处理方式
+	maxLateness      time.Duration // maxLateness FireRecentOverdue 允许的最大逾期时间
+	compactThreshold int           // compactThreshold 延迟队列压缩日志的无效记录数阈值
+	noSync           bool          // noSync 延迟队列写入后不同步到磁盘
+}
+
+// WithClock 设置时钟,默认为系统时钟
+func WithClock(clock Clock) Option {
+	return func(options *Options) {
+		options.clock = clock
+	}
+}
+
+// WithExecutor 设置执行到期定时器函数的方式,默认每个函数在新的goroutine中执行
+// executor在推进时间轮的goroutine中调用,不能长时间阻塞,例如交给线程池执行
+func WithExecutor(executor func(f func())) Option {
+	return func(options *Options) {
+		options.executor = executor
+	}
+}
+
+// WithShards 设置 ShardedTimingWheel 的分片数,默认为GOMAXPROCS
+func WithShards(shards int) Option {
+	return func(options *Options) {
+		options.shards = shards
+	}
+}
+
+// wheel 时间轮的一层
+type wheel struct {
+	tick     int64     // tick 每个桶覆盖的时间(纳秒)
+	size     int64     // size 桶数
+	interval int64     // interval 本层覆盖的时间
+	current  int64     // current 本层的当前时间,tick的整数倍
+	buckets  []*bucket // buckets 环形排列的桶
+	overflow *wheel    // overflow 上一层,按需创建
+}
+
+func newWheel(tick, size, start int64) *wheel {
+	w := &wheel{
+		tick:     tick,
+		size:     size,
+		interval: tick * size,
+		current:  start - start%tick,
+		buckets:  make([]*bucket, size),
+	}
+	// 层数很多时范围可能溢出,此时本层覆盖全部时间
+	if w.interval/size != tick {
+		w.interval = 1<<63 - 1
+	}
+	for i := range w.buckets {
+		w.buckets[i] = newBucket()
+	}
+	return w
+}
+
+// overflowWheel 上一层
+func (w *wheel) overflowWheel() *wheel {
+	if w.overflow == nil {
+		w.overflow = newWheel(w.interval, w.size, w.current)
+	}
+	return w.overflow
+}
+
+// advance 推进本层及上层的当前时间
+func (w *wheel) advance(now int64) {
+	if now-w.current >= w.tick {
+		w.current = now - now%w.tick
+		if w.overflow != nil {
+			w.overflow.advance(w.current)
+		}
+	}
+}
+
+// TimingWheel 分层时间轮,并发安全
+type TimingWheel struct {
+	options Options
+	tick    int64
+	mu      sync.Mutex // mu 保护时间轮,桶与延迟队列
+	wheel   *wheel     // wheel 最底层
+	queue   delayQueue // queue 有定时器的桶
+	wake    chan struct{}
+	stop    chan struct{}
+	stopped chan struct{}
+	once    sync.Once
+}
+
+// NewTimingWheel 创建时间轮并开始推进
+// tick为最底层每个桶覆盖的时间,即定时器的精度;wheelSize为每层的桶数;参数不合法时panic
+func NewTimingWheel(tick time.Duration, wheelSize int, opts ...Option) *TimingWheel {
+	options := Options{
+		clock:    realClock{},
+		executor: func(f func()) { go f() },
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if tick <= 0 || wheelSize <= 0 {
+		panic("timingwheels: tick and wheelSize must be > 0")
+	}
+	tw := &TimingWheel{
+		options: options,
+		tick:    int64(tick),
+		wheel:   newWheel(int64(tick), int64(wheelSize), options.clock.Now().UnixNano()),
+		wake:    make(chan struct{}, 1),
+		stop:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	go tw.run()
+	return tw
+}
+
+// AfterFunc 在d之后执行f,返回的 Timer 可以停止或重新设置
+// d不大于0时立即执行;时间轮停止后加入的定时器不会触发
+func (tw *TimingWheel) AfterFunc(d time.Duration, f func()) *Timer {
+	t := &Timer{tw: tw, f: f}
+	tw.mu.Lock()
+	added := tw.schedule(t, d)
+	tw.mu.Unlock()
+	if !added {
+		tw.options.executor(f)
+	}
+	return t
+}
+
+// Stop 停止推进时间轮,尚未到期的定时器不再触发,等待推进的goroutine退出
+func (tw *TimingWheel) Stop() {
+	tw.once.Do(func() {
+		close(tw.stop)
+	})
+	<-tw.stopped
+}
+
+// schedule 设置定时器在d之后过期并加入时间轮,已到期时返回false,调用方持有锁
+func (tw *TimingWheel) schedule(t *Timer, d time.Duration) bool {
+	if d > maxDelay {
+		d = maxDelay
+	}
+	return tw.scheduleAt(t, tw.options.clock.Now().Add(d))
+}
+
+// scheduleAt 设置定时器在when过期并加入时间轮,已到期时返回false,调用方持有锁
+func (tw *TimingWheel) scheduleAt(t *Timer, when time.Time) bool {
+	t.when = when
+	expiration := when.UnixNano()
+	// 向上取整到tick,定时器所在的桶过期时定时器一定已到期
+	if r := expiration % tw.tick; r != 0 {
+		expiration += tw.tick - r
+	}
+	t.expiration = expiration
+	return tw.add(t)
+}
+
+// add 从最底层开始找到覆盖过期时间的层,放入对应的桶,已到期时返回false,调用方持有锁
+func (tw *TimingWheel) add(t *Timer) bool {
+	for w := tw.wheel; ; w = w.overflowWheel() {
+		delta := t.expiration - w.current
+		if delta < w.tick {
+			return false
+		}
+		if delta < w.interval {
+			id := t.expiration / w.tick
+			b := w.buckets[id%w.size]
+			b.add(t)
+			// 桶被清空后第一次放入定时器,设置新的过期时间并加入延迟队列
+			if expiration := id * w.tick; b.expiration != expiration {
+				b.expiration = expiration
+				if tw.queue.offer(b) {
+					tw.signal()
+				}
+			}
+			return true
+		}
+	}
+}
+
+// signal 唤醒推进时间轮的goroutine,重新计算等待时间
+func (tw *TimingWheel) signal() {
+	select {
+	case tw.wake <- struct{}{}:
+	default:
+	}
+}
+
+// advance 处理全部已过期的桶,返回到期的定时器与距下一个桶过期的时间,没有桶时返回-1
+func (tw *TimingWheel) advance(expired []*Timer) ([]*Timer, time.Duration) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	now := tw.options.clock.Now().UnixNano()
+	for {
+		b := tw.queue.peek()
+		if b == nil {
+			return expired, -1
+		}
+		if b.expiration > now {
+			return expired, time.Duration(b.expiration - now)
+		}
+		tw.queue.poll()
+		tw.wheel.advance(b.expiration)
+		b.flush(func(t *Timer) {
+			if !tw.add(t) {
+				expired = append(expired, t)
+				tw.reschedule(t, now)
+			}
+		})
+	}
+}
+
+// run 等待最早的桶过期后推进时间轮并执行到期的定时器
+func (tw *TimingWheel) run() {
+	defer close(tw.stopped)
+	var expired []*Timer
+	for {
+		var wait time.Duration
+		expired, wait = tw.advance(expired)
+		for i, t := range expired {
+			tw.options.executor(t.f)
+			expired[i] = nil
+		}
+		expired = expired[:0]
+
+		var (
+			timer ClockTimer
+			fire  <-chan time.Time
+		)
+		if wait >= 0 {
+			timer = tw.options.clock.NewTimer(wait)
+			fire = timer.C()
+		}
+		select {
+		case <-fire:
+		case <-tw.wake:
+		case <-tw.stop:
+			if timer != nil {
+				timer.Stop()
+			}
+			return
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+}
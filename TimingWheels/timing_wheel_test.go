@@ -0,0 +1,179 @@
+package TimingWheels
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"preseus/internal/fakeclock"
+)
+
+/****************************************************************
+ * @author: Ihc
+ * @date: 2026/10/20 08:20
+ * @description:
+ ***************************************************************/
+
+var epoch = time.Date(2026, 10, 20, 8, 0, 0, 0, time.UTC)
+
+// fakeClock 手动推进的时钟
+type fakeClock struct {
+	*fakeclock.FakeClock
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{fakeclock.New(epoch)}
+}
+
+// waitTimer 等待出现在when触发的定时器,即时间轮已开始等待该时间
+func (c *fakeClock) waitTimer(t *testing.T, when time.Time) {
+	t.Helper()
+	waitFor(t, "timer at "+when.Format(time.RFC3339Nano), func() bool {
+		for _, timer := range c.Timers() {
+			if timer.Equal(when) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// next 等待时间轮开始等待,返回最早的定时器的触发时间
+func (c *fakeClock) next(t *testing.T) time.Time {
+	t.Helper()
+	var when time.Time
+	waitFor(t, "wheel waiting", func() bool {
+		timers := c.Timers()
+		if len(timers) == 0 {
+			return false
+		}
+		when = timers[0]
+		return true
+	})
+	return when
+}
+
+// waitFor 轮询等待条件成立
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timeout waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// newTestWheel 使用手动时钟,在推进时间轮的goroutine中同步执行定时器
+func newTestWheel(tick time.Duration, wheelSize int) (*TimingWheel, *fakeClock) {
+	clock := newFakeClock()
+	tw := NewTimingWheel(tick, wheelSize, WithClock(clock), WithExecutor(func(f func()) { f() }))
+	return tw, clock
+}
+
+func TestAfterFuncAcrossWheels(t *testing.T) {
+	// 每层覆盖8ms,64ms,512ms,4096ms
+	tw, clock := newTestWheel(time.Millisecond, 8)
+	defer tw.Stop()
+	fired := make(chan time.Duration, 10)
+	delays := []time.Duration{3 * time.Millisecond, 20 * time.Millisecond, 100 * time.Millisecond, 3 * time.Second}
+	for _, d := range delays {
+		d := d
+		tw.AfterFunc(d, func() { fired <- d })
+	}
+	for _, d := range delays {
+		clock.next(t)
+		clock.AdvanceTo(epoch.Add(d - time.Nanosecond))
+		select {
+		case got := <-fired:
+			t.Fatalf("%v fired early at %v", got, clock.Now().Sub(epoch))
+		case <-time.After(5 * time.Millisecond):
+		}
+		clock.next(t)
+		clock.AdvanceTo(epoch.Add(d))
+		if got := <-fired; got != d {
+			t.Fatalf("fired %v, want %v", got, d)
+		}
+	}
+}
+
+func TestAdvanceOnlyWhenBucketsExpire(t *testing.T) {
+	tw, clock := newTestWheel(time.Millisecond, 8)
+	defer tw.Stop()
+	fired := make(chan struct{}, 1)
+	tw.AfterFunc(time.Hour, func() { fired <- struct{}{} })
+	// 一小时的定时器在第4层,其桶在第一个整4096ms时过期,之后逐层下降
+	var wakeups int
+	for clock.Now().Before(epoch.Add(time.Hour)) {
+		clock.AdvanceTo(clock.next(t))
+		wakeups++
+	}
+	<-fired
+	if wakeups > 20 {
+		t.Fatalf("%d wakeups for one timer", wakeups)
+	}
+}
+
+func TestTimerStopAndReset(t *testing.T) {
+	tw, clock := newTestWheel(time.Millisecond, 16)
+	defer tw.Stop()
+	var count int32
+	stopped := tw.AfterFunc(5*time.Millisecond, func() { atomic.AddInt32(&count, 100) })
+	if !stopped.Stop() || stopped.Stop() {
+		t.Fatal("Stop must report whether the timer was pending")
+	}
+	fired := make(chan time.Time, 2)
+	timer := tw.AfterFunc(5*time.Millisecond, func() { fired <- clock.Now() })
+	clock.AdvanceTo(epoch.Add(2 * time.Millisecond))
+	if !timer.Reset(10 * time.Millisecond) {
+		t.Fatal("Reset of a pending timer must return true")
+	}
+	// 停止的定时器留下的空桶仍在延迟队列中
+	clock.waitTimer(t, epoch.Add(5*time.Millisecond))
+	clock.AdvanceTo(epoch.Add(12 * time.Millisecond))
+	if got := <-fired; !got.Equal(epoch.Add(12 * time.Millisecond)) {
+		t.Fatal("fired at", got)
+	}
+	if timer.Stop() {
+		t.Fatal("Stop after firing must return false")
+	}
+	// 触发后可以再次设置
+	if timer.Reset(time.Millisecond) {
+		t.Fatal("Reset of a fired timer must return false")
+	}
+	clock.waitTimer(t, epoch.Add(13*time.Millisecond))
+	clock.AdvanceTo(epoch.Add(13 * time.Millisecond))
+	<-fired
+	if timer.Reset(0) {
+		t.Fatal("Reset with zero delay fires immediately")
+	}
+	<-fired
+	if atomic.LoadInt32(&count) != 0 {
+		t.Fatal("stopped timer fired")
+	}
+}
+
+func TestManyTimersRealClock(t *testing.T) {
+	tw := NewTimingWheel(time.Millisecond, 32)
+	defer tw.Stop()
+	const n = 10000
+	var wg sync.WaitGroup
+	wg.Add(n)
+	start := time.Now()
+	var early int32
+	for i := 0; i < n; i++ {
+		d := time.Duration(i%50) * time.Millisecond
+		tw.AfterFunc(d, func() {
+			if time.Since(start) < d {
+				atomic.AddInt32(&early, 1)
+			}
+			wg.Done()
+		})
+	}
+	wg.Wait()
+	if early != 0 {
+		t.Fatalf("%d timers fired early", early)
+	}
+}
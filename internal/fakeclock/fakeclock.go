@@ -0,0 +1,95 @@
+package fakeclock
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"preseus/Clock"
+)
+
+/****************************************************************
+ * @author: Ihc
+ * @date: 2026/10/20 14:20
+ * @description: 测试用的手动推进的时钟
+推进时钟时触发到期的定时器,测试通过 Timers 等待被测模块开始等待定时器
+ ***************************************************************/
+
+// FakeClock 手动推进的时钟
+type FakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers map[*fakeTimer]struct{}
+}
+
+type fakeTimer struct {
+	clock *FakeClock
+	when  time.Time
+	c     chan time.Time
+}
+
+// New 创建当前时间为now的时钟
+func New(now time.Time) *FakeClock {
+	return &FakeClock{now: now, timers: make(map[*fakeTimer]struct{})}
+}
+
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// NewTimer 创建定时器,d不大于0时立即触发
+func (c *FakeClock) NewTimer(d time.Duration) Clock.Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &fakeTimer{clock: c, when: c.now.Add(d), c: make(chan time.Time, 1)}
+	if d <= 0 {
+		t.c <- c.now
+		return t
+	}
+	c.timers[t] = struct{}{}
+	return t
+}
+
+// Advance 推进时钟,触发到期的定时器
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	for t := range c.timers {
+		if !t.when.After(c.now) {
+			t.c <- c.now
+			delete(c.timers, t)
+		}
+	}
+}
+
+// AdvanceTo 推进时钟到when
+func (c *FakeClock) AdvanceTo(when time.Time) {
+	c.Advance(when.Sub(c.Now()))
+}
+
+// Timers 未触发的定时器的触发时间,从早到晚排序
+func (c *FakeClock) Timers() []time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	whens := make([]time.Time, 0, len(c.timers))
+	for t := range c.timers {
+		whens = append(whens, t.when)
+	}
+	sort.Slice(whens, func(i, j int) bool { return whens[i].Before(whens[j]) })
+	return whens
+}
+
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	_, ok := t.clock.timers[t]
+	delete(t.clock.timers, t)
+	return ok
+}